@@ -3,9 +3,15 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/meter-peter/driveby/internal/config"
 	"github.com/meter-peter/driveby/internal/logger"
 	"github.com/meter-peter/driveby/internal/report"
 	"github.com/meter-peter/driveby/internal/validation"
@@ -20,6 +26,21 @@ var (
 		Long: `DriveBy is a modern API validation framework that helps you validate, test, and monitor your APIs.
 It supports OpenAPI/Swagger specifications and provides comprehensive validation, testing, and rollout capabilities.`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// The config file is read before the logger so that it can set the log level
+			if cmd != configValidateCmd {
+				if err := loadConfigFile(viper.GetString("config")); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				// Principles of rule files must be registered before principles are selected
+				for _, path := range viper.GetStringSlice("rules") {
+					if err := validation.DefaultRegistry.LoadRules(path); err != nil {
+						cmd.SilenceUsage = true
+						return err
+					}
+				}
+			}
+
 			// Initialize logger with minimal configuration
 			logCfg := logger.DefaultConfig()
 			logCfg.Level = viper.GetString("log-level")
@@ -40,45 +61,74 @@ const (
 	ExitExecutionError   = 2 // Error executing tests
 )
 
-var validateOnlyCmd = &cobra.Command{
-	Use:   "validate-only",
-	Short: "Run only OpenAPI/documentation validation checks",
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run spec validation, functional and performance tests as gated by the validation mode",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		openapiPath := viper.GetString("openapi")
-		if openapiPath == "" {
-			openapiPath = os.Getenv("DRIVEBY_OPENAPI")
-			fmt.Fprintf(os.Stderr, "[DEBUG] Fallback: using DRIVEBY_OPENAPI env var: %s\n", openapiPath)
-		}
-		osOpenapi := os.Getenv("DRIVEBY_OPENAPI")
-		fmt.Fprintf(os.Stderr, "[DEBUG] os.Getenv(DRIVEBY_OPENAPI): %s\n", osOpenapi)
-		fmt.Fprintf(os.Stderr, "[DEBUG] viper.GetString(openapi): %s\n", viper.GetString("openapi"))
-		if openapiPath == "" {
-			fmt.Fprintln(os.Stderr, "[ERROR] --openapi flag or DRIVEBY_OPENAPI env variable must be set")
-			os.Exit(2)
+		openapiPath := specPath()
+		cfg := validatorConfig(openapiPath)
+		cfg.ValidationMode = validation.ValidationMode(viper.GetString("validation-mode"))
+		cfg.Auth = authConfig()
+		cfg.SuitePaths = viper.GetStringSlice("suite")
+		cfg.PerformanceTarget = performanceTargets()
+		cfg.Principles = viper.GetStringSlice("principles")
+		reportDir := viper.GetString("report-dir")
+		generator := newGenerator(reportDir)
+		orchestrator := validation.NewOrchestrator(cfg)
+		results, err := orchestrator.RunAllValidations(context.Background())
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
 		}
-		// Debug print for openapi path
-		fmt.Fprintf(os.Stderr, "[DEBUG] openapi path: %s\n", openapiPath)
-		protocol := viper.GetString("protocol")
-		port := viper.GetString("port")
-		if protocol == "https" && port == "8080" {
-			port = "443"
+		report := orchestrator.MergeResults(results)
+		printFindings(openapiPath, report)
+		if err := generator.SaveValidationReport(report); err != nil {
+			logAndExit(err, ExitExecutionError)
 		}
-
-		baseURL := viper.GetString("api-url")
-		if baseURL == "" {
-			baseURL = fmt.Sprintf("%s://%s:%s", protocol, viper.GetString("host"), port)
+		for _, result := range results {
+			if result.Skipped || result.Report == nil {
+				continue
+			}
+			switch result.Type {
+			case validation.ValidationTypeFunctional:
+				err = generator.SaveFunctionalTestReport(result.Report)
+			case validation.ValidationTypePerformance:
+				err = generator.SaveLoadTestReport(result.Report)
+			}
+			if err != nil {
+				logAndExit(err, ExitExecutionError)
+			}
+		}
+		if err := generator.SaveRunReports(report, openapiPath); err != nil {
+			logAndExit(err, ExitExecutionError)
 		}
+		recordRun(cmd.Name(), report)
+		json.NewEncoder(os.Stdout).Encode(report)
 
-		cfg := validation.ValidatorConfig{
-			BaseURL:        baseURL,
-			SpecPath:       openapiPath,
-			Environment:    viper.GetString("environment"),
-			Version:        viper.GetString("version"),
-			Timeout:        viper.GetDuration("timeout"),
-			ValidationMode: validation.ValidationMode(viper.GetString("validation-mode")),
+		// Fail on critical principles, failed tests, or tests stopped by a failing phase
+		for _, principle := range report.Principles {
+			if !principle.Passed && principle.Principle.Severity == "critical" {
+				os.Exit(ExitValidationFailed)
+			}
 		}
+		switch report.TestResults.Status {
+		case validation.TestStatusFailed, validation.TestStatusIncomplete:
+			os.Exit(ExitValidationFailed)
+		}
+		os.Exit(ExitSuccess)
+		return nil
+	},
+}
+
+var validateOnlyCmd = &cobra.Command{
+	Use:   "validate-only",
+	Short: "Run only OpenAPI/documentation validation checks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		openapiPath := specPath()
+		cfg := validatorConfig(openapiPath)
+		cfg.ValidationMode = validation.ValidationMode(viper.GetString("validation-mode"))
+		cfg.Principles = viper.GetStringSlice("principles")
 		reportDir := viper.GetString("report-dir")
-		generator := report.NewGenerator(reportDir)
+		generator := newGenerator(reportDir)
 		validator, err := validation.NewAPIValidator(cfg)
 		if err != nil {
 			logAndExit(err, ExitExecutionError)
@@ -87,9 +137,14 @@ var validateOnlyCmd = &cobra.Command{
 		if err != nil {
 			logAndExit(err, ExitExecutionError)
 		}
+		printFindings(openapiPath, report)
 		if err := generator.SaveValidationReport(report); err != nil {
 			logAndExit(err, ExitExecutionError)
 		}
+		if err := generator.SaveRunReports(report, openapiPath); err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		recordRun(cmd.Name(), report)
 		json.NewEncoder(os.Stdout).Encode(report)
 
 		// Check if any critical principles failed
@@ -107,40 +162,12 @@ var functionOnlyCmd = &cobra.Command{
 	Use:   "function-only",
 	Short: "Run only functional tests",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		openapiPath := viper.GetString("openapi")
-		if openapiPath == "" {
-			openapiPath = os.Getenv("DRIVEBY_OPENAPI")
-			fmt.Fprintf(os.Stderr, "[DEBUG] Fallback: using DRIVEBY_OPENAPI env var: %s\n", openapiPath)
-		}
-		osOpenapi := os.Getenv("DRIVEBY_OPENAPI")
-		fmt.Fprintf(os.Stderr, "[DEBUG] os.Getenv(DRIVEBY_OPENAPI): %s\n", osOpenapi)
-		fmt.Fprintf(os.Stderr, "[DEBUG] viper.GetString(openapi): %s\n", viper.GetString("openapi"))
-		if openapiPath == "" {
-			fmt.Fprintln(os.Stderr, "[ERROR] --openapi flag or DRIVEBY_OPENAPI env variable must be set")
-			os.Exit(2)
-		}
-		// Debug print for openapi path
-		fmt.Fprintf(os.Stderr, "[DEBUG] openapi path: %s\n", openapiPath)
-		protocol := viper.GetString("protocol")
-		port := viper.GetString("port")
-		if protocol == "https" && port == "8080" {
-			port = "443"
-		}
-
-		baseURL := viper.GetString("api-url")
-		if baseURL == "" {
-			baseURL = fmt.Sprintf("%s://%s:%s", protocol, viper.GetString("host"), port)
-		}
-
-		cfg := validation.ValidatorConfig{
-			BaseURL:     baseURL,
-			SpecPath:    openapiPath,
-			Environment: viper.GetString("environment"),
-			Version:     viper.GetString("version"),
-			Timeout:     viper.GetDuration("timeout"),
-		}
+		openapiPath := specPath()
+		cfg := validatorConfig(openapiPath)
+		cfg.Auth = authConfig()
+		cfg.SuitePaths = viper.GetStringSlice("suite")
 		reportDir := viper.GetString("report-dir")
-		generator := report.NewGenerator(reportDir)
+		generator := newGenerator(reportDir)
 		tester := validation.NewFunctionalTester(cfg)
 		report, err := tester.TestEndpoints(context.Background())
 		if err != nil {
@@ -149,6 +176,10 @@ var functionOnlyCmd = &cobra.Command{
 		if err := generator.SaveFunctionalTestReport(report); err != nil {
 			logAndExit(err, ExitExecutionError)
 		}
+		if err := generator.SaveRunReports(report, openapiPath); err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		recordRun(cmd.Name(), report)
 		json.NewEncoder(os.Stdout).Encode(report)
 
 		// Check if any endpoints failed
@@ -166,58 +197,258 @@ var loadOnlyCmd = &cobra.Command{
 	Use:   "load-only",
 	Short: "Run only load/performance tests",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		openapiPath := viper.GetString("openapi")
-		if openapiPath == "" {
-			openapiPath = os.Getenv("DRIVEBY_OPENAPI")
-			fmt.Fprintf(os.Stderr, "[DEBUG] Fallback: using DRIVEBY_OPENAPI env var: %s\n", openapiPath)
-		}
-		osOpenapi := os.Getenv("DRIVEBY_OPENAPI")
-		fmt.Fprintf(os.Stderr, "[DEBUG] os.Getenv(DRIVEBY_OPENAPI): %s\n", osOpenapi)
-		fmt.Fprintf(os.Stderr, "[DEBUG] viper.GetString(openapi): %s\n", viper.GetString("openapi"))
-		if openapiPath == "" {
-			fmt.Fprintln(os.Stderr, "[ERROR] --openapi flag or DRIVEBY_OPENAPI env variable must be set")
-			os.Exit(2)
+		openapiPath := specPath()
+		cfg := validatorConfig(openapiPath)
+		cfg.Auth = authConfig()
+		cfg.PerformanceTarget = performanceTargets()
+		reportDir := viper.GetString("report-dir")
+		generator := newGenerator(reportDir)
+		tester, err := validation.NewPerformanceTester(cfg)
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		report, err := tester.TestPerformance(context.Background())
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
 		}
-		// Debug print for openapi path
-		fmt.Fprintf(os.Stderr, "[DEBUG] openapi path: %s\n", openapiPath)
-		protocol := viper.GetString("protocol")
-		port := viper.GetString("port")
-		if protocol == "https" && port == "8080" {
-			port = "443"
+		if err := generator.SaveLoadTestReport(report); err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		if err := generator.SaveRunReports(report, openapiPath); err != nil {
+			logAndExit(err, ExitExecutionError)
 		}
+		recordRun(cmd.Name(), report)
+		json.NewEncoder(os.Stdout).Encode(report)
 
-		baseURL := viper.GetString("api-url")
-		if baseURL == "" {
-			baseURL = fmt.Sprintf("%s://%s:%s", protocol, viper.GetString("host"), port)
+		// Fail when a performance target, an endpoint SLO or the baseline check failed
+		for _, principle := range report.Principles {
+			if !principle.Passed {
+				os.Exit(ExitValidationFailed)
+			}
 		}
+		os.Exit(ExitSuccess)
+		return nil
+	},
+}
 
-		cfg := validation.ValidatorConfig{
-			BaseURL:     baseURL,
-			SpecPath:    openapiPath,
-			Environment: viper.GetString("environment"),
-			Version:     viper.GetString("version"),
-			Timeout:     viper.GetDuration("timeout"),
-			PerformanceTarget: &validation.PerformanceTargetConfig{
-				MaxLatencyP95:   viper.GetDuration("max-latency-p95"),
-				MinSuccessRate:  viper.GetFloat64("min-success-rate"),
-				ConcurrentUsers: viper.GetInt("concurrent-users"),
-				Duration:        viper.GetDuration("test-duration"),
-			},
+var fuzzCmd = &cobra.Command{
+	Use:   "fuzz",
+	Short: "Send randomized requests generated from the OpenAPI spec",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		openapiPath := specPath()
+		cfg := validatorConfig(openapiPath)
+		cfg.Auth = authConfig()
+		cfg.Fuzz = &validation.FuzzConfig{
+			Seed:       viper.GetInt64("fuzz-seed"),
+			Iterations: viper.GetInt("fuzz-iterations"),
+			Duration:   viper.GetDuration("fuzz-duration"),
 		}
 		reportDir := viper.GetString("report-dir")
-		generator := report.NewGenerator(reportDir)
-		tester, err := validation.NewPerformanceTester(cfg)
+		generator := newGenerator(reportDir)
+		fuzzer := validation.NewFuzzer(cfg)
+		report, err := fuzzer.Fuzz(context.Background())
 		if err != nil {
 			logAndExit(err, ExitExecutionError)
 		}
-		report, err := tester.TestPerformance(context.Background())
+		if err := generator.SaveFuzzReport(report); err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		if err := generator.SaveRunReports(report, openapiPath); err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		recordRun(cmd.Name(), report)
+		json.NewEncoder(os.Stdout).Encode(report)
+
+		if report.TestResults.Fuzz.Status == validation.TestStatusFailed {
+			os.Exit(ExitValidationFailed)
+		}
+		os.Exit(ExitSuccess)
+		return nil
+	},
+}
+
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Apply safe automatic repairs to the OpenAPI spec",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		openapiPath := specPath()
+		cfg := validatorConfig(openapiPath)
+		cfg.ValidationMode = validation.ValidationMode(viper.GetString("validation-mode"))
+		cfg.FixOutputPath = viper.GetString("fix-output")
+		reportDir := viper.GetString("report-dir")
+		generator := newGenerator(reportDir)
+		fixer := validation.NewFixer(cfg)
+		report, err := fixer.Fix(context.Background())
 		if err != nil {
 			logAndExit(err, ExitExecutionError)
 		}
-		if err := generator.SaveLoadTestReport(report); err != nil {
+		if err := generator.SaveValidationReport(report); err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		if err := generator.SaveRunReports(report, openapiPath); err != nil {
 			logAndExit(err, ExitExecutionError)
 		}
+		recordRun(cmd.Name(), report)
 		json.NewEncoder(os.Stdout).Encode(report)
+
+		// Fail if a fix could not be applied or a critical principle still fails
+		for _, fix := range report.AutoFixes {
+			if !fix.Success {
+				os.Exit(ExitValidationFailed)
+			}
+		}
+		for _, principle := range report.Principles {
+			if !principle.Passed && principle.Principle.Severity == "critical" {
+				os.Exit(ExitValidationFailed)
+			}
+		}
+		os.Exit(ExitSuccess)
+		return nil
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Detect breaking changes between a baseline and a candidate OpenAPI spec",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		openapiPath := specPath()
+		baselinePath := viper.GetString("baseline")
+		if baselinePath == "" {
+			fmt.Fprintln(os.Stderr, "[ERROR] --baseline flag or DRIVEBY_BASELINE env variable must be set")
+			os.Exit(2)
+		}
+
+		cfg := validatorConfig(openapiPath)
+		cfg.BaselineSpecPath = baselinePath
+		reportDir := viper.GetString("report-dir")
+		generator := newGenerator(reportDir)
+		differ := validation.NewSpecDiffer(cfg)
+		report, err := differ.Diff(context.Background())
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		printFindings(openapiPath, report)
+		if err := generator.SaveDiffReport(report); err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		if err := generator.SaveRunReports(report, openapiPath); err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		recordRun(cmd.Name(), report)
+		json.NewEncoder(os.Stdout).Encode(report)
+
+		// Fail when the version bump does not match the changes
+		if report.FailedChecks > 0 {
+			os.Exit(ExitValidationFailed)
+		}
+		os.Exit(ExitSuccess)
+		return nil
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show earlier runs recorded in the report directory",
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded runs of the environment, most recent first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		records, err := historyStore().List(historyFilter())
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RUN\tCOMMAND\tVERSION\tCHECKS\tCRITICAL\tTESTS\tP95")
+		for _, record := range records {
+			run := validation.SummarizeRun(record)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%d\t%s\t%s\n", run.ID, run.Command, run.Version,
+				run.PassedChecks, run.TotalChecks, run.CriticalIssues, orDash(string(run.Status)), runLatency(run))
+		}
+		return w.Flush()
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <run>",
+	Short: "Print the report of a recorded run",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		record, err := historyStore().Get(args[0])
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(record)
+	},
+}
+
+var historyTrendsCmd = &cobra.Command{
+	Use:   "trends",
+	Short: "Show pass rate, critical issues and latency of recorded runs over time",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		records, err := historyStore().List(historyFilter())
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		trend := validation.NewHistoryTrend(records)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tCOMMAND\tVERSION\tPASS RATE\tCRITICAL\tP95\tREQ/S")
+		for _, run := range trend.Runs {
+			throughput := "-"
+			if run.HasPerformance {
+				throughput = fmt.Sprintf("%.2f", run.RequestsPerSec)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.1f%%\t%d\t%s\t%s\n", run.Timestamp.Format(time.RFC3339), run.Command,
+				run.Version, run.PassRate*100, run.CriticalIssues, runLatency(run), throughput)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if len(trend.Runs) > 1 {
+			fmt.Printf("\nOver %d runs: pass rate %+.1f points, critical issues %+d, P95 latency %+.1f%%\n",
+				len(trend.Runs), trend.PassRateChange, trend.CriticalIssuesChange, trend.LatencyP95Change*100)
+		}
+		return nil
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with driveby config files",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check a config file and its profiles against the config schema (defaults to --config)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := viper.GetString("config")
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if path == "" {
+			fmt.Fprintln(os.Stderr, "[ERROR] a config file argument or --config must be given")
+			os.Exit(ExitExecutionError)
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitValidationFailed)
+		}
+		if errs := cfg.Validate(); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+			}
+			os.Exit(ExitValidationFailed)
+		}
+		if profiles := cfg.ProfileNames(); len(profiles) > 0 {
+			fmt.Printf("%s is valid (profiles: %s)\n", path, strings.Join(profiles, ", "))
+		} else {
+			fmt.Printf("%s is valid\n", path)
+		}
 		return nil
 	},
 }
@@ -229,6 +460,7 @@ func Execute() error {
 
 func init() {
 	// Root command flags
+	rootCmd.PersistentFlags().String("config", "", "YAML config file; flags and environment variables override its options")
 	rootCmd.PersistentFlags().String("log-level", "info", "log level (debug, info, warn, error, fatal)")
 	rootCmd.PersistentFlags().String("api-url", "", "Base URL of the API to test")
 	rootCmd.PersistentFlags().String("protocol", "http", "Protocol to use (http or https)")
@@ -236,18 +468,89 @@ func init() {
 	rootCmd.PersistentFlags().String("openapi", "", "Path or URL to OpenAPI specification")
 	rootCmd.PersistentFlags().String("environment", "production", "Environment name (e.g., production, staging)")
 	rootCmd.PersistentFlags().String("version", "1.0.0", "API version being tested")
-	rootCmd.PersistentFlags().Duration("timeout", 30, "Request timeout in seconds")
-	rootCmd.PersistentFlags().String("validation-mode", "minimal", "validation mode (strict, minimal)")
+	rootCmd.PersistentFlags().Duration("timeout", 30*time.Second, "Request timeout (e.g. 30s)")
+	rootCmd.PersistentFlags().String("validation-mode", "minimal", "validation mode (strict, minimal, test-only, flexible)")
+	rootCmd.PersistentFlags().StringSlice("principles", nil, "Spec principles to run instead of those of the validation mode, e.g. P001,P002")
+	rootCmd.PersistentFlags().StringSlice("rules", nil, "Rule files defining additional spec principles")
 	rootCmd.PersistentFlags().String("report-dir", "/tmp/driveby-reports", "report output directory")
+	rootCmd.PersistentFlags().StringSlice("format", []string{"json", "markdown"}, "Report formats to write (json, markdown, junit, sarif, html)")
+	rootCmd.PersistentFlags().Bool("history", true, "Record the run in the history kept in the report directory")
+	rootCmd.PersistentFlags().Int("history-max-runs", 100, "Runs kept in the history per environment (0 keeps all)")
+	rootCmd.PersistentFlags().Duration("history-max-age", 0, "Runs older than this are removed from the history, e.g. 720h (0 keeps all)")
+	historyCmd.PersistentFlags().Int("limit", 20, "Most recent runs shown (0 shows all)")
+	historyCmd.PersistentFlags().String("command", "", "Only show runs of this command, e.g. load-only")
 	rootCmd.PersistentFlags().String("host", "", "Host of the API to test")
 
+	// Authentication flags; each credential is sent to the operations whose security schemes accept it
+	rootCmd.PersistentFlags().String("auth-token", "", "Token for bearer, OAuth2 and OpenID Connect security schemes")
+	rootCmd.PersistentFlags().String("auth-token-type", "Bearer", "Token type sent to operations without declared security")
+	rootCmd.PersistentFlags().String("auth-token-header", "Authorization", "Header the token is sent in to operations without declared security")
+	rootCmd.PersistentFlags().String("auth-api-key", "", "Key for apiKey security schemes, sent in the header, query parameter or cookie they name")
+	rootCmd.PersistentFlags().String("auth-api-key-header", "X-API-Key", "Header the API key is sent in to operations without declared security")
+	rootCmd.PersistentFlags().String("auth-username", "", "Username for basic security schemes")
+	rootCmd.PersistentFlags().String("auth-password", "", "Password for basic security schemes (prefer DRIVEBY_AUTH_PASSWORD)")
+	rootCmd.PersistentFlags().String("auth-token-url", "", "OAuth2 token endpoint; tokens fetched from it replace --auth-token and are refreshed before they expire")
+	rootCmd.PersistentFlags().String("auth-grant-type", "client_credentials", "OAuth2 grant: client_credentials, or password with --auth-username and --auth-password")
+	rootCmd.PersistentFlags().String("auth-client-id", "", "OAuth2 client ID")
+	rootCmd.PersistentFlags().String("auth-client-secret", "", "OAuth2 client secret (prefer DRIVEBY_AUTH_CLIENT_SECRET)")
+	rootCmd.PersistentFlags().StringSlice("auth-scopes", nil, "OAuth2 scopes to request")
+	rootCmd.PersistentFlags().Duration("auth-refresh-before", 30*time.Second, "Refresh OAuth2 tokens this long before they expire")
+	rootCmd.PersistentFlags().StringSlice("auth-scope-set", nil, "Token granted known OAuth2 scopes, e.g. \"tasks.read+tasks.write=TOKEN\", or scopes alone to fetch one from --auth-token-url; checks scope enforcement (repeatable)")
+	rootCmd.PersistentFlags().StringSlice("auth-scheme", nil, "Credential for a security scheme by name, e.g. \"partnerKey=abc\" or \"admin=user:pass\" (repeatable)")
+
 	// Load test specific flags
-	loadOnlyCmd.Flags().Duration("max-latency-p95", 500, "Maximum allowed P95 latency in milliseconds")
+	loadOnlyCmd.Flags().Duration("max-latency-p95", 500*time.Millisecond, "Maximum allowed P95 latency (e.g. 500ms)")
 	loadOnlyCmd.Flags().Float64("min-success-rate", 0.99, "Minimum required success rate (0-1)")
-	loadOnlyCmd.Flags().Int("concurrent-users", 10, "Number of concurrent users for load testing")
-	loadOnlyCmd.Flags().Duration("test-duration", 300, "Duration of load test in seconds")
+	loadOnlyCmd.Flags().Int("concurrent-users", 10, "Number of virtual users in the closed load model (requests per second in the open model unless --rate is set)")
+	loadOnlyCmd.Flags().Duration("test-duration", 5*time.Minute, "Duration of load test (e.g. 5m)")
+	loadOnlyCmd.Flags().String("load-profile", "constant", "Load profile (constant, ramp, step, spike, soak, capacity)")
+	loadOnlyCmd.Flags().Int("rate", 0, "Peak requests per second (defaults to --concurrent-users)")
+	loadOnlyCmd.Flags().Int("start-rate", 0, "Starting rate of ramp and step profiles and baseline of spike (defaults to a tenth of --rate)")
+	loadOnlyCmd.Flags().Int("profile-steps", 5, "Number of stages in ramp, step and soak profiles")
+	loadOnlyCmd.Flags().Duration("spike-duration", 0, "Length of the spike in the spike profile, e.g. 30s (defaults to a fifth of the test)")
+	loadOnlyCmd.Flags().Duration("probe-duration", 0, "Length of each probe of the capacity profile, e.g. 10s (defaults to 10s)")
+	loadOnlyCmd.Flags().Int("max-probes", 0, "Maximum number of probes of the capacity profile (defaults to 10)")
+	loadOnlyCmd.Flags().Bool("perf-baseline", false, "Compare the load test with the baseline recorded in the report directory and record passing runs")
+	loadOnlyCmd.Flags().String("perf-baseline-version", "", "Version whose baseline is compared (defaults to the latest baseline of the environment)")
+	loadOnlyCmd.Flags().Float64("p95-tolerance", 0.10, "Allowed P95 latency increase over the baseline (0-1)")
+	loadOnlyCmd.Flags().Float64("throughput-tolerance", 0.10, "Allowed throughput decrease below the baseline (0-1)")
+	loadOnlyCmd.Flags().Duration("min-p95-increase", 5*time.Millisecond, "P95 latency increases over the baseline below this are treated as noise")
+	loadOnlyCmd.Flags().Bool("update-perf-baseline", true, "Record passing runs as the baseline of their environment and version")
+	loadOnlyCmd.Flags().String("load-model", "open", "Load model: open (requests at a planned rate) or closed (virtual users running journeys)")
+	loadOnlyCmd.Flags().Duration("think-time", 0, "Closed model: pause between the steps of a journey, e.g. 1s")
+	loadOnlyCmd.Flags().Duration("pacing", 0, "Closed model: minimum time between the starts of a user's journeys, e.g. 5s")
+	loadOnlyCmd.Flags().StringSlice("journey", nil, "Closed model journey, e.g. \"browse=listTasks>GET /tasks/{id}\" (repeatable; defaults to every load target)")
+	loadOnlyCmd.Flags().StringSlice("load-weight", nil, "Traffic weight, e.g. \"reads=70\", \"writes=30\", \"tag:reports=5\" or \"createTask=2\" (repeatable)")
+	loadOnlyCmd.Flags().StringSlice("load-include", nil, "Only load test these operations (operationId, \"METHOD /path\" or \"tag:<name>\")")
+	loadOnlyCmd.Flags().StringSlice("load-exclude", nil, "Never load test these operations (operationId, \"METHOD /path\" or \"tag:<name>\")")
+	loadOnlyCmd.Flags().StringSlice("endpoint-slo", nil, "Per-operation SLO, e.g. \"GET /reports@p95=800ms;p99=2s;success=0.95\" or \"listReports@p95=800ms\" (repeatable)")
+
+	// Functional test specific flags
+	functionOnlyCmd.Flags().StringSlice("suite", nil, "Path to a YAML/JSON test suite file (repeatable)")
+
+	// Run shares the functional and load test flags
+	runCmd.Flags().AddFlag(functionOnlyCmd.Flags().Lookup("suite"))
+	for _, name := range []string{"max-latency-p95", "min-success-rate", "concurrent-users", "test-duration", "endpoint-slo",
+		"load-profile", "rate", "start-rate", "profile-steps", "spike-duration",
+		"probe-duration", "max-probes",
+		"perf-baseline", "perf-baseline-version", "p95-tolerance", "throughput-tolerance", "min-p95-increase",
+		"update-perf-baseline", "load-model", "think-time", "pacing", "journey", "load-weight", "load-include", "load-exclude"} {
+		runCmd.Flags().AddFlag(loadOnlyCmd.Flags().Lookup(name))
+	}
+
+	// Fix specific flags
+	fixCmd.Flags().String("output", "", "Where to write the repaired spec (required; the --openapi path replaces the spec, keeping a .orig backup; .yaml/.yml writes YAML)")
+
+	// Diff specific flags
+	diffCmd.Flags().String("baseline", "", "Path or URL to the baseline OpenAPI specification; --openapi is the candidate")
+
+	// Fuzz specific flags
+	fuzzCmd.Flags().Int64("seed", 0, "Random seed; reuse a reported seed to replay a run (0 picks one)")
+	fuzzCmd.Flags().Int("iterations", 1000, "Maximum number of fuzzed requests (0 for no limit when --duration is set)")
+	fuzzCmd.Flags().Duration("duration", 0, "Maximum fuzzing time, e.g. 5m (0 for no limit)")
 
 	// Bind flags to viper
+	viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
 	viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
 	viper.BindPFlag("api-url", rootCmd.PersistentFlags().Lookup("api-url"))
 	viper.BindPFlag("protocol", rootCmd.PersistentFlags().Lookup("protocol"))
@@ -257,21 +560,82 @@ func init() {
 	viper.BindPFlag("version", rootCmd.PersistentFlags().Lookup("version"))
 	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
 	viper.BindPFlag("validation-mode", rootCmd.PersistentFlags().Lookup("validation-mode"))
+	viper.BindPFlag("principles", rootCmd.PersistentFlags().Lookup("principles"))
+	viper.BindPFlag("rules", rootCmd.PersistentFlags().Lookup("rules"))
 	viper.BindPFlag("report-dir", rootCmd.PersistentFlags().Lookup("report-dir"))
+	viper.BindPFlag("format", rootCmd.PersistentFlags().Lookup("format"))
+	viper.BindPFlag("history", rootCmd.PersistentFlags().Lookup("history"))
+	viper.BindPFlag("history-max-runs", rootCmd.PersistentFlags().Lookup("history-max-runs"))
+	viper.BindPFlag("history-max-age", rootCmd.PersistentFlags().Lookup("history-max-age"))
+	viper.BindPFlag("limit", historyCmd.PersistentFlags().Lookup("limit"))
+	viper.BindPFlag("command", historyCmd.PersistentFlags().Lookup("command"))
 	viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("host"))
 
+	// Bind authentication flags
+	for _, name := range []string{"auth-token", "auth-token-type", "auth-token-header", "auth-api-key",
+		"auth-api-key-header", "auth-username", "auth-password", "auth-scheme", "auth-token-url", "auth-grant-type",
+		"auth-client-id", "auth-client-secret", "auth-scopes", "auth-refresh-before", "auth-scope-set"} {
+		viper.BindPFlag(name, rootCmd.PersistentFlags().Lookup(name))
+	}
+
 	// Bind load test flags
 	viper.BindPFlag("max-latency-p95", loadOnlyCmd.Flags().Lookup("max-latency-p95"))
 	viper.BindPFlag("min-success-rate", loadOnlyCmd.Flags().Lookup("min-success-rate"))
 	viper.BindPFlag("concurrent-users", loadOnlyCmd.Flags().Lookup("concurrent-users"))
 	viper.BindPFlag("test-duration", loadOnlyCmd.Flags().Lookup("test-duration"))
+	viper.BindPFlag("endpoint-slo", loadOnlyCmd.Flags().Lookup("endpoint-slo"))
+	viper.BindPFlag("load-profile", loadOnlyCmd.Flags().Lookup("load-profile"))
+	viper.BindPFlag("rate", loadOnlyCmd.Flags().Lookup("rate"))
+	viper.BindPFlag("start-rate", loadOnlyCmd.Flags().Lookup("start-rate"))
+	viper.BindPFlag("profile-steps", loadOnlyCmd.Flags().Lookup("profile-steps"))
+	viper.BindPFlag("spike-duration", loadOnlyCmd.Flags().Lookup("spike-duration"))
+	viper.BindPFlag("probe-duration", loadOnlyCmd.Flags().Lookup("probe-duration"))
+	viper.BindPFlag("max-probes", loadOnlyCmd.Flags().Lookup("max-probes"))
+	viper.BindPFlag("perf-baseline", loadOnlyCmd.Flags().Lookup("perf-baseline"))
+	viper.BindPFlag("perf-baseline-version", loadOnlyCmd.Flags().Lookup("perf-baseline-version"))
+	viper.BindPFlag("p95-tolerance", loadOnlyCmd.Flags().Lookup("p95-tolerance"))
+	viper.BindPFlag("throughput-tolerance", loadOnlyCmd.Flags().Lookup("throughput-tolerance"))
+	viper.BindPFlag("min-p95-increase", loadOnlyCmd.Flags().Lookup("min-p95-increase"))
+	viper.BindPFlag("update-perf-baseline", loadOnlyCmd.Flags().Lookup("update-perf-baseline"))
+	viper.BindPFlag("load-model", loadOnlyCmd.Flags().Lookup("load-model"))
+	viper.BindPFlag("think-time", loadOnlyCmd.Flags().Lookup("think-time"))
+	viper.BindPFlag("pacing", loadOnlyCmd.Flags().Lookup("pacing"))
+	viper.BindPFlag("journey", loadOnlyCmd.Flags().Lookup("journey"))
+	viper.BindPFlag("load-weight", loadOnlyCmd.Flags().Lookup("load-weight"))
+	viper.BindPFlag("load-include", loadOnlyCmd.Flags().Lookup("load-include"))
+	viper.BindPFlag("load-exclude", loadOnlyCmd.Flags().Lookup("load-exclude"))
+
+	// Bind functional test flags
+	viper.BindPFlag("suite", functionOnlyCmd.Flags().Lookup("suite"))
+
+	// Bind fix flags
+	viper.BindPFlag("fix-output", fixCmd.Flags().Lookup("output"))
+
+	// Bind diff flags
+	viper.BindPFlag("baseline", diffCmd.Flags().Lookup("baseline"))
+
+	// Bind fuzz flags
+	viper.BindPFlag("fuzz-seed", fuzzCmd.Flags().Lookup("seed"))
+	viper.BindPFlag("fuzz-iterations", fuzzCmd.Flags().Lookup("iterations"))
+	viper.BindPFlag("fuzz-duration", fuzzCmd.Flags().Lookup("duration"))
 
 	// Add commands
+	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(validateOnlyCmd)
 	rootCmd.AddCommand(functionOnlyCmd)
 	rootCmd.AddCommand(loadOnlyCmd)
+	rootCmd.AddCommand(fuzzCmd)
+	rootCmd.AddCommand(fixCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyTrendsCmd)
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
 
 	// Set up environment variable bindings
+	viper.BindEnv("config", "DRIVEBY_CONFIG")
 	viper.BindEnv("api-url", "DRIVEBY_API_URL")
 	viper.BindEnv("protocol", "DRIVEBY_PROTOCOL")
 	viper.BindEnv("port", "DRIVEBY_PORT")
@@ -281,15 +645,365 @@ func init() {
 	viper.BindEnv("version", "DRIVEBY_VERSION")
 	viper.BindEnv("timeout", "DRIVEBY_TIMEOUT")
 	viper.BindEnv("validation-mode", "DRIVEBY_VALIDATION_MODE")
+	viper.BindEnv("principles", "DRIVEBY_PRINCIPLES")
+	viper.BindEnv("rules", "DRIVEBY_RULES")
 	viper.BindEnv("report-dir", "DRIVEBY_REPORT_DIR")
+	viper.BindEnv("format", "DRIVEBY_FORMAT")
+	viper.BindEnv("history", "DRIVEBY_HISTORY")
+	viper.BindEnv("history-max-runs", "DRIVEBY_HISTORY_MAX_RUNS")
+	viper.BindEnv("history-max-age", "DRIVEBY_HISTORY_MAX_AGE")
+	viper.BindEnv("auth-token", "DRIVEBY_AUTH_TOKEN")
+	viper.BindEnv("auth-token-type", "DRIVEBY_AUTH_TOKEN_TYPE")
+	viper.BindEnv("auth-token-header", "DRIVEBY_AUTH_TOKEN_HEADER")
+	viper.BindEnv("auth-api-key", "DRIVEBY_AUTH_API_KEY")
+	viper.BindEnv("auth-api-key-header", "DRIVEBY_AUTH_API_KEY_HEADER")
+	viper.BindEnv("auth-username", "DRIVEBY_AUTH_USERNAME")
+	viper.BindEnv("auth-password", "DRIVEBY_AUTH_PASSWORD")
+	viper.BindEnv("auth-scheme", "DRIVEBY_AUTH_SCHEME")
+	viper.BindEnv("auth-token-url", "DRIVEBY_AUTH_TOKEN_URL")
+	viper.BindEnv("auth-grant-type", "DRIVEBY_AUTH_GRANT_TYPE")
+	viper.BindEnv("auth-client-id", "DRIVEBY_AUTH_CLIENT_ID")
+	viper.BindEnv("auth-client-secret", "DRIVEBY_AUTH_CLIENT_SECRET")
+	viper.BindEnv("auth-scopes", "DRIVEBY_AUTH_SCOPES")
+	viper.BindEnv("auth-refresh-before", "DRIVEBY_AUTH_REFRESH_BEFORE")
+	viper.BindEnv("auth-scope-set", "DRIVEBY_AUTH_SCOPE_SET")
 	viper.BindEnv("max-latency-p95", "DRIVEBY_MAX_LATENCY_P95")
 	viper.BindEnv("min-success-rate", "DRIVEBY_MIN_SUCCESS_RATE")
 	viper.BindEnv("concurrent-users", "DRIVEBY_CONCURRENT_USERS")
 	viper.BindEnv("test-duration", "DRIVEBY_TEST_DURATION")
+	viper.BindEnv("endpoint-slo", "DRIVEBY_ENDPOINT_SLO")
+	viper.BindEnv("load-profile", "DRIVEBY_LOAD_PROFILE")
+	viper.BindEnv("rate", "DRIVEBY_RATE")
+	viper.BindEnv("start-rate", "DRIVEBY_START_RATE")
+	viper.BindEnv("profile-steps", "DRIVEBY_PROFILE_STEPS")
+	viper.BindEnv("spike-duration", "DRIVEBY_SPIKE_DURATION")
+	viper.BindEnv("probe-duration", "DRIVEBY_PROBE_DURATION")
+	viper.BindEnv("max-probes", "DRIVEBY_MAX_PROBES")
+	viper.BindEnv("perf-baseline", "DRIVEBY_PERF_BASELINE")
+	viper.BindEnv("perf-baseline-version", "DRIVEBY_PERF_BASELINE_VERSION")
+	viper.BindEnv("p95-tolerance", "DRIVEBY_P95_TOLERANCE")
+	viper.BindEnv("throughput-tolerance", "DRIVEBY_THROUGHPUT_TOLERANCE")
+	viper.BindEnv("min-p95-increase", "DRIVEBY_MIN_P95_INCREASE")
+	viper.BindEnv("update-perf-baseline", "DRIVEBY_UPDATE_PERF_BASELINE")
+	viper.BindEnv("load-model", "DRIVEBY_LOAD_MODEL")
+	viper.BindEnv("think-time", "DRIVEBY_THINK_TIME")
+	viper.BindEnv("pacing", "DRIVEBY_PACING")
+	viper.BindEnv("journey", "DRIVEBY_JOURNEY")
+	viper.BindEnv("load-weight", "DRIVEBY_LOAD_WEIGHT")
+	viper.BindEnv("load-include", "DRIVEBY_LOAD_INCLUDE")
+	viper.BindEnv("load-exclude", "DRIVEBY_LOAD_EXCLUDE")
+	viper.BindEnv("suite", "DRIVEBY_SUITE")
+	viper.BindEnv("fix-output", "DRIVEBY_FIX_OUTPUT")
+	viper.BindEnv("baseline", "DRIVEBY_BASELINE")
+	viper.BindEnv("fuzz-seed", "DRIVEBY_FUZZ_SEED")
+	viper.BindEnv("fuzz-iterations", "DRIVEBY_FUZZ_ITERATIONS")
+	viper.BindEnv("fuzz-duration", "DRIVEBY_FUZZ_DURATION")
 
 	viper.AutomaticEnv()
 }
 
+// loadConfigFile merges the options of a config file, and of the profile of the selected
+// environment, under the flags and environment variables
+func loadConfigFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return fmt.Errorf("invalid config file %s: %w", path, errors.Join(errs...))
+	}
+	// The file itself may select the environment, which picks the profile
+	base, err := (&config.Config{Settings: cfg.Settings}).Values("")
+	if err != nil {
+		return err
+	}
+	if err := viper.MergeConfigMap(base); err != nil {
+		return fmt.Errorf("failed to apply config file: %w", err)
+	}
+	values, err := cfg.Values(viper.GetString("environment"))
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := viper.MergeConfigMap(values); err != nil {
+		return fmt.Errorf("failed to apply config file: %w", err)
+	}
+	return nil
+}
+
+// specPath returns the OpenAPI spec path from --openapi or DRIVEBY_OPENAPI, exiting when
+// neither is set
+func specPath() string {
+	openapiPath := viper.GetString("openapi")
+	if openapiPath == "" {
+		openapiPath = os.Getenv("DRIVEBY_OPENAPI")
+	}
+	if openapiPath == "" {
+		fmt.Fprintln(os.Stderr, "[ERROR] --openapi flag or DRIVEBY_OPENAPI env variable must be set")
+		os.Exit(2)
+	}
+	return openapiPath
+}
+
+// apiBaseURL returns --api-url, or the URL built from --protocol, --host and --port
+func apiBaseURL() string {
+	if baseURL := viper.GetString("api-url"); baseURL != "" {
+		return baseURL
+	}
+	protocol := viper.GetString("protocol")
+	port := viper.GetString("port")
+	if protocol == "https" && port == "8080" {
+		port = "443"
+	}
+	return fmt.Sprintf("%s://%s:%s", protocol, viper.GetString("host"), port)
+}
+
+// validatorConfig returns the settings every command uses for the spec at openapiPath;
+// commands add their own
+func validatorConfig(openapiPath string) validation.ValidatorConfig {
+	return validation.ValidatorConfig{
+		BaseURL:     apiBaseURL(),
+		SpecPath:    openapiPath,
+		Environment: viper.GetString("environment"),
+		Version:     viper.GetString("version"),
+		Timeout:     viper.GetDuration("timeout"),
+	}
+}
+
+// authConfig builds the credentials sent to the API, or nil when none are configured
+func authConfig() *validation.AuthConfig {
+	var schemes map[string]string
+	for _, value := range viper.GetStringSlice("auth-scheme") {
+		name, credential, err := validation.ParseAuthScheme(value)
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		if schemes == nil {
+			schemes = make(map[string]string)
+		}
+		schemes[name] = credential
+	}
+	auth := &validation.AuthConfig{
+		Token:        viper.GetString("auth-token"),
+		TokenType:    viper.GetString("auth-token-type"),
+		TokenHeader:  viper.GetString("auth-token-header"),
+		APIKey:       viper.GetString("auth-api-key"),
+		APIKeyHeader: viper.GetString("auth-api-key-header"),
+		Username:     viper.GetString("auth-username"),
+		Password:     viper.GetString("auth-password"),
+		Schemes:      schemes,
+	}
+	var tokenSource *validation.OAuth2Config
+	if tokenURL := viper.GetString("auth-token-url"); tokenURL != "" {
+		oauth := validation.OAuth2Config{
+			TokenURL:      tokenURL,
+			GrantType:     viper.GetString("auth-grant-type"),
+			ClientID:      viper.GetString("auth-client-id"),
+			ClientSecret:  viper.GetString("auth-client-secret"),
+			Scopes:        viper.GetStringSlice("auth-scopes"),
+			RefreshBefore: viper.GetDuration("auth-refresh-before"),
+			Timeout:       viper.GetDuration("timeout"),
+		}
+		// The password grant sends the username and password to the token endpoint only
+		if oauth.GrantType == validation.OAuth2GrantPassword {
+			oauth.Username, oauth.Password = auth.Username, auth.Password
+			auth.Username, auth.Password = "", ""
+		}
+		provider, err := validation.NewOAuth2Provider(oauth)
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		auth.OAuth2 = provider
+		tokenSource = &oauth
+	}
+	for _, value := range viper.GetStringSlice("auth-scope-set") {
+		scopes, token, err := validation.ParseScopedCredential(value)
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		credential := validation.ScopedCredential{Scopes: scopes, Token: token}
+		if token == "" {
+			// Tokens with exactly these scopes are fetched from the token endpoint
+			if tokenSource == nil {
+				logAndExit(fmt.Errorf("scope set %q has no token and no --auth-token-url is set", value), ExitExecutionError)
+			}
+			oauth := *tokenSource
+			oauth.Scopes = scopes
+			if credential.OAuth2, err = validation.NewOAuth2Provider(oauth); err != nil {
+				logAndExit(err, ExitExecutionError)
+			}
+		}
+		auth.ScopedCredentials = append(auth.ScopedCredentials, credential)
+	}
+	if auth.Token == "" && auth.APIKey == "" && auth.Username == "" && len(schemes) == 0 && auth.OAuth2 == nil &&
+		len(auth.ScopedCredentials) == 0 {
+		return nil
+	}
+	return auth
+}
+
+// performanceTargets builds the load test targets and profile from the load test flags
+func performanceTargets() *validation.PerformanceTargetConfig {
+	return &validation.PerformanceTargetConfig{
+		MaxLatencyP95:   viper.GetDuration("max-latency-p95"),
+		MinSuccessRate:  viper.GetFloat64("min-success-rate"),
+		ConcurrentUsers: viper.GetInt("concurrent-users"),
+		Duration:        viper.GetDuration("test-duration"),
+		Endpoints:       endpointTargets(),
+		Rate:            viper.GetInt("rate"),
+		Profile: validation.LoadProfile{
+			Type:          validation.LoadProfileType(viper.GetString("load-profile")),
+			StartRate:     viper.GetInt("start-rate"),
+			Steps:         viper.GetInt("profile-steps"),
+			SpikeDuration: viper.GetDuration("spike-duration"),
+			ProbeDuration: viper.GetDuration("probe-duration"),
+			MaxProbes:     viper.GetInt("max-probes"),
+		},
+		Model:     validation.LoadModel(viper.GetString("load-model")),
+		ThinkTime: viper.GetDuration("think-time"),
+		Pacing:    viper.GetDuration("pacing"),
+		Journeys:  journeys(),
+		Weights:   loadWeights(),
+		Include:   viper.GetStringSlice("load-include"),
+		Exclude:   viper.GetStringSlice("load-exclude"),
+		Baseline:  performanceBaseline(),
+	}
+}
+
+// performanceBaseline builds the baseline comparison settings, which are off unless
+// --perf-baseline is set
+func performanceBaseline() *validation.BaselineConfig {
+	if !viper.GetBool("perf-baseline") {
+		return nil
+	}
+	return &validation.BaselineConfig{
+		Path:                filepath.Join(viper.GetString("report-dir"), validation.BaselineFileName),
+		Version:             viper.GetString("perf-baseline-version"),
+		P95Tolerance:        viper.GetFloat64("p95-tolerance"),
+		ThroughputTolerance: viper.GetFloat64("throughput-tolerance"),
+		MinP95Increase:      viper.GetDuration("min-p95-increase"),
+		Update:              viper.GetBool("update-perf-baseline"),
+	}
+}
+
+// loadWeights parses the traffic weights given with --load-weight
+func loadWeights() map[string]float64 {
+	values := viper.GetStringSlice("load-weight")
+	if len(values) == 0 {
+		return nil
+	}
+	weights := make(map[string]float64, len(values))
+	for _, value := range values {
+		key, weight, err := validation.ParseLoadWeight(value)
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		weights[key] = weight
+	}
+	return weights
+}
+
+// journeys parses the closed-model journeys given with --journey
+func journeys() []validation.Journey {
+	var journeys []validation.Journey
+	for _, value := range viper.GetStringSlice("journey") {
+		journey, err := validation.ParseJourney(value)
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		journeys = append(journeys, journey)
+	}
+	return journeys
+}
+
+// endpointTargets parses the per-operation SLOs given with --endpoint-slo
+func endpointTargets() map[string]validation.EndpointTarget {
+	values := viper.GetStringSlice("endpoint-slo")
+	if len(values) == 0 {
+		return nil
+	}
+	targets := make(map[string]validation.EndpointTarget, len(values))
+	for _, value := range values {
+		operation, target, err := validation.ParseEndpointTarget(value)
+		if err != nil {
+			logAndExit(err, ExitExecutionError)
+		}
+		targets[operation] = target
+	}
+	return targets
+}
+
+// newGenerator creates a report generator writing the formats selected with --format
+func newGenerator(reportDir string) *report.Generator {
+	formats, err := report.ParseFormats(viper.GetStringSlice("format"))
+	if err != nil {
+		logAndExit(err, ExitExecutionError)
+	}
+	return report.NewGenerator(reportDir, formats...)
+}
+
+// printFindings lists the spec findings of a report on stderr as file:line:column
+// references that editors and terminals can open
+func printFindings(specPath string, report *validation.ValidationReport) {
+	for _, finding := range validation.SpecFindings(report) {
+		location := specPath
+		if finding.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", specPath, finding.Line, finding.Column)
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s %s: %s\n", location, finding.PrincipleID, finding.Severity, finding.Message)
+	}
+}
+
+// historyStore opens the run history in the report directory
+func historyStore() *validation.HistoryStore {
+	return validation.NewHistoryStore(filepath.Join(viper.GetString("report-dir"), validation.HistoryDirName), validation.HistoryRetention{
+		MaxRuns: viper.GetInt("history-max-runs"),
+		MaxAge:  viper.GetDuration("history-max-age"),
+	})
+}
+
+// historyFilter selects the runs of the configured environment for the history commands
+func historyFilter() validation.HistoryFilter {
+	return validation.HistoryFilter{
+		Environment: viper.GetString("environment"),
+		Command:     viper.GetString("command"),
+		Limit:       viper.GetInt("limit"),
+	}
+}
+
+// recordRun keeps a command's report in the run history unless --history=false is set
+func recordRun(command string, report *validation.ValidationReport) {
+	if !viper.GetBool("history") {
+		return
+	}
+	logger, err := validation.NewLogger(filepath.Join(viper.GetString("report-dir"), validation.HistoryDirName, "history.log"))
+	if err != nil {
+		logAndExit(err, ExitExecutionError)
+	}
+	logger.UseHistory(historyStore(), command)
+	if err := logger.LogReport(report); err != nil {
+		logAndExit(err, ExitExecutionError)
+	}
+}
+
+// runLatency formats the P95 latency of a run that included a load test
+func runLatency(run validation.RunSummary) string {
+	if !run.HasPerformance {
+		return "-"
+	}
+	return run.LatencyP95.String()
+}
+
+// orDash returns the value, or a dash when it is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
 // logAndExit logs the error and exits with the specified code
 func logAndExit(err error, exitCode int) {
 	json.NewEncoder(os.Stdout).Encode(map[string]interface{}{
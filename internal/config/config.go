@@ -0,0 +1,451 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/meter-peter/driveby/internal/report"
+	"github.com/meter-peter/driveby/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a driveby configuration file. Its settings apply to every environment, and
+// the profile named after the selected environment overrides them.
+type Config struct {
+	Settings `yaml:",inline"`
+	Profiles map[string]Settings `yaml:"profiles"`
+}
+
+// Settings are the options of a configuration file or profile. Each option sets the
+// command line flag named by its flag tag; options left out keep the flag's default.
+type Settings struct {
+	LogLevel    *string              `yaml:"log_level" flag:"log-level"`
+	API         *APISettings         `yaml:"api"`
+	Validation  *ValidationSettings  `yaml:"validation"`
+	Auth        *AuthSettings        `yaml:"auth"`
+	Functional  *FunctionalSettings  `yaml:"functional"`
+	Performance *PerformanceSettings `yaml:"performance"`
+	Fuzz        *FuzzSettings        `yaml:"fuzz"`
+	Reports     *ReportSettings      `yaml:"reports"`
+}
+
+// APISettings locate the API under test
+type APISettings struct {
+	BaseURL  *string        `yaml:"base_url" flag:"api-url"`
+	Protocol *string        `yaml:"protocol" flag:"protocol"`
+	Host     *string        `yaml:"host" flag:"host"`
+	Port     *string        `yaml:"port" flag:"port"`
+	Timeout  *time.Duration `yaml:"timeout" flag:"timeout"`
+}
+
+// ValidationSettings select the spec and how it is validated
+type ValidationSettings struct {
+	OpenAPIPath *string  `yaml:"openapi_path" flag:"openapi"`
+	Mode        *string  `yaml:"mode" flag:"validation-mode"`
+	Environment *string  `yaml:"environment" flag:"environment"`
+	Version     *string  `yaml:"version" flag:"version"`
+	Principles  []string `yaml:"principles" flag:"principles"`
+	Rules       []string `yaml:"rules" flag:"rules"`
+}
+
+// AuthSettings hold the credentials sent to the API
+type AuthSettings struct {
+	Token        *string         `yaml:"token" flag:"auth-token"`
+	TokenType    *string         `yaml:"token_type" flag:"auth-token-type"`
+	TokenHeader  *string         `yaml:"token_header" flag:"auth-token-header"`
+	APIKey       *string         `yaml:"api_key" flag:"auth-api-key"`
+	APIKeyHeader *string         `yaml:"api_key_header" flag:"auth-api-key-header"`
+	Username     *string         `yaml:"username" flag:"auth-username"`
+	Password     *string         `yaml:"password" flag:"auth-password"`
+	Schemes      AuthSchemes     `yaml:"schemes" flag:"auth-scheme"`
+	OAuth2       *OAuth2Settings `yaml:"oauth2"`
+	ScopeSets    ScopeSets       `yaml:"scope_sets" flag:"auth-scope-set"`
+}
+
+// OAuth2Settings configure fetching tokens from an OAuth2 token endpoint
+type OAuth2Settings struct {
+	TokenURL      *string        `yaml:"token_url" flag:"auth-token-url"`
+	GrantType     *string        `yaml:"grant_type" flag:"auth-grant-type"`
+	ClientID      *string        `yaml:"client_id" flag:"auth-client-id"`
+	ClientSecret  *string        `yaml:"client_secret" flag:"auth-client-secret"`
+	Scopes        []string       `yaml:"scopes" flag:"auth-scopes"`
+	RefreshBefore *time.Duration `yaml:"refresh_before" flag:"auth-refresh-before"`
+}
+
+// FunctionalSettings configure the functional tests
+type FunctionalSettings struct {
+	Suites []string `yaml:"suites" flag:"suite"`
+}
+
+// PerformanceSettings configure the load test
+type PerformanceSettings struct {
+	MaxLatencyP95   *time.Duration       `yaml:"max_latency_p95" flag:"max-latency-p95"`
+	MinSuccessRate  *float64             `yaml:"min_success_rate" flag:"min-success-rate"`
+	ConcurrentUsers *int                 `yaml:"concurrent_users" flag:"concurrent-users"`
+	Duration        *time.Duration       `yaml:"duration" flag:"test-duration"`
+	Rate            *int                 `yaml:"rate" flag:"rate"`
+	Profile         *string              `yaml:"profile" flag:"load-profile"`
+	StartRate       *int                 `yaml:"start_rate" flag:"start-rate"`
+	Steps           *int                 `yaml:"steps" flag:"profile-steps"`
+	SpikeDuration   *time.Duration       `yaml:"spike_duration" flag:"spike-duration"`
+	ProbeDuration   *time.Duration       `yaml:"probe_duration" flag:"probe-duration"`
+	MaxProbes       *int                 `yaml:"max_probes" flag:"max-probes"`
+	Model           *string              `yaml:"model" flag:"load-model"`
+	ThinkTime       *time.Duration       `yaml:"think_time" flag:"think-time"`
+	Pacing          *time.Duration       `yaml:"pacing" flag:"pacing"`
+	Include         []string             `yaml:"include" flag:"load-include"`
+	Exclude         []string             `yaml:"exclude" flag:"load-exclude"`
+	Weights         Weights              `yaml:"weights" flag:"load-weight"`
+	Journeys        Journeys             `yaml:"journeys" flag:"journey"`
+	Endpoints       EndpointSLOs         `yaml:"endpoints" flag:"endpoint-slo"`
+	Baseline        *PerformanceBaseline `yaml:"baseline"`
+}
+
+// PerformanceBaseline configures the comparison of load tests with earlier runs
+type PerformanceBaseline struct {
+	Enabled             *bool          `yaml:"enabled" flag:"perf-baseline"`
+	Version             *string        `yaml:"version" flag:"perf-baseline-version"`
+	P95Tolerance        *float64       `yaml:"p95_tolerance" flag:"p95-tolerance"`
+	ThroughputTolerance *float64       `yaml:"throughput_tolerance" flag:"throughput-tolerance"`
+	MinP95Increase      *time.Duration `yaml:"min_p95_increase" flag:"min-p95-increase"`
+	Update              *bool          `yaml:"update" flag:"update-perf-baseline"`
+}
+
+// FuzzSettings configure the fuzz command
+type FuzzSettings struct {
+	Seed       *int64         `yaml:"seed" flag:"fuzz-seed"`
+	Iterations *int           `yaml:"iterations" flag:"fuzz-iterations"`
+	Duration   *time.Duration `yaml:"duration" flag:"fuzz-duration"`
+}
+
+// ReportSettings configure the reports and run history
+type ReportSettings struct {
+	Dir            *string        `yaml:"dir" flag:"report-dir"`
+	Formats        []string       `yaml:"formats" flag:"format"`
+	History        *bool          `yaml:"history" flag:"history"`
+	HistoryMaxRuns *int           `yaml:"history_max_runs" flag:"history-max-runs"`
+	HistoryMaxAge  *time.Duration `yaml:"history_max_age" flag:"history-max-age"`
+}
+
+// AuthSchemes are credentials keyed by the name of a security scheme of the spec
+type AuthSchemes map[string]string
+
+// ScopeSets are tokens granted known OAuth2 scopes
+type ScopeSets []ScopeSet
+
+// ScopeSet is a token granted a set of OAuth2 scopes. Without a token, one with these
+// scopes is fetched from the OAuth2 token endpoint.
+type ScopeSet struct {
+	Scopes []string `yaml:"scopes"`
+	Token  string   `yaml:"token"`
+}
+
+// Weights are traffic weights keyed by "METHOD path", operationId, "tag:<name>", "reads" or "writes"
+type Weights map[string]float64
+
+// Journeys are closed-model journeys: the operations each one runs, in order
+type Journeys map[string][]string
+
+// EndpointSLOs are per-operation SLOs keyed by "METHOD path" or operationId
+type EndpointSLOs map[string]EndpointSLO
+
+// EndpointSLO is the SLO of one operation
+type EndpointSLO struct {
+	P95     time.Duration `yaml:"p95"`
+	P99     time.Duration `yaml:"p99"`
+	Success float64       `yaml:"success"`
+}
+
+// flagValues converts the credentials to --auth-scheme values
+func (a AuthSchemes) flagValues() []string {
+	var values []string
+	for _, name := range sortedKeys(a) {
+		values = append(values, name+"="+a[name])
+	}
+	return values
+}
+
+// flagValues converts the scope sets to --auth-scope-set values
+func (s ScopeSets) flagValues() []string {
+	var values []string
+	for _, set := range s {
+		value := strings.Join(set.Scopes, "+")
+		if set.Token != "" {
+			value += "=" + set.Token
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// flagValues converts the weights to --load-weight values
+func (w Weights) flagValues() []string {
+	var values []string
+	for _, key := range sortedKeys(w) {
+		values = append(values, fmt.Sprintf("%s=%g", key, w[key]))
+	}
+	return values
+}
+
+// flagValues converts the journeys to --journey values
+func (j Journeys) flagValues() []string {
+	var values []string
+	for _, name := range sortedKeys(j) {
+		values = append(values, name+"="+strings.Join(j[name], ">"))
+	}
+	return values
+}
+
+// flagValues converts the SLOs to --endpoint-slo values
+func (e EndpointSLOs) flagValues() []string {
+	var values []string
+	for _, operation := range sortedKeys(e) {
+		slo := e[operation]
+		var settings []string
+		if slo.P95 > 0 {
+			settings = append(settings, "p95="+slo.P95.String())
+		}
+		if slo.P99 > 0 {
+			settings = append(settings, "p99="+slo.P99.String())
+		}
+		if slo.Success > 0 {
+			settings = append(settings, fmt.Sprintf("success=%g", slo.Success))
+		}
+		values = append(values, operation+"@"+strings.Join(settings, ";"))
+	}
+	return values
+}
+
+// flagValuer is implemented by options whose flag takes a list of encoded values
+type flagValuer interface {
+	flagValues() []string
+}
+
+// Load reads a configuration file, rejecting unknown options and values of the wrong type
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var config Config
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// ProfileNames returns the names of the profiles in order
+func (c *Config) ProfileNames() []string {
+	return sortedKeys(c.Profiles)
+}
+
+// Values returns the options of the file, keyed by flag name, with the profile of the
+// environment applied. A file with profiles must have one for the environment.
+func (c *Config) Values(environment string) (map[string]interface{}, error) {
+	values := c.Settings.values()
+	if len(c.Profiles) == 0 {
+		return values, nil
+	}
+	profile, ok := c.Profiles[environment]
+	if !ok {
+		return nil, fmt.Errorf("config has no profile for environment %q (profiles: %s)", environment, strings.Join(c.ProfileNames(), ", "))
+	}
+	for key, value := range profile.values() {
+		values[key] = value
+	}
+	return values, nil
+}
+
+// Validate checks every option of the file and its profiles, and returns all problems found
+func (c *Config) Validate() []error {
+	base, errs := c.Settings.principleRegistry("", validation.DefaultRegistry)
+	errs = append(errs, c.Settings.validate("", base)...)
+	for _, name := range c.ProfileNames() {
+		profile := c.Profiles[name]
+		prefix := "profiles." + name + "."
+		if profile.Validation != nil && profile.Validation.Environment != nil {
+			errs = append(errs, fmt.Errorf("%svalidation.environment: profiles are selected by environment and cannot set it", prefix))
+		}
+		registry, ruleErrs := profile.principleRegistry(prefix, base)
+		errs = append(errs, ruleErrs...)
+		errs = append(errs, profile.validate(prefix, registry)...)
+	}
+	return errs
+}
+
+// principleRegistry returns the principles available to settings: the core ones and
+// those of their rule files, or the inherited ones when they list no rule files
+func (s Settings) principleRegistry(prefix string, inherited *validation.PrincipleRegistry) (*validation.PrincipleRegistry, []error) {
+	if s.Validation == nil || len(s.Validation.Rules) == 0 {
+		return inherited, nil
+	}
+	var errs []error
+	registry := validation.DefaultRegistry.Clone()
+	for _, path := range s.Validation.Rules {
+		if err := registry.LoadRules(path); err != nil {
+			errs = append(errs, fmt.Errorf("%svalidation.rules: %w", prefix, err))
+		}
+	}
+	return registry, errs
+}
+
+// values returns the options that are set, keyed by flag name
+func (s Settings) values() map[string]interface{} {
+	values := make(map[string]interface{})
+	collectValues(reflect.ValueOf(s), values)
+	return values
+}
+
+// collectValues walks a settings struct, collecting the fields with a flag tag that are set
+func collectValues(v reflect.Value, values map[string]interface{}) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		flag := field.Tag.Get("flag")
+		if flag == "" {
+			if value.Kind() == reflect.Pointer && value.Elem().Kind() == reflect.Struct {
+				collectValues(value, values)
+			}
+			continue
+		}
+		if value.IsZero() {
+			continue
+		}
+		switch typed := value.Interface().(type) {
+		case flagValuer:
+			values[flag] = typed.flagValues()
+		default:
+			if value.Kind() == reflect.Pointer {
+				value = value.Elem()
+			}
+			values[flag] = value.Interface()
+		}
+	}
+}
+
+// validate checks the values of options against what the flags accept, and selected
+// principles against those of the registry
+func (s Settings) validate(prefix string, registry *validation.PrincipleRegistry) []error {
+	var errs []error
+	add := func(option string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", prefix, option, err))
+		}
+	}
+
+	if s.LogLevel != nil {
+		add("log_level", oneOf(*s.LogLevel, "debug", "info", "warn", "error", "fatal"))
+	}
+	if api := s.API; api != nil {
+		if api.Protocol != nil {
+			add("api.protocol", oneOf(*api.Protocol, "http", "https"))
+		}
+		if api.Timeout != nil && *api.Timeout <= 0 {
+			add("api.timeout", fmt.Errorf("must be greater than 0"))
+		}
+	}
+	if v := s.Validation; v != nil {
+		if v.Mode != nil {
+			add("validation.mode", oneOf(*v.Mode, string(validation.ValidationModeStrict), string(validation.ValidationModeMinimal),
+				string(validation.ValidationModeTestOnly), string(validation.ValidationModeFlexible)))
+		}
+		add("validation.principles", registry.CheckIDs(v.Principles))
+	}
+	if a := s.Auth; a != nil {
+		for _, value := range a.Schemes.flagValues() {
+			_, _, err := validation.ParseAuthScheme(value)
+			add("auth.schemes", err)
+		}
+		for _, value := range a.ScopeSets.flagValues() {
+			_, _, err := validation.ParseScopedCredential(value)
+			add("auth.scope_sets", err)
+		}
+		if o := a.OAuth2; o != nil {
+			if o.GrantType != nil {
+				add("auth.oauth2.grant_type", oneOf(*o.GrantType, validation.OAuth2GrantClientCredentials, validation.OAuth2GrantPassword))
+			}
+			if o.RefreshBefore != nil && *o.RefreshBefore < 0 {
+				add("auth.oauth2.refresh_before", fmt.Errorf("must not be negative"))
+			}
+		}
+	}
+	if p := s.Performance; p != nil {
+		if p.MinSuccessRate != nil && (*p.MinSuccessRate < 0 || *p.MinSuccessRate > 1) {
+			add("performance.min_success_rate", fmt.Errorf("must be between 0 and 1"))
+		}
+		for option, value := range map[string]*int{
+			"concurrent_users": p.ConcurrentUsers, "rate": p.Rate, "start_rate": p.StartRate, "steps": p.Steps, "max_probes": p.MaxProbes,
+		} {
+			if value != nil && *value < 0 {
+				add("performance."+option, fmt.Errorf("must not be negative"))
+			}
+		}
+		if p.Profile != nil {
+			add("performance.profile", oneOf(*p.Profile, string(validation.LoadProfileConstant), string(validation.LoadProfileRamp),
+				string(validation.LoadProfileStep), string(validation.LoadProfileSpike), string(validation.LoadProfileSoak),
+				string(validation.LoadProfileCapacity)))
+		}
+		if p.Model != nil {
+			add("performance.model", oneOf(*p.Model, string(validation.LoadModelOpen), string(validation.LoadModelClosed)))
+		}
+		for _, value := range p.Weights.flagValues() {
+			_, _, err := validation.ParseLoadWeight(value)
+			add("performance.weights", err)
+		}
+		for _, value := range p.Journeys.flagValues() {
+			_, err := validation.ParseJourney(value)
+			add("performance.journeys", err)
+		}
+		for _, value := range p.Endpoints.flagValues() {
+			_, _, err := validation.ParseEndpointTarget(value)
+			add("performance.endpoints", err)
+		}
+		if b := p.Baseline; b != nil {
+			for option, value := range map[string]*float64{"p95_tolerance": b.P95Tolerance, "throughput_tolerance": b.ThroughputTolerance} {
+				if value != nil && *value < 0 {
+					add("performance.baseline."+option, fmt.Errorf("must not be negative"))
+				}
+			}
+		}
+	}
+	if r := s.Reports; r != nil {
+		_, err := report.ParseFormats(r.Formats)
+		add("reports.formats", err)
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
+
+// oneOf checks that a value is one of the allowed values
+func oneOf(value string, allowed ...string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of %s", value, strings.Join(allowed, ", "))
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,343 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxExampleDepth bounds recursion through self-referencing schemas
+const maxExampleDepth = 8
+
+// ParameterExample returns a value for a parameter, preferring the documented
+// example, then named examples, then the schema's example/default, and finally a
+// value generated from the schema.
+func ParameterExample(param *openapi3.Parameter) interface{} {
+	if param == nil {
+		return nil
+	}
+	if param.Example != nil {
+		return param.Example
+	}
+	if value, ok := firstNamedExample(param.Examples); ok {
+		return value
+	}
+	if param.Schema != nil {
+		return SchemaExample(param.Schema.Value)
+	}
+	if _, mediaType := JSONMediaType(param.Content); mediaType != nil {
+		return MediaTypeExample(mediaType)
+	}
+	return nil
+}
+
+// MediaTypeExample returns a value for a request or response body, preferring the
+// documented example, then named examples, then a value generated from the schema.
+func MediaTypeExample(mediaType *openapi3.MediaType) interface{} {
+	if mediaType == nil {
+		return nil
+	}
+	if mediaType.Example != nil {
+		return mediaType.Example
+	}
+	if value, ok := firstNamedExample(mediaType.Examples); ok {
+		return value
+	}
+	if mediaType.Schema != nil {
+		return SchemaExample(mediaType.Schema.Value)
+	}
+	return nil
+}
+
+// SchemaExample generates a deterministic value that satisfies a schema. Resolved
+// $refs, allOf/oneOf/anyOf compositions, formats, simple patterns and numeric/length
+// constraints are honoured; example, default and enum values take precedence over
+// generation.
+func SchemaExample(schema *openapi3.Schema) interface{} {
+	return schemaExample(schema, 0, true)
+}
+
+// SchemaResponseExample is like SchemaExample but keeps readOnly properties and
+// drops writeOnly ones, matching what a server would return.
+func SchemaResponseExample(schema *openapi3.Schema) interface{} {
+	return schemaExample(schema, 0, false)
+}
+
+func schemaExample(schema *openapi3.Schema, depth int, asRequest bool) interface{} {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	if len(schema.AllOf) > 0 {
+		return allOfExample(schema, depth, asRequest)
+	}
+	if len(schema.OneOf) > 0 && schema.OneOf[0] != nil {
+		return schemaExample(schema.OneOf[0].Value, depth+1, asRequest)
+	}
+	if len(schema.AnyOf) > 0 && schema.AnyOf[0] != nil {
+		return schemaExample(schema.AnyOf[0].Value, depth+1, asRequest)
+	}
+
+	switch inferType(schema) {
+	case "string":
+		return stringExample(schema)
+	case "integer":
+		return int64(numberExample(schema, true))
+	case "number":
+		return numberExample(schema, false)
+	case "boolean":
+		return true
+	case "array":
+		return arrayExample(schema, depth, asRequest)
+	case "object":
+		return objectExample(schema, depth, asRequest)
+	}
+	return nil
+}
+
+// inferType returns the schema type, falling back to structural hints when it is omitted
+func inferType(schema *openapi3.Schema) string {
+	if schema.Type != "" {
+		return schema.Type
+	}
+	switch {
+	case len(schema.Properties) > 0 || schema.AdditionalProperties.Schema != nil:
+		return "object"
+	case schema.Items != nil:
+		return "array"
+	case schema.Format != "" || schema.Pattern != "" || schema.MinLength > 0 || schema.MaxLength != nil:
+		return "string"
+	case schema.Min != nil || schema.Max != nil || schema.MultipleOf != nil:
+		return "number"
+	}
+	return ""
+}
+
+// stringExample generates a string for a schema: a value of its format, or one generated
+// from its pattern when the format value does not match it. Only the plain placeholder is
+// padded or cut to the length limits, as cutting a date-time or UUID would make it
+// invalid. When no value matching the pattern can be generated, the placeholder is used
+// and callers can tell with MatchesPattern.
+func stringExample(schema *openapi3.Schema) string {
+	formatValue, formatted := formatExample(schema.Format)
+	if schema.Pattern != "" && !(formatted && MatchesPattern(schema.Pattern, formatValue)) {
+		if value, ok := patternExample(schema); ok {
+			return value
+		}
+		log.Debugf("[openapi] No value matching pattern %q could be generated", schema.Pattern)
+	}
+	if formatted {
+		return formatValue
+	}
+
+	value := "example"
+	minLength := int(schema.MinLength)
+	for len(value) < minLength {
+		value += "x"
+	}
+	if schema.MaxLength != nil && uint64(len(value)) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+	return value
+}
+
+// formatExample returns a valid value of a string format, or false for formats without one
+func formatExample(format string) (string, bool) {
+	switch format {
+	case "date":
+		return "2024-01-01", true
+	case "date-time":
+		return "2024-01-01T00:00:00Z", true
+	case "time":
+		return "00:00:00Z", true
+	case "email":
+		return "user@example.com", true
+	case "uuid":
+		return "123e4567-e89b-12d3-a456-426614174000", true
+	case "uri", "url":
+		return "https://example.com", true
+	case "hostname":
+		return "example.com", true
+	case "ipv4":
+		return "192.0.2.1", true
+	case "ipv6":
+		return "2001:db8::1", true
+	case "byte":
+		return "ZXhhbXBsZQ==", true
+	case "password":
+		return "Passw0rd!", true
+	}
+	return "", false
+}
+
+func numberExample(schema *openapi3.Schema, integer bool) float64 {
+	value := 1.0
+	if schema.Min != nil {
+		value = *schema.Min
+		if schema.ExclusiveMin {
+			if integer {
+				value = math.Floor(value) + 1
+			} else {
+				value += 0.5
+			}
+		}
+	}
+	if schema.Max != nil && value > *schema.Max {
+		value = *schema.Max
+		if schema.ExclusiveMax {
+			value--
+		}
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		value = math.Ceil(value / *schema.MultipleOf) * *schema.MultipleOf
+	}
+	if integer {
+		value = math.Ceil(value)
+	}
+	return value
+}
+
+func arrayExample(schema *openapi3.Schema, depth int, asRequest bool) []interface{} {
+	count := 1
+	if schema.MinItems > 1 {
+		count = int(schema.MinItems)
+	}
+	if schema.MaxItems != nil && uint64(count) > *schema.MaxItems {
+		count = int(*schema.MaxItems)
+	}
+	items := make([]interface{}, 0, count)
+	if schema.Items == nil {
+		return items
+	}
+	for i := 0; i < count; i++ {
+		items = append(items, schemaExample(schema.Items.Value, depth+1, asRequest))
+	}
+	return items
+}
+
+func objectExample(schema *openapi3.Schema, depth int, asRequest bool) map[string]interface{} {
+	obj := make(map[string]interface{}, len(schema.Properties))
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+		if prop == nil || prop.Value == nil {
+			continue
+		}
+		if asRequest && prop.Value.ReadOnly {
+			continue
+		}
+		if !asRequest && prop.Value.WriteOnly {
+			continue
+		}
+		obj[name] = schemaExample(prop.Value, depth+1, asRequest)
+	}
+	return obj
+}
+
+// allOfExample merges the generated values of all subschemas into a single object
+func allOfExample(schema *openapi3.Schema, depth int, asRequest bool) interface{} {
+	merged := make(map[string]interface{})
+	var scalar interface{}
+	for _, sub := range schema.AllOf {
+		if sub == nil {
+			continue
+		}
+		switch value := schemaExample(sub.Value, depth+1, asRequest).(type) {
+		case map[string]interface{}:
+			for k, v := range value {
+				merged[k] = v
+			}
+		case nil:
+		default:
+			scalar = value
+		}
+	}
+	if len(schema.Properties) > 0 {
+		for k, v := range objectExample(schema, depth, asRequest) {
+			merged[k] = v
+		}
+	}
+	if len(merged) == 0 && scalar != nil {
+		return scalar
+	}
+	return merged
+}
+
+// firstNamedExample returns the value of the alphabetically first named example
+func firstNamedExample(examples openapi3.Examples) (interface{}, bool) {
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ref := examples[name]; ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			return ref.Value.Value, true
+		}
+	}
+	return nil, false
+}
+
+// JSONMediaType returns the media type name and definition to use for a JSON body
+func JSONMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	if mediaType := content.Get("application/json"); mediaType != nil {
+		return "application/json", mediaType
+	}
+	names := make([]string, 0, len(content))
+	for name := range content {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.Contains(name, "json") {
+			return name, content[name]
+		}
+	}
+	if len(names) > 0 {
+		return names[0], content[names[0]]
+	}
+	return "", nil
+}
+
+// FormatParameterValue renders a scalar or array parameter value as a string
+func FormatParameterValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		if v == math.Trunc(v) {
+			return fmt.Sprintf("%.0f", v)
+		}
+		return fmt.Sprintf("%v", v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, FormatParameterValue(item))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func sortedKeys(schemas openapi3.Schemas) []string {
+	keys := make([]string, 0, len(schemas))
+	for k := range schemas {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,6 +1,7 @@
 package openapi
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,12 +11,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	stdlog "log"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/meter-peter/driveby/internal/util"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 )
 
 var log = logrus.New()
@@ -29,12 +32,23 @@ func init() {
 
 // Loader handles loading and validating OpenAPI specifications
 type Loader struct {
-	doc *openapi3.T
+	doc       *openapi3.T
+	sourceMap SourceMap // Positions of the elements of the spec loaded by LoadFromFileOrURL
 }
 
-// NewLoader creates a new OpenAPI loader
+// registerFormats guards the one-time registration of string formats
+var registerFormats sync.Once
+
+// NewLoader creates a new OpenAPI loader. The first loader registers the uuid and email
+// string formats with kin-openapi, which only ships byte, date and date-time, so that
+// validating values against a loaded spec reports format mismatches. kin-openapi keeps
+// formats in a process-wide registry, so the registration applies to every schema.
 func NewLoader() *Loader {
 	log.Debug("[openapi] Creating new OpenAPI Loader")
+	registerFormats.Do(func() {
+		openapi3.DefineStringFormat("uuid", openapi3.FormatOfStringForUUIDOfRFC4122)
+		openapi3.DefineStringFormat("email", openapi3.FormatOfStringForEmail)
+	})
 	return &Loader{}
 }
 
@@ -124,6 +138,10 @@ func (l *Loader) LoadFromFileOrURL(path string) error {
 		}
 		log.Debugf("[openapi] Read %d bytes from file", len(data))
 	}
+	// Positions are taken from the spec as written, before any preprocessing
+	if l.sourceMap, err = NewSourceMap(data); err != nil {
+		log.WithError(err).Warnf("[openapi] Findings in %s will not have source positions", path)
+	}
 	// Preprocess exclusiveMinimum/exclusiveMaximum
 	var raw map[string]interface{}
 	if err := json.Unmarshal(data, &raw); err == nil {
@@ -169,6 +187,11 @@ func (l *Loader) GetDocument() *openapi3.T {
 	return l.doc
 }
 
+// Locate returns the position of a JSON pointer in the loaded spec file
+func (l *Loader) Locate(pointer string) (Position, bool) {
+	return l.sourceMap.Locate(pointer)
+}
+
 // GetEndpoints returns a list of all endpoints in the specification
 func (l *Loader) GetEndpoints() []string {
 	log.Debug("[openapi] Enter GetEndpoints")
@@ -197,51 +220,16 @@ func (l *Loader) GetExampleValues(schema *openapi3.Schema) map[string]interface{
 		return nil
 	}
 
-	examples := make(map[string]interface{})
-
-	switch schema.Type {
-	case "string":
-		if schema.Enum != nil && len(schema.Enum) > 0 {
-			examples["value"] = schema.Enum[0]
-		} else if schema.Format == "date-time" {
-			examples["value"] = "2024-01-01T00:00:00Z"
-		} else if schema.Format == "date" {
-			examples["value"] = "2024-01-01"
-		} else if schema.Format == "email" {
-			examples["value"] = "example@example.com"
-		} else if schema.Format == "uuid" {
-			examples["value"] = "123e4567-e89b-12d3-a456-426614174000"
-		} else {
-			examples["value"] = "example string"
-		}
-	case "number", "integer":
-		examples["value"] = 42
-	case "boolean":
-		examples["value"] = true
-	case "array":
-		if schema.Items != nil {
-			examples["value"] = []interface{}{l.GetExampleValues(schema.Items.Value)}
-		} else {
-			examples["value"] = []interface{}{}
-		}
-	case "object":
-		if len(schema.Properties) > 0 {
-			obj := make(map[string]interface{})
-			for propName, propSchema := range schema.Properties {
-				log.Debugf("[openapi] Object property: %s", propName)
-				obj[propName] = l.GetExampleValues(propSchema.Value)["value"]
-			}
-			examples["value"] = obj
-		} else {
-			examples["value"] = map[string]interface{}{}
-		}
+	examples := map[string]interface{}{
+		"value": SchemaExample(schema),
 	}
 
 	log.Debugf("[openapi] Example values generated: %v", examples)
 	return examples
 }
 
-// SaveToFile saves the OpenAPI specification to a file
+// SaveToFile saves the OpenAPI specification to a file, as YAML if the path ends in
+// .yaml or .yml and as JSON otherwise
 func (l *Loader) SaveToFile(path string) error {
 	log.Debugf("[openapi] Enter SaveToFile with path: %s", path)
 	if l.doc == nil {
@@ -254,6 +242,13 @@ func (l *Loader) SaveToFile(path string) error {
 		log.WithError(err).Errorf("[openapi] Failed to marshal OpenAPI spec for saving: %s", path)
 		return fmt.Errorf("failed to marshal OpenAPI spec: %w", err)
 	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if data, err = jsonToYAML(data); err != nil {
+			log.WithError(err).Errorf("[openapi] Failed to convert OpenAPI spec to YAML for saving: %s", path)
+			return fmt.Errorf("failed to convert OpenAPI spec to YAML: %w", err)
+		}
+	}
 
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		log.WithError(err).Errorf("[openapi] Failed to create directory for saving: %s", path)
@@ -268,3 +263,30 @@ func (l *Loader) SaveToFile(path string) error {
 	log.Infof("[openapi] Successfully saved OpenAPI spec to file: %s", path)
 	return nil
 }
+
+// jsonToYAML re-encodes JSON as block-style YAML, keeping the key order
+func jsonToYAML(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	var unflow func(*yaml.Node)
+	unflow = func(n *yaml.Node) {
+		n.Style = 0
+		for _, child := range n.Content {
+			unflow(child)
+		}
+	}
+	unflow(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
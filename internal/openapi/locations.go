@@ -0,0 +1,80 @@
+package openapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a line and column in a spec file, both starting at 1
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// String formats a position as line:column
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// SourceMap maps the JSON pointers of a spec, such as "#/paths/~1tasks/get", to where
+// they are defined in the spec file. Object members are located at their key.
+type SourceMap map[string]Position
+
+// NewSourceMap maps the elements of a JSON or YAML spec to their positions
+func NewSourceMap(data []byte) (SourceMap, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse spec for source positions: %w", err)
+	}
+	sourceMap := make(SourceMap)
+	if len(root.Content) > 0 {
+		sourceMap.add("#", root.Content[0], Position{Line: 1, Column: 1})
+	}
+	return sourceMap, nil
+}
+
+// add records the position of a node and of its children
+func (m SourceMap) add(pointer string, node *yaml.Node, position Position) {
+	m[pointer] = position
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			m.add(pointer+"/"+escapePointerToken(key.Value), value, Position{Line: key.Line, Column: key.Column})
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			m.add(pointer+"/"+strconv.Itoa(i), item, Position{Line: item.Line, Column: item.Column})
+		}
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			m.add(pointer, node.Alias, position)
+		}
+	}
+}
+
+// Locate returns the position of a pointer. Pointers to elements that are not in the
+// spec, such as a missing description, are located at their closest existing parent.
+func (m SourceMap) Locate(pointer string) (Position, bool) {
+	if len(m) == 0 || !strings.HasPrefix(pointer, "#") {
+		return Position{}, false
+	}
+	for {
+		if position, ok := m[pointer]; ok {
+			return position, true
+		}
+		i := strings.LastIndex(pointer, "/")
+		if i < 0 {
+			return Position{}, false
+		}
+		pointer = pointer[:i]
+	}
+}
+
+// escapePointerToken escapes a key for use in a JSON pointer
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
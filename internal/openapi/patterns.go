@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// MatchesPattern reports whether a value matches a schema pattern. Like JSON Schema
+// patterns, it is not implicitly anchored. Patterns Go cannot compile match nothing.
+func MatchesPattern(pattern, value string) bool {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+// patternExample generates a string matching the schema's pattern within its length
+// limits. Alternations take their first branch, classes their first letter or digit and
+// repetitions their minimum count, repeated more when minLength asks for a longer value.
+// It returns false for patterns Go cannot parse, such as those with lookarounds, and
+// when the generated value does not fit.
+func patternExample(schema *openapi3.Schema) (string, bool) {
+	re, err := syntax.Parse(schema.Pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	re = re.Simplify()
+
+	value, ok := generatePattern(re, 0)
+	if ok && uint64(len(value)) < schema.MinLength {
+		// Each unbounded repetition adds at least a character per extra round
+		value, ok = generatePattern(re, int(schema.MinLength)-len(value))
+	}
+	if !ok || uint64(len(value)) < schema.MinLength || (schema.MaxLength != nil && uint64(len(value)) > *schema.MaxLength) {
+		return "", false
+	}
+	return value, MatchesPattern(schema.Pattern, value)
+}
+
+// generatePattern builds a string matching a parsed pattern, repeating unbounded
+// repetitions extra times beyond their minimum
+func generatePattern(re *syntax.Regexp, extra int) (string, bool) {
+	var b strings.Builder
+	if !writePattern(&b, re, extra) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+func writePattern(b *strings.Builder, re *syntax.Regexp, extra int) bool {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+		return true
+	case syntax.OpCharClass:
+		r, ok := classRune(re.Rune)
+		if ok {
+			b.WriteRune(r)
+		}
+		return ok
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune('x')
+		return true
+	case syntax.OpCapture:
+		return writePattern(b, re.Sub[0], extra)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writePattern(b, sub, extra) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		return writePattern(b, re.Sub[0], extra)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		count := 0
+		switch re.Op {
+		case syntax.OpStar:
+			count = extra
+		case syntax.OpPlus:
+			count = 1 + extra
+		case syntax.OpRepeat:
+			count = re.Min
+			if re.Max == -1 {
+				count += extra
+			}
+		}
+		for i := 0; i < count; i++ {
+			if !writePattern(b, re.Sub[0], extra) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// classRune picks a readable character from a character class, given as pairs of rune
+// ranges: an ASCII letter or digit when the class has one, otherwise another printable
+// character
+func classRune(ranges []rune) (rune, bool) {
+	var printable rune = -1
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r < unicode.MaxASCII; r++ {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r, true
+			}
+			if printable == -1 && unicode.IsPrint(r) && r != ' ' {
+				printable = r
+			}
+		}
+	}
+	if printable != -1 {
+		return printable, true
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r < ranges[i]+256; r++ {
+			if unicode.IsPrint(r) && r != ' ' {
+				return r, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestPatternExample(t *testing.T) {
+	maxLength := func(n uint64) *uint64 { return &n }
+	tests := []struct {
+		name   string
+		schema *openapi3.Schema
+		want   string
+		ok     bool
+	}{
+		{name: "literal", schema: &openapi3.Schema{Pattern: "^abc$"}, want: "abc", ok: true},
+		{name: "classes and counts", schema: &openapi3.Schema{Pattern: `^[A-Z]{3}-\d{4}$`}, want: "AAA-0000", ok: true},
+		{name: "alternation takes the first branch", schema: &openapi3.Schema{Pattern: "^(draft|published)$"}, want: "draft", ok: true},
+		{name: "optional part is left out", schema: &openapi3.Schema{Pattern: `^v\d+(\.\d+)?$`}, want: "v0", ok: true},
+		{name: "unanchored", schema: &openapi3.Schema{Pattern: "[a-z]+"}, want: "a", ok: true},
+		{name: "negated class", schema: &openapi3.Schema{Pattern: "^[^0-9]$"}, want: "A", ok: true},
+		{name: "minLength repeats unbounded parts", schema: &openapi3.Schema{Pattern: "^[a-z]+$", MinLength: 4}, want: "aaaa", ok: true},
+		{name: "maxLength too short", schema: &openapi3.Schema{Pattern: `^\d{6}$`, MaxLength: maxLength(5)}},
+		{name: "minLength not reachable", schema: &openapi3.Schema{Pattern: `^\d{2}$`, MinLength: 3}},
+		{name: "lookahead", schema: &openapi3.Schema{Pattern: "^(?=.*[A-Z]).+$"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := patternExample(tt.schema)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("patternExample(%q) = %q, %v, want %q, %v", tt.schema.Pattern, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestStringExample(t *testing.T) {
+	maxLength := uint64(4)
+	tests := []struct {
+		name   string
+		schema *openapi3.Schema
+		want   string
+	}{
+		{name: "placeholder", schema: &openapi3.Schema{}, want: "example"},
+		{name: "placeholder padded", schema: &openapi3.Schema{MinLength: 9}, want: "examplexx"},
+		{name: "placeholder cut", schema: &openapi3.Schema{MaxLength: &maxLength}, want: "exam"},
+		{name: "format is not cut", schema: &openapi3.Schema{Format: "date", MaxLength: &maxLength}, want: "2024-01-01"},
+		{name: "format matching the pattern", schema: &openapi3.Schema{Format: "date", Pattern: `^\d{4}-\d{2}-\d{2}$`}, want: "2024-01-01"},
+		{name: "pattern over format", schema: &openapi3.Schema{Format: "email", Pattern: `^[a-z]+@corp\.example$`}, want: "a@corp.example"},
+		{name: "ungeneratable pattern", schema: &openapi3.Schema{Pattern: "^(?!x).+$"}, want: "example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stringExample(tt.schema); got != tt.want {
+				t.Errorf("stringExample() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
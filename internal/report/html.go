@@ -0,0 +1,682 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/meter-peter/driveby/internal/validation"
+)
+
+// htmlReport is the view of a validation report rendered by the HTML template
+type htmlReport struct {
+	Report     *validation.ValidationReport
+	Generated  string
+	Tiles      []htmlTile
+	Principles []htmlPrinciple
+	Endpoints  []htmlEndpoint
+	Load       *validation.PerformanceMetrics
+	Charts     []svgChart
+	Fuzz       *validation.FuzzTestResults
+}
+
+// htmlTile is a figure of the summary dashboard
+type htmlTile struct {
+	Label  string
+	Value  string
+	Detail string
+	Status string // CSS class: passed, failed, warning or skipped
+}
+
+// htmlPrinciple is a principle card
+type htmlPrinciple struct {
+	validation.PrincipleResult
+	Status   string
+	Checks   []htmlCheck
+	Findings []validation.Finding
+	Impact   []string
+}
+
+// htmlCheck is a check of a principle and its outcome, when known
+type htmlCheck struct {
+	Name   string
+	Status string // passed, failed, or empty when the principle does not report it
+}
+
+// htmlEndpoint is a functionally tested endpoint
+type htmlEndpoint struct {
+	validation.EndpointTestResult
+	Anchor  string
+	Passed  int
+	Failed  int
+	Skipped int
+}
+
+// svgChart is a chart drawn as inline SVG, so that charts render without chart libraries
+// or external assets; the only script in the report is the inline table sorting
+type svgChart struct {
+	Title  string
+	Width  int
+	Height int
+	Bars   []svgBar
+	Series []svgSeries
+	Texts  []svgText
+	Lines  []svgLine
+}
+
+type svgBar struct {
+	X, Y, Width, Height float64
+	Class               string
+	Title               string
+}
+
+type svgSeries struct {
+	Class  string
+	Points string
+	Dots   []svgDot
+}
+
+type svgDot struct {
+	X, Y  float64
+	Title string
+}
+
+type svgText struct {
+	X, Y   float64
+	Text   string
+	Anchor string
+}
+
+type svgLine struct {
+	X1, Y1, X2, Y2 float64
+}
+
+// Chart layout in pixels
+const (
+	chartWidth     = 760
+	chartLabelLeft = 230 // Width of the operation labels of bar charts
+	chartMargin    = 50
+	chartBarHeight = 9
+	chartRowGap    = 14
+	chartHeight    = 260
+)
+
+// latencyClasses are the CSS classes of the P50, P95 and P99 series
+var latencyClasses = []string{"p50", "p95", "p99"}
+
+var htmlFuncs = template.FuncMap{
+	"ms": func(d time.Duration) string {
+		return fmt.Sprintf("%.2f ms", float64(d)/float64(time.Millisecond))
+	},
+	"msValue": func(d time.Duration) string {
+		return fmt.Sprintf("%.3f", float64(d)/float64(time.Millisecond))
+	},
+	"percent": func(f float64) string {
+		return fmt.Sprintf("%.2f%%", f*100)
+	},
+	"lower": func(value interface{}) string {
+		return strings.ToLower(fmt.Sprint(value))
+	},
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(htmlFuncs).Parse(htmlReportTemplate))
+
+// saveHTML saves a validation report as a single HTML file that works offline
+func (g *Generator) saveHTML(path string, result *validation.ValidationReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+	return g.writeValidationHTML(file, result)
+}
+
+// writeValidationHTML writes a validation report as HTML with a summary dashboard,
+// principle cards, sortable endpoint tables and latency charts
+func (g *Generator) writeValidationHTML(file *os.File, report *validation.ValidationReport) error {
+	view := htmlReport{
+		Report:    report,
+		Generated: report.Timestamp.Format(time.RFC3339),
+		Load:      loadTestMetrics(report),
+	}
+	for _, principle := range report.Principles {
+		view.Principles = append(view.Principles, newHTMLPrinciple(principle))
+	}
+	if tests := report.TestResults; tests != nil {
+		if tests.Functional != nil {
+			for i, endpoint := range tests.Functional.EndpointResults {
+				view.Endpoints = append(view.Endpoints, newHTMLEndpoint(i, endpoint))
+			}
+		}
+		view.Fuzz = tests.Fuzz
+	}
+	if view.Load != nil {
+		view.Charts = latencyCharts(view.Load)
+	}
+	view.Tiles = summaryTiles(report, view.Load)
+
+	if err := htmlTemplate.Execute(file, view); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
+
+// loadTestMetrics returns the load test metrics of a report, if it ran a load test
+func loadTestMetrics(report *validation.ValidationReport) *validation.PerformanceMetrics {
+	for _, principle := range report.Principles {
+		if principle.Principle.ID == "P007" {
+			if details, ok := principle.Details.(*validation.PerformanceMetrics); ok {
+				return details
+			}
+		}
+	}
+	return nil
+}
+
+// summaryTiles lists the headline figures of a report
+func summaryTiles(report *validation.ValidationReport, load *validation.PerformanceMetrics) []htmlTile {
+	checks := htmlTile{Label: "Checks passed", Value: fmt.Sprintf("%d/%d", report.PassedChecks, report.TotalChecks), Status: "passed"}
+	if report.TotalChecks > 0 {
+		checks.Detail = fmt.Sprintf("%.0f%% pass rate", float64(report.PassedChecks)/float64(report.TotalChecks)*100)
+	}
+	if report.FailedChecks > 0 {
+		checks.Status = "failed"
+	}
+	critical := htmlTile{Label: "Critical issues", Value: fmt.Sprint(report.Summary.CriticalIssues), Status: "passed"}
+	if report.Summary.CriticalIssues > 0 {
+		critical.Status = "failed"
+	}
+	warnings := htmlTile{Label: "Warnings", Value: fmt.Sprint(report.Summary.Warnings), Status: "passed"}
+	if report.Summary.Warnings > 0 {
+		warnings.Status = "warning"
+	}
+	tiles := []htmlTile{checks, critical, warnings}
+
+	if tests := report.TestResults; tests != nil && tests.Functional != nil {
+		functional := tests.Functional
+		tile := htmlTile{
+			Label:  "Endpoints passed",
+			Value:  fmt.Sprintf("%d/%d", functional.PassedEndpoints, functional.TestedEndpoints),
+			Detail: fmt.Sprintf("average response %.2f ms", float64(functional.AverageResponseTime)/float64(time.Millisecond)),
+			Status: "passed",
+		}
+		if functional.FailedEndpoints > 0 {
+			tile.Status = "failed"
+		}
+		tiles = append(tiles, tile)
+	}
+	if load != nil {
+		tile := htmlTile{
+			Label:  "P95 latency",
+			Value:  fmt.Sprintf("%.2f ms", float64(load.LatencyP95)/float64(time.Millisecond)),
+			Detail: fmt.Sprintf("%.1f req/s, %.2f%% errors", load.RequestsPerSec, load.ErrorRate*100),
+			Status: "passed",
+		}
+		for _, principle := range report.Principles {
+			if principle.Principle.ID == "P007" && !principle.Passed {
+				tile.Status = "failed"
+			}
+		}
+		tiles = append(tiles, tile)
+	}
+	if tests := report.TestResults; tests != nil && tests.Fuzz != nil {
+		tile := htmlTile{
+			Label:  "Fuzz failures",
+			Value:  fmt.Sprint(len(tests.Fuzz.Failures)),
+			Detail: fmt.Sprintf("%d iterations, seed %d", tests.Fuzz.Iterations, tests.Fuzz.Seed),
+			Status: "passed",
+		}
+		if len(tests.Fuzz.Failures) > 0 {
+			tile.Status = "failed"
+		}
+		tiles = append(tiles, tile)
+	}
+	return tiles
+}
+
+// newHTMLPrinciple builds a principle card, with the outcome of each check when the
+// principle's details report it
+func newHTMLPrinciple(result validation.PrincipleResult) htmlPrinciple {
+	card := htmlPrinciple{PrincipleResult: result, Status: "passed"}
+	if !result.Passed {
+		card.Status = "failed"
+		card.Findings = result.Findings
+		if len(card.Findings) == 0 {
+			card.Findings = validation.PrincipleFindings(result)
+		}
+	}
+
+	failed := make(map[string]bool)
+	itemized := false
+	for _, finding := range card.Findings {
+		if finding.Check != "" {
+			failed[finding.Check] = true
+			itemized = true
+		}
+	}
+	reported := detailChecks(result.Details)
+	for _, check := range result.Principle.Checks {
+		status := ""
+		if passed, ok := reported[check]; ok {
+			status = "failed"
+			if passed {
+				status = "passed"
+			}
+		} else if failed[check] {
+			status = "failed"
+		} else if result.Passed || itemized {
+			status = "passed"
+		}
+		card.Checks = append(card.Checks, htmlCheck{Name: check, Status: status})
+	}
+
+	if impact := result.TestImpact; impact != nil {
+		for _, operation := range impact.Operations {
+			var effects []string
+			if len(operation.Skipped) > 0 {
+				effects = append(effects, "skips "+strings.Join(operation.Skipped, ", ")+" tests")
+			}
+			if len(operation.Downgraded) > 0 {
+				effects = append(effects, "downgrades "+strings.Join(operation.Downgraded, ", ")+" failures to warnings")
+			}
+			card.Impact = append(card.Impact, fmt.Sprintf("%s: %s because %s", operation.Operation, strings.Join(effects, " and "), operation.Reason))
+		}
+	}
+	return card
+}
+
+// detailChecks returns the per-check outcomes some principles put in their details
+func detailChecks(details interface{}) map[string]bool {
+	values, ok := details.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	checks, _ := values["checks"].(map[string]bool)
+	return checks
+}
+
+// newHTMLEndpoint counts the outcomes of an endpoint's test cases
+func newHTMLEndpoint(index int, result validation.EndpointTestResult) htmlEndpoint {
+	endpoint := htmlEndpoint{EndpointTestResult: result, Anchor: fmt.Sprintf("endpoint-%d", index)}
+	for _, testCase := range result.TestCases {
+		switch testCase.Status {
+		case validation.TestStatusFailed:
+			endpoint.Failed++
+		case validation.TestStatusSkipped:
+			endpoint.Skipped++
+		default:
+			endpoint.Passed++
+		}
+	}
+	return endpoint
+}
+
+// latencyCharts draws the latency of each operation, and over the stages or capacity
+// probes of the load test when there are some
+func latencyCharts(metrics *validation.PerformanceMetrics) []svgChart {
+	var charts []svgChart
+	if len(metrics.Endpoints) > 0 {
+		charts = append(charts, endpointLatencyChart(metrics.Endpoints))
+	}
+	if len(metrics.Stages) > 1 {
+		var labels []string
+		var latencies [][3]time.Duration
+		for _, stage := range metrics.Stages {
+			labels = append(labels, stage.Name)
+			latencies = append(latencies, [3]time.Duration{stage.LatencyP50, stage.LatencyP95, stage.LatencyP99})
+		}
+		charts = append(charts, latencyLineChart("Latency by stage", labels, latencies))
+	}
+	if metrics.Capacity != nil && len(metrics.Capacity.Curve) > 1 {
+		var labels []string
+		var latencies [][3]time.Duration
+		for _, probe := range metrics.Capacity.Curve {
+			labels = append(labels, fmt.Sprintf("%d/s", probe.Rate))
+			latencies = append(latencies, [3]time.Duration{probe.LatencyP50, probe.LatencyP95, probe.LatencyP99})
+		}
+		charts = append(charts, latencyLineChart("Latency by request rate", labels, latencies))
+	}
+	return charts
+}
+
+// endpointLatencyChart draws a group of P50, P95 and P99 bars per operation
+func endpointLatencyChart(endpoints []validation.EndpointMetrics) svgChart {
+	rowHeight := 3*chartBarHeight + chartRowGap
+	chart := svgChart{
+		Title:  "Latency by operation",
+		Width:  chartWidth,
+		Height: len(endpoints)*rowHeight + 30,
+	}
+	var max time.Duration
+	for _, endpoint := range endpoints {
+		if endpoint.LatencyP99 > max {
+			max = endpoint.LatencyP99
+		}
+	}
+	scale := float64(chartWidth-chartLabelLeft-chartMargin) / math.Max(float64(max), 1)
+	for i, endpoint := range endpoints {
+		top := float64(i*rowHeight + 10)
+		chart.Texts = append(chart.Texts, svgText{
+			X:      chartLabelLeft - 8,
+			Y:      top + 1.5*chartBarHeight + 4,
+			Text:   endpoint.Method + " " + endpoint.Path,
+			Anchor: "end",
+		})
+		for j, latency := range []time.Duration{endpoint.LatencyP50, endpoint.LatencyP95, endpoint.LatencyP99} {
+			chart.Bars = append(chart.Bars, svgBar{
+				X:      chartLabelLeft,
+				Y:      top + float64(j*chartBarHeight),
+				Width:  round1(math.Max(float64(latency)*scale, 1)),
+				Height: chartBarHeight - 1,
+				Class:  latencyClasses[j],
+				Title:  fmt.Sprintf("%s %s %s: %s", endpoint.Method, endpoint.Path, strings.ToUpper(latencyClasses[j]), latency),
+			})
+		}
+	}
+	bottom := float64(chart.Height - 12)
+	chart.Lines = append(chart.Lines, svgLine{X1: chartLabelLeft, Y1: 4, X2: chartLabelLeft, Y2: bottom - 10})
+	chart.Texts = append(chart.Texts,
+		svgText{X: chartLabelLeft, Y: bottom, Text: "0 ms", Anchor: "middle"},
+		svgText{X: chartWidth - chartMargin, Y: bottom, Text: fmt.Sprintf("%.2f ms", float64(max)/float64(time.Millisecond)), Anchor: "middle"},
+	)
+	return chart
+}
+
+// latencyLineChart draws P50, P95 and P99 lines over a sequence of points
+func latencyLineChart(title string, labels []string, latencies [][3]time.Duration) svgChart {
+	chart := svgChart{Title: title, Width: chartWidth, Height: chartHeight}
+	left, right := float64(chartMargin+20), float64(chartWidth-chartMargin)
+	top, bottom := 16.0, float64(chartHeight-40)
+
+	var max time.Duration
+	for _, point := range latencies {
+		if point[2] > max {
+			max = point[2]
+		}
+	}
+	yScale := (bottom - top) / math.Max(float64(max)*1.1, 1)
+	step := (right - left) / float64(len(labels)-1)
+
+	for series := range latencyClasses {
+		line := svgSeries{Class: latencyClasses[series]}
+		var points []string
+		for i, point := range latencies {
+			x := round1(left + float64(i)*step)
+			y := round1(bottom - float64(point[series])*yScale)
+			points = append(points, fmt.Sprintf("%g,%g", x, y))
+			line.Dots = append(line.Dots, svgDot{X: x, Y: y, Title: fmt.Sprintf("%s %s: %s", labels[i], strings.ToUpper(latencyClasses[series]), point[series])})
+		}
+		line.Points = strings.Join(points, " ")
+		chart.Series = append(chart.Series, line)
+	}
+
+	chart.Lines = append(chart.Lines,
+		svgLine{X1: left, Y1: bottom, X2: right, Y2: bottom},
+		svgLine{X1: left, Y1: top, X2: left, Y2: bottom},
+	)
+	for _, fraction := range []float64{0, 0.5, 1} {
+		value := float64(max) * 1.1 * fraction
+		chart.Texts = append(chart.Texts, svgText{
+			X:      left - 6,
+			Y:      round1(bottom - value*yScale + 4),
+			Text:   fmt.Sprintf("%.1f ms", value/float64(time.Millisecond)),
+			Anchor: "end",
+		})
+	}
+	for i, label := range labels {
+		chart.Texts = append(chart.Texts, svgText{X: round1(left + float64(i)*step), Y: bottom + 18, Text: label, Anchor: "middle"})
+	}
+	return chart
+}
+
+// round1 rounds a chart coordinate to a tenth of a pixel
+func round1(value float64) float64 {
+	return math.Round(value*10) / 10
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>API Validation Report - {{.Report.Environment}} {{.Report.Version}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; background: #f5f6f8; color: #1f2328; }
+header { background: #24292f; color: #fff; padding: 20px 32px; }
+header h1 { margin: 0 0 4px; font-size: 22px; }
+header p { margin: 0; color: #c9d1d9; }
+main { padding: 24px 32px; max-width: 1200px; }
+h2 { font-size: 18px; margin: 32px 0 12px; }
+.tiles { display: flex; flex-wrap: wrap; gap: 12px; }
+.tile { background: #fff; border-radius: 6px; padding: 14px 18px; min-width: 160px; border-top: 4px solid #8c959f; box-shadow: 0 1px 2px rgba(0,0,0,.08); }
+.tile .value { font-size: 26px; font-weight: 600; }
+.tile .label { font-size: 13px; color: #57606a; }
+.tile .detail { font-size: 12px; color: #57606a; margin-top: 4px; }
+.tile.passed { border-color: #1a7f37; } .tile.failed { border-color: #cf222e; } .tile.warning { border-color: #bf8700; }
+details.card { background: #fff; border-radius: 6px; margin-bottom: 8px; box-shadow: 0 1px 2px rgba(0,0,0,.08); }
+details.card > summary { cursor: pointer; padding: 12px 16px; font-weight: 600; list-style: none; }
+details.card > summary::-webkit-details-marker { display: none; }
+details.card > summary::before { content: "\25B8"; display: inline-block; width: 16px; color: #57606a; }
+details.card[open] > summary::before { content: "\25BE"; }
+details.card .body { padding: 0 16px 14px 32px; font-size: 14px; }
+.badge { display: inline-block; font-size: 12px; font-weight: 600; padding: 1px 8px; border-radius: 10px; margin-left: 6px; background: #eaeef2; color: #57606a; }
+.badge.passed { background: #dafbe1; color: #1a7f37; } .badge.failed { background: #ffebe9; color: #cf222e; }
+.badge.warning { background: #fff8c5; color: #9a6700; } .badge.skipped, .badge.incomplete { background: #eaeef2; color: #57606a; }
+.badge.critical { background: #cf222e; color: #fff; }
+ul.checks { list-style: none; padding: 0; }
+ul.checks li::before { display: inline-block; width: 18px; font-weight: 700; content: "\2022"; color: #8c959f; }
+ul.checks li.passed::before { content: "\2713"; color: #1a7f37; }
+ul.checks li.failed::before { content: "\2717"; color: #cf222e; }
+code { font-size: 12px; background: #eaeef2; padding: 1px 4px; border-radius: 4px; }
+table { border-collapse: collapse; width: 100%; background: #fff; font-size: 13px; box-shadow: 0 1px 2px rgba(0,0,0,.08); }
+th, td { text-align: left; padding: 7px 10px; border-bottom: 1px solid #d0d7de; }
+th { background: #eaeef2; }
+th.sortable { cursor: pointer; user-select: none; }
+th.sortable::after { content: " \2195"; color: #8c959f; }
+th.asc::after { content: " \2191"; } th.desc::after { content: " \2193"; }
+td.num, th.num { text-align: right; }
+.chart { background: #fff; border-radius: 6px; padding: 12px 16px; margin-bottom: 12px; box-shadow: 0 1px 2px rgba(0,0,0,.08); }
+.chart h3 { margin: 0 0 8px; font-size: 15px; }
+.chart svg { width: 100%; height: auto; font-size: 11px; }
+.chart text { fill: #57606a; }
+.chart line { stroke: #8c959f; }
+.chart polyline { fill: none; stroke-width: 2; }
+rect.p50, circle.p50, .legend .p50 { fill: #54aeff; background: #54aeff; } polyline.p50 { stroke: #54aeff; }
+rect.p95, circle.p95, .legend .p95 { fill: #bf8700; background: #bf8700; } polyline.p95 { stroke: #bf8700; }
+rect.p99, circle.p99, .legend .p99 { fill: #cf222e; background: #cf222e; } polyline.p99 { stroke: #cf222e; }
+.legend { font-size: 12px; color: #57606a; }
+.legend span { display: inline-block; width: 10px; height: 10px; margin: 0 4px 0 12px; border-radius: 2px; }
+.muted { color: #57606a; }
+</style>
+</head>
+<body>
+<header>
+<h1>API Validation Report</h1>
+<p>Environment {{.Report.Environment}} &middot; Version {{.Report.Version}} &middot; Generated {{.Generated}}</p>
+</header>
+<main>
+<section>
+<h2>Summary</h2>
+<div class="tiles">
+{{- range .Tiles}}
+<div class="tile {{.Status}}"><div class="value">{{.Value}}</div><div class="label">{{.Label}}</div>{{if .Detail}}<div class="detail">{{.Detail}}</div>{{end}}</div>
+{{- end}}
+</div>
+{{- with .Report.Summary.FailedTags}}
+<p class="muted">Failed tags: {{range $i, $tag := .}}{{if $i}}, {{end}}<code>{{$tag}}</code>{{end}}</p>
+{{- end}}
+</section>
+
+<section>
+<h2>Principles</h2>
+{{- range .Principles}}
+<details class="card"{{if not .Passed}} open{{end}}>
+<summary>{{.Principle.ID}} {{.Principle.Name}} <span class="badge {{.Status}}">{{.Status}}</span><span class="badge{{if eq .Principle.Severity "critical"}} critical{{end}}">{{.Principle.Severity}}</span> <span class="muted">{{.Principle.Category}}</span></summary>
+<div class="body">
+<p>{{.Principle.Description}}</p>
+<p><strong>Result:</strong> {{.Message}}</p>
+{{- if .Checks}}
+<p><strong>Checks</strong></p>
+<ul class="checks">
+{{- range .Checks}}
+<li class="{{.Status}}">{{.Name}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Findings}}
+<p><strong>Findings</strong></p>
+<ul>
+{{- range .Findings}}
+<li>{{.Message}}{{with .Location}} <span class="muted">line {{.}}</span>{{end}}{{if .Pointer}} <code>{{.Pointer}}</code>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Impact}}
+<p><strong>Test impact</strong></p>
+<ul>
+{{- range .Impact}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if and (not .Passed) .Explanation}}
+<p><strong>Why it matters:</strong> {{.Explanation}}</p>
+{{- end}}
+{{- if and (not .Passed) .SuggestedFix}}
+<p><strong>Suggested fix:</strong> {{.SuggestedFix}}</p>
+{{- end}}
+{{- with .Principle.Tags}}
+<p class="muted">Tags: {{range $i, $tag := .}}{{if $i}}, {{end}}{{$tag}}{{end}}</p>
+{{- end}}
+</div>
+</details>
+{{- end}}
+</section>
+{{- if .Endpoints}}
+
+<section>
+<h2>Functional Tests</h2>
+<table class="sortable">
+<thead><tr><th class="sortable">Method</th><th class="sortable">Path</th><th class="sortable">Status</th><th class="sortable num">Status Code</th><th class="sortable num">Response Time (ms)</th><th class="sortable num">Test Cases Passed</th><th class="sortable num">Failed</th><th class="sortable num">Skipped</th></tr></thead>
+<tbody>
+{{- range .Endpoints}}
+<tr><td>{{.Method}}</td><td><a href="#{{.Anchor}}">{{.Path}}</a></td><td><span class="badge {{lower .Status}}">{{.Status}}</span></td><td class="num">{{.StatusCode}}</td><td class="num" data-value="{{msValue .ResponseTime}}">{{ms .ResponseTime}}</td><td class="num">{{.Passed}}</td><td class="num">{{.Failed}}</td><td class="num">{{.Skipped}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+<h3>Endpoint Details</h3>
+{{- range .Endpoints}}
+<details class="card" id="{{.Anchor}}"{{if eq (lower .Status) "failed"}} open{{end}}>
+<summary>{{.Method}} {{.Path}} <span class="badge {{lower .Status}}">{{.Status}}</span></summary>
+<div class="body">
+{{- with .Errors}}<p><strong>Errors</strong></p><ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{- with .Warnings}}<p><strong>Warnings</strong></p><ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{- with .Notes}}<p><strong>Notes</strong></p><ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{- if .TestCases}}
+<table>
+<thead><tr><th>Test Case</th><th>Status</th><th>Description</th><th>Error</th></tr></thead>
+<tbody>
+{{- range .TestCases}}
+<tr><td>{{.Name}}</td><td><span class="badge {{lower .Status}}">{{.Status}}</span></td><td>{{.Description}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+</div>
+</details>
+{{- end}}
+</section>
+{{- end}}
+{{- with .Load}}
+
+<section>
+<h2>Load Test</h2>
+<p class="muted">{{.TotalRequests}} requests &middot; {{printf "%.2f" .RequestsPerSec}} req/s &middot; {{percent .ErrorRate}} errors &middot; P50 {{ms .LatencyP50}} &middot; P95 {{ms .LatencyP95}} &middot; P99 {{ms .LatencyP99}}{{if .LoadProfile}} &middot; {{.LoadProfile}} profile{{end}}</p>
+{{- range $.Charts}}
+<div class="chart">
+<h3>{{.Title}}</h3>
+<svg viewBox="0 0 {{.Width}} {{.Height}}" role="img" aria-label="{{.Title}}">
+{{- range .Lines}}
+<line x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}"/>
+{{- end}}
+{{- range .Bars}}
+<rect class="{{.Class}}" x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"><title>{{.Title}}</title></rect>
+{{- end}}
+{{- range .Series}}
+<polyline class="{{.Class}}" points="{{.Points}}"/>
+{{- $class := .Class}}
+{{- range .Dots}}
+<circle class="{{$class}}" cx="{{.X}}" cy="{{.Y}}" r="3"><title>{{.Title}}</title></circle>
+{{- end}}
+{{- end}}
+{{- range .Texts}}
+<text x="{{.X}}" y="{{.Y}}" text-anchor="{{.Anchor}}">{{.Text}}</text>
+{{- end}}
+</svg>
+<div class="legend"><span class="p50"></span>P50<span class="p95"></span>P95<span class="p99"></span>P99</div>
+</div>
+{{- end}}
+{{- if .Endpoints}}
+<table class="sortable">
+<thead><tr><th class="sortable">Method</th><th class="sortable">Path</th><th class="sortable num">Requests</th><th class="sortable num">Error Rate</th><th class="sortable num">P50 (ms)</th><th class="sortable num">P95 (ms)</th><th class="sortable num">P99 (ms)</th><th class="sortable num">Req/s</th><th class="sortable">SLO</th></tr></thead>
+<tbody>
+{{- range .Endpoints}}
+<tr><td>{{.Method}}</td><td>{{.Path}}</td><td class="num">{{.TotalRequests}}</td><td class="num" data-value="{{.ErrorRate}}">{{percent .ErrorRate}}</td><td class="num" data-value="{{msValue .LatencyP50}}">{{ms .LatencyP50}}</td><td class="num" data-value="{{msValue .LatencyP95}}">{{ms .LatencyP95}}</td><td class="num" data-value="{{msValue .LatencyP99}}">{{ms .LatencyP99}}</td><td class="num">{{printf "%.2f" .RequestsPerSec}}</td><td>{{if .Passed}}<span class="badge passed">met</span>{{else}}<span class="badge failed" title="{{range .Failures}}{{.}}&#10;{{end}}">missed</span>{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- with .Baseline}}
+<p><strong>Baseline:</strong> {{if .Skipped}}{{.Skipped}}{{else}}compared with version {{.Version}} recorded {{.RecordedAt.Format "2006-01-02 15:04"}}{{end}}</p>
+{{- with .Regressions}}<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{- end}}
+</section>
+{{- end}}
+{{- with .Fuzz}}
+
+<section>
+<h2>Fuzzing</h2>
+<p class="muted">{{.Iterations}} iterations over {{.Operations}} operations with seed {{.Seed}}</p>
+{{- if .Failures}}
+<table class="sortable">
+<thead><tr><th class="sortable">Method</th><th class="sortable">Path</th><th class="sortable">Kind</th><th class="sortable num">Occurrences</th><th>Error</th></tr></thead>
+<tbody>
+{{- range .Failures}}
+<tr><td>{{.Method}}</td><td>{{.Path}}</td><td>{{.Kind}}</td><td class="num">{{.Occurrences}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+</section>
+{{- end}}
+</main>
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  table.querySelectorAll("th.sortable").forEach(function (th, column) {
+    th.addEventListener("click", function () {
+      var ascending = !th.classList.contains("asc");
+      table.querySelectorAll("th").forEach(function (other) { other.classList.remove("asc", "desc"); });
+      th.classList.add(ascending ? "asc" : "desc");
+      var tbody = table.tBodies[0];
+      var value = function (row) {
+        var cell = row.cells[column];
+        return cell.dataset.value !== undefined ? cell.dataset.value : cell.textContent.trim();
+      };
+      var rows = Array.prototype.slice.call(tbody.rows);
+      rows.sort(function (a, b) {
+        var x = value(a), y = value(b);
+        var nx = parseFloat(x), ny = parseFloat(y);
+        var order = !isNaN(nx) && !isNaN(ny) ? nx - ny : x.localeCompare(y);
+        return ascending ? order : -order;
+      });
+      rows.forEach(function (row) { tbody.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,274 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/meter-peter/driveby/internal/validation"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one kind of check
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single check
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure describes why a test case failed
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSkipped describes why a test case did not run
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// writeJUnit writes a report as JUnit XML with a suite per kind of check: principles,
+// functional endpoints and their test cases, load tested operations and fuzz failures
+func (g *Generator) writeJUnit(path string, result *validation.ValidationReport) error {
+	suites := junitTestSuites{Name: "driveby"}
+	add := func(suite junitTestSuite) {
+		if len(suite.Cases) == 0 {
+			return
+		}
+		for _, tc := range suite.Cases {
+			suite.Tests++
+			if tc.Failure != nil {
+				suite.Failures++
+			}
+			if tc.Skipped != nil {
+				suite.Skipped++
+			}
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	timestamp := result.Timestamp.Format(time.RFC3339)
+	add(principleSuite(result, timestamp))
+	if tests := result.TestResults; tests != nil {
+		if tests.Functional != nil {
+			add(functionalSuite(tests.Functional, timestamp))
+		}
+		if tests.Performance != nil {
+			add(performanceSuite(tests.Performance, timestamp))
+		}
+		if tests.Fuzz != nil {
+			add(fuzzSuite(tests.Fuzz, timestamp))
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(xml.Header); err != nil {
+		return fmt.Errorf("failed to write XML header: %w", err)
+	}
+	encoder := xml.NewEncoder(file)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	if _, err := file.WriteString("\n"); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// principleSuite has a test case per principle
+func principleSuite(result *validation.ValidationReport, timestamp string) junitTestSuite {
+	suite := junitTestSuite{Name: "principles", Time: "0", Timestamp: timestamp}
+	for _, principle := range result.Principles {
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("%s %s", principle.Principle.ID, principle.Principle.Name),
+			ClassName: "driveby.principles." + junitName(principle.Principle.Category),
+			Time:      "0",
+		}
+		if !principle.Passed {
+			var text []string
+			if principle.Explanation != "" {
+				text = append(text, principle.Explanation)
+			}
+			if principle.SuggestedFix != "" {
+				text = append(text, "Suggested fix: "+principle.SuggestedFix)
+			}
+			tc.Failure = &junitFailure{
+				Message: principle.Message,
+				Type:    principle.Principle.Severity,
+				Text:    strings.Join(text, "\n"),
+			}
+		} else {
+			tc.SystemOut = principle.Message
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for _, finding := range validation.SpecFindings(result) {
+		// Each spec finding is listed under its principle's failure as well
+		for i := range suite.Cases {
+			if strings.HasPrefix(suite.Cases[i].Name, finding.PrincipleID+" ") && suite.Cases[i].Failure != nil {
+				suite.Cases[i].Failure.Text = strings.TrimPrefix(suite.Cases[i].Failure.Text+"\n"+findingLine(finding), "\n")
+			}
+		}
+	}
+	return suite
+}
+
+// functionalSuite has a test case per endpoint and per test case of an endpoint
+func functionalSuite(results *validation.FunctionalTestResults, timestamp string) junitTestSuite {
+	suite := junitTestSuite{Name: "functional", Timestamp: timestamp}
+	var total time.Duration
+	for _, endpoint := range results.EndpointResults {
+		operation := endpoint.Method + " " + endpoint.Path
+		total += endpoint.ResponseTime
+		tc := junitTestCase{
+			Name:      operation,
+			ClassName: "driveby.functional",
+			Time:      junitSeconds(endpoint.ResponseTime),
+			SystemOut: strings.Join(append(append([]string{}, endpoint.Warnings...), endpoint.Notes...), "\n"),
+		}
+		applyJUnitStatus(&tc, endpoint.Status, fmt.Sprintf("status %d", endpoint.StatusCode), endpoint.Errors)
+		suite.Cases = append(suite.Cases, tc)
+
+		for _, testCase := range endpoint.TestCases {
+			tc := junitTestCase{
+				Name:      testCase.Name,
+				ClassName: "driveby.functional." + junitName(operation),
+				Time:      "0",
+				SystemOut: testCase.Description,
+			}
+			var errors []string
+			if testCase.Error != "" {
+				errors = append(errors, testCase.Error)
+			}
+			applyJUnitStatus(&tc, testCase.Status, testCase.Description, errors)
+			suite.Cases = append(suite.Cases, tc)
+		}
+	}
+	suite.Time = junitSeconds(total)
+	return suite
+}
+
+// performanceSuite has a test case per load tested operation, checked against its SLO
+func performanceSuite(results *validation.PerformanceTestResults, timestamp string) junitTestSuite {
+	suite := junitTestSuite{Name: "performance", Time: junitSeconds(results.Duration), Timestamp: timestamp}
+	for _, endpoint := range results.Endpoints {
+		tc := junitTestCase{
+			Name:      endpoint.Method + " " + endpoint.Path,
+			ClassName: "driveby.performance",
+			Time:      "0",
+			SystemOut: fmt.Sprintf("%d requests, error rate %.2f%%, P50 %s, P95 %s, P99 %s",
+				endpoint.TotalRequests, endpoint.ErrorRate*100, endpoint.LatencyP50, endpoint.LatencyP95, endpoint.LatencyP99),
+		}
+		if !endpoint.Passed {
+			tc.Failure = &junitFailure{
+				Message: strings.Join(endpoint.Failures, "; "),
+				Type:    "slo",
+				Text:    strings.Join(endpoint.Failures, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for _, target := range results.SkippedTargets {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      target,
+			ClassName: "driveby.performance",
+			Time:      "0",
+			Skipped:   &junitSkipped{Message: "left out of the load test"},
+		})
+	}
+	return suite
+}
+
+// fuzzSuite has a failing test case per unique fuzz failure, or a single passing one
+func fuzzSuite(results *validation.FuzzTestResults, timestamp string) junitTestSuite {
+	suite := junitTestSuite{Name: "fuzz", Time: junitSeconds(results.Duration), Timestamp: timestamp}
+	if len(results.Failures) == 0 {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      fmt.Sprintf("%d iterations over %d operations (seed %d)", results.Iterations, results.Operations, results.Seed),
+			ClassName: "driveby.fuzz",
+			Time:      junitSeconds(results.Duration),
+		})
+		return suite
+	}
+	for _, failure := range results.Failures {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      fmt.Sprintf("%s %s: %s", failure.Method, failure.Path, failure.Kind),
+			ClassName: "driveby.fuzz",
+			Time:      "0",
+			Failure: &junitFailure{
+				Message: failure.Error,
+				Type:    failure.Kind,
+				Text:    fmt.Sprintf("Seed %d, iteration %d, %d occurrences\nReproduce with:\n%s", failure.Seed, failure.Iteration, failure.Occurrences, failure.Curl),
+			},
+		})
+	}
+	return suite
+}
+
+// applyJUnitStatus marks a test case as failed or skipped according to a test status
+func applyJUnitStatus(tc *junitTestCase, status validation.TestStatus, message string, errors []string) {
+	switch status {
+	case validation.TestStatusFailed:
+		if len(errors) > 0 {
+			message = errors[0]
+		}
+		tc.Failure = &junitFailure{Message: message, Type: string(status), Text: strings.Join(errors, "\n")}
+	case validation.TestStatusSkipped:
+		tc.Skipped = &junitSkipped{Message: strings.Join(errors, "; ")}
+	}
+}
+
+// findingLine describes a spec finding on a single line
+func findingLine(finding validation.Finding) string {
+	if finding.Pointer == "" {
+		return "- " + finding.Message
+	}
+	if location := finding.Location(); location != "" {
+		return fmt.Sprintf("- %s (line %s, %s)", finding.Message, location, finding.Pointer)
+	}
+	return fmt.Sprintf("- %s (%s)", finding.Message, finding.Pointer)
+}
+
+// junitName turns a label into a dot-free class name segment
+func junitName(label string) string {
+	return strings.ReplaceAll(label, ".", "_")
+}
+
+// junitSeconds formats a duration in seconds, as JUnit expects
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
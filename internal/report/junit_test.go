@@ -0,0 +1,120 @@
+package report
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/meter-peter/driveby/internal/validation"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	principles := []validation.PrincipleResult{
+		{Principle: validation.CorePrinciples[0], Passed: true, Message: "Documented"},
+		{Principle: validation.CorePrinciples[1], Message: "Operations are missing responses"},
+	}
+	type suiteCounts struct {
+		name     string
+		tests    int
+		failures int
+		skipped  int
+	}
+	tests := []struct {
+		name   string
+		report *validation.ValidationReport
+		want   []suiteCounts
+	}{
+		{
+			name:   "principles only",
+			report: &validation.ValidationReport{Principles: principles},
+			want:   []suiteCounts{{name: "principles", tests: 2, failures: 1}},
+		},
+		{
+			name: "functional",
+			report: &validation.ValidationReport{TestResults: &validation.TestResults{
+				Functional: &validation.FunctionalTestResults{EndpointResults: []validation.EndpointTestResult{
+					{
+						Method: "GET", Path: "/tasks", Status: validation.TestStatusPassed, StatusCode: 200,
+						TestCases: []validation.TestCaseResult{
+							{Name: "missing limit", Status: validation.TestStatusPassed},
+							{Name: "limit of the wrong type", Status: validation.TestStatusFailed, Error: "got 200, want 400"},
+						},
+					},
+					{Method: "DELETE", Path: "/tasks/{id}", Status: validation.TestStatusSkipped, Errors: []string{"no example for id"}},
+				}},
+			}},
+			want: []suiteCounts{{name: "functional", tests: 4, failures: 1, skipped: 1}},
+		},
+		{
+			name: "performance",
+			report: &validation.ValidationReport{TestResults: &validation.TestResults{
+				Performance: &validation.PerformanceTestResults{
+					Duration: time.Minute,
+					Endpoints: []validation.EndpointMetrics{
+						{Method: "GET", Path: "/tasks", Passed: true},
+						{Method: "POST", Path: "/tasks", Failures: []string{"P95 latency 900ms exceeds 500ms"}},
+					},
+					SkippedTargets: []string{"DELETE /tasks/{id}"},
+				},
+			}},
+			want: []suiteCounts{{name: "performance", tests: 3, failures: 1, skipped: 1}},
+		},
+		{
+			name: "fuzz without failures",
+			report: &validation.ValidationReport{TestResults: &validation.TestResults{
+				Fuzz: &validation.FuzzTestResults{Seed: 7, Iterations: 100, Operations: 3},
+			}},
+			want: []suiteCounts{{name: "fuzz", tests: 1}},
+		},
+		{
+			name: "every kind of check",
+			report: &validation.ValidationReport{Principles: principles, TestResults: &validation.TestResults{
+				Functional: &validation.FunctionalTestResults{},
+				Fuzz: &validation.FuzzTestResults{Failures: []validation.FuzzFailure{
+					{Kind: "server_error", Method: "POST", Path: "/tasks", Error: "status 500"},
+					{Kind: "schema_mismatch", Method: "GET", Path: "/tasks", Error: "missing id"},
+				}},
+			}},
+			want: []suiteCounts{{name: "principles", tests: 2, failures: 1}, {name: "fuzz", tests: 2, failures: 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.xml")
+			if err := NewGenerator(t.TempDir()).writeJUnit(path, tt.report); err != nil {
+				t.Fatalf("writeJUnit: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			var suites junitTestSuites
+			if err := xml.Unmarshal(data, &suites); err != nil {
+				t.Fatalf("report is not valid XML: %v", err)
+			}
+
+			var got []suiteCounts
+			var total suiteCounts
+			for _, suite := range suites.Suites {
+				got = append(got, suiteCounts{name: suite.Name, tests: suite.Tests, failures: suite.Failures, skipped: suite.Skipped})
+				total.tests += suite.Tests
+				total.failures += suite.Failures
+				total.skipped += suite.Skipped
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("suites are %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("suite %d is %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+			if suites.Tests != total.tests || suites.Failures != total.failures || suites.Skipped != total.skipped {
+				t.Errorf("totals are %d tests, %d failures, %d skipped, want the sum of the suites %+v",
+					suites.Tests, suites.Failures, suites.Skipped, total)
+			}
+		})
+	}
+}
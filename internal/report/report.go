@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,16 +21,62 @@ func init() {
 	log.Infof("[report] Logger set to DEBUG (verbose) mode")
 }
 
+// Format is an output format of the report generator
+type Format string
+
+const (
+	FormatJSON     Format = "json"     // Per-command JSON reports
+	FormatMarkdown Format = "markdown" // Per-command Markdown reports
+	FormatJUnit    Format = "junit"    // junit.xml with a test case per principle, endpoint and test case
+	FormatSARIF    Format = "sarif"    // driveby.sarif with the spec findings as annotations on the spec
+	FormatHTML     Format = "html"     // report.html, a self-contained page for readers who do not read JSON
+)
+
+// DefaultFormats are the formats written when none are selected
+var DefaultFormats = []Format{FormatJSON, FormatMarkdown}
+
+// ParseFormats parses output format names
+func ParseFormats(values []string) ([]Format, error) {
+	var formats []Format
+	for _, value := range values {
+		format := Format(strings.ToLower(strings.TrimSpace(value)))
+		switch format {
+		case "":
+			continue
+		case FormatJSON, FormatMarkdown, FormatJUnit, FormatSARIF, FormatHTML:
+			formats = append(formats, format)
+		default:
+			return nil, fmt.Errorf("unknown report format %q (expected json, markdown, junit, sarif or html)", value)
+		}
+	}
+	return formats, nil
+}
+
 // Generator handles report generation
 type Generator struct {
 	outputDir string
+	formats   map[Format]bool
 }
 
-// NewGenerator creates a new report generator
-func NewGenerator(outputDir string) *Generator {
-	return &Generator{
+// NewGenerator creates a new report generator writing the given formats, or the
+// default formats when none are given
+func NewGenerator(outputDir string, formats ...Format) *Generator {
+	if len(formats) == 0 {
+		formats = DefaultFormats
+	}
+	g := &Generator{
 		outputDir: outputDir,
+		formats:   make(map[Format]bool, len(formats)),
+	}
+	for _, format := range formats {
+		g.formats[format] = true
 	}
+	return g
+}
+
+// writes reports whether the generator writes a format
+func (g *Generator) writes(format Format) bool {
+	return g.formats[format]
 }
 
 // SaveValidationReport saves a validation report to JSON and Markdown files
@@ -40,17 +87,21 @@ func (g *Generator) SaveValidationReport(result *validation.ValidationReport) er
 	}
 
 	// Save JSON report
-	jsonPath := filepath.Join(g.outputDir, "validation-report.json")
-	if err := g.saveJSON(jsonPath, result); err != nil {
-		log.Debugf("Returning from SaveValidationReport with error: %v", err)
-		return fmt.Errorf("failed to save JSON report: %w", err)
+	if g.writes(FormatJSON) {
+		jsonPath := filepath.Join(g.outputDir, "validation-report.json")
+		if err := g.saveJSON(jsonPath, result); err != nil {
+			log.Debugf("Returning from SaveValidationReport with error: %v", err)
+			return fmt.Errorf("failed to save JSON report: %w", err)
+		}
 	}
 
 	// Save Markdown report
-	mdPath := filepath.Join(g.outputDir, "validation-report.md")
-	if err := g.saveMarkdown(mdPath, result); err != nil {
-		log.Debugf("Returning from SaveValidationReport with error: %v", err)
-		return fmt.Errorf("failed to save Markdown report: %w", err)
+	if g.writes(FormatMarkdown) {
+		mdPath := filepath.Join(g.outputDir, "validation-report.md")
+		if err := g.saveMarkdown(mdPath, result); err != nil {
+			log.Debugf("Returning from SaveValidationReport with error: %v", err)
+			return fmt.Errorf("failed to save Markdown report: %w", err)
+		}
 	}
 
 	log.Debugf("Returning from SaveValidationReport with nil")
@@ -84,17 +135,21 @@ func (g *Generator) SavePerformanceReport(result *validation.ValidationReport) e
 	}
 
 	// Save JSON report
-	jsonPath := filepath.Join(g.outputDir, "loadtest-report.json")
-	if err := g.saveJSON(jsonPath, perfMetrics); err != nil {
-		log.Debugf("Returning from SavePerformanceReport with error: %v", err)
-		return fmt.Errorf("failed to save JSON report: %w", err)
+	if g.writes(FormatJSON) {
+		jsonPath := filepath.Join(g.outputDir, "loadtest-report.json")
+		if err := g.saveJSON(jsonPath, perfMetrics); err != nil {
+			log.Debugf("Returning from SavePerformanceReport with error: %v", err)
+			return fmt.Errorf("failed to save JSON report: %w", err)
+		}
 	}
 
 	// Save Markdown report
-	mdPath := filepath.Join(g.outputDir, "loadtest-report.md")
-	if err := g.saveMarkdown(mdPath, perfMetrics); err != nil {
-		log.Debugf("Returning from SavePerformanceReport with error: %v", err)
-		return fmt.Errorf("failed to save Markdown report: %w", err)
+	if g.writes(FormatMarkdown) {
+		mdPath := filepath.Join(g.outputDir, "loadtest-report.md")
+		if err := g.saveMarkdown(mdPath, perfMetrics); err != nil {
+			log.Debugf("Returning from SavePerformanceReport with error: %v", err)
+			return fmt.Errorf("failed to save Markdown report: %w", err)
+		}
 	}
 
 	log.Debugf("Returning from SavePerformanceReport with nil")
@@ -116,6 +171,13 @@ func (g *Generator) SaveFunctionalTestReport(result *validation.ValidationReport
 				endpointResults = details
 				break
 			}
+			// Failed runs wrap the endpoint results together with the failure list
+			if details, ok := principle.Details.(map[string]interface{}); ok {
+				if all, ok := details["all_results"].([]validation.EndpointValidation); ok {
+					endpointResults = all
+					break
+				}
+			}
 		}
 	}
 
@@ -128,17 +190,21 @@ func (g *Generator) SaveFunctionalTestReport(result *validation.ValidationReport
 	}
 
 	// Save JSON report
-	jsonPath := filepath.Join(g.outputDir, "functional-test-report.json")
-	if err := g.saveJSON(jsonPath, endpointResults); err != nil {
-		log.Debugf("Returning from SaveFunctionalTestReport with error: %v", err)
-		return fmt.Errorf("failed to save JSON report: %w", err)
+	if g.writes(FormatJSON) {
+		jsonPath := filepath.Join(g.outputDir, "functional-test-report.json")
+		if err := g.saveJSON(jsonPath, endpointResults); err != nil {
+			log.Debugf("Returning from SaveFunctionalTestReport with error: %v", err)
+			return fmt.Errorf("failed to save JSON report: %w", err)
+		}
 	}
 
 	// Save Markdown report
-	mdPath := filepath.Join(g.outputDir, "functional-test-report.md")
-	if err := g.saveMarkdown(mdPath, endpointResults); err != nil {
-		log.Debugf("Returning from SaveFunctionalTestReport with error: %v", err)
-		return fmt.Errorf("failed to save Markdown report: %w", err)
+	if g.writes(FormatMarkdown) {
+		mdPath := filepath.Join(g.outputDir, "functional-test-report.md")
+		if err := g.saveMarkdown(mdPath, endpointResults); err != nil {
+			log.Debugf("Returning from SaveFunctionalTestReport with error: %v", err)
+			return fmt.Errorf("failed to save Markdown report: %w", err)
+		}
 	}
 
 	log.Debugf("Returning from SaveFunctionalTestReport with nil")
@@ -172,23 +238,155 @@ func (g *Generator) SaveLoadTestReport(result *validation.ValidationReport) erro
 	}
 
 	// Save JSON report
-	jsonPath := filepath.Join(g.outputDir, "load-test-report.json")
-	if err := g.saveJSON(jsonPath, perfMetrics); err != nil {
-		log.Debugf("Returning from SaveLoadTestReport with error: %v", err)
-		return fmt.Errorf("failed to save JSON report: %w", err)
+	if g.writes(FormatJSON) {
+		jsonPath := filepath.Join(g.outputDir, "load-test-report.json")
+		if err := g.saveJSON(jsonPath, perfMetrics); err != nil {
+			log.Debugf("Returning from SaveLoadTestReport with error: %v", err)
+			return fmt.Errorf("failed to save JSON report: %w", err)
+		}
 	}
 
 	// Save Markdown report
-	mdPath := filepath.Join(g.outputDir, "load-test-report.md")
-	if err := g.saveMarkdown(mdPath, perfMetrics); err != nil {
-		log.Debugf("Returning from SaveLoadTestReport with error: %v", err)
-		return fmt.Errorf("failed to save Markdown report: %w", err)
+	if g.writes(FormatMarkdown) {
+		mdPath := filepath.Join(g.outputDir, "load-test-report.md")
+		if err := g.saveMarkdown(mdPath, perfMetrics); err != nil {
+			log.Debugf("Returning from SaveLoadTestReport with error: %v", err)
+			return fmt.Errorf("failed to save Markdown report: %w", err)
+		}
 	}
 
 	log.Debugf("Returning from SaveLoadTestReport with nil")
 	return nil
 }
 
+// SaveFuzzReport saves a fuzzing report and one reproducer file per failure
+func (g *Generator) SaveFuzzReport(result *validation.ValidationReport) error {
+	log.Debugf("Enter SaveFuzzReport with result: %+v", result)
+	if result.TestResults == nil || result.TestResults.Fuzz == nil {
+		return fmt.Errorf("no fuzz test results found in validation result")
+	}
+	fuzzResults := result.TestResults.Fuzz
+
+	reproducerDir := filepath.Join(g.outputDir, "fuzz-reproducers")
+	if err := os.MkdirAll(reproducerDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Save JSON report
+	if g.writes(FormatJSON) {
+		jsonPath := filepath.Join(g.outputDir, "fuzz-report.json")
+		if err := g.saveJSON(jsonPath, fuzzResults); err != nil {
+			log.Debugf("Returning from SaveFuzzReport with error: %v", err)
+			return fmt.Errorf("failed to save JSON report: %w", err)
+		}
+	}
+
+	// Save Markdown report
+	if g.writes(FormatMarkdown) {
+		mdPath := filepath.Join(g.outputDir, "fuzz-report.md")
+		if err := g.saveMarkdown(mdPath, fuzzResults); err != nil {
+			log.Debugf("Returning from SaveFuzzReport with error: %v", err)
+			return fmt.Errorf("failed to save Markdown report: %w", err)
+		}
+	}
+
+	// Save reproducers
+	for i, failure := range fuzzResults.Failures {
+		if err := g.saveJSON(filepath.Join(reproducerDir, reproducerFileName(i+1, failure)), failure); err != nil {
+			log.Debugf("Returning from SaveFuzzReport with error: %v", err)
+			return fmt.Errorf("failed to save reproducer: %w", err)
+		}
+	}
+
+	log.Debugf("Returning from SaveFuzzReport with nil")
+	return nil
+}
+
+// reproducerFileName builds a file name such as 001-server_error-post-tasks-id.json
+func reproducerFileName(index int, failure validation.FuzzFailure) string {
+	path := strings.Trim(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, failure.Path), "-")
+	for strings.Contains(path, "--") {
+		path = strings.ReplaceAll(path, "--", "-")
+	}
+	return fmt.Sprintf("%03d-%s-%s-%s.json", index, failure.Kind, strings.ToLower(failure.Method), path)
+}
+
+// SaveDiffReport saves a spec comparison report
+func (g *Generator) SaveDiffReport(result *validation.ValidationReport) error {
+	log.Debugf("Enter SaveDiffReport with result: %+v", result)
+	var diff *validation.SpecDiff
+	for _, principle := range result.Principles {
+		if principle.Principle.ID == "P008" {
+			if details, ok := principle.Details.(*validation.SpecDiff); ok {
+				diff = details
+				break
+			}
+		}
+	}
+
+	if diff == nil {
+		return fmt.Errorf("no spec diff found in validation result")
+	}
+
+	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Save JSON report
+	if g.writes(FormatJSON) {
+		jsonPath := filepath.Join(g.outputDir, "diff-report.json")
+		if err := g.saveJSON(jsonPath, diff); err != nil {
+			log.Debugf("Returning from SaveDiffReport with error: %v", err)
+			return fmt.Errorf("failed to save JSON report: %w", err)
+		}
+	}
+
+	// Save Markdown report
+	if g.writes(FormatMarkdown) {
+		mdPath := filepath.Join(g.outputDir, "diff-report.md")
+		if err := g.saveMarkdown(mdPath, diff); err != nil {
+			log.Debugf("Returning from SaveDiffReport with error: %v", err)
+			return fmt.Errorf("failed to save Markdown report: %w", err)
+		}
+	}
+
+	log.Debugf("Returning from SaveDiffReport with nil")
+	return nil
+}
+
+// SaveRunReports saves the reports covering a whole run, when selected: JUnit XML,
+// SARIF and HTML. SARIF results are located in the spec at specPath.
+func (g *Generator) SaveRunReports(result *validation.ValidationReport, specPath string) error {
+	if !g.writes(FormatJUnit) && !g.writes(FormatSARIF) && !g.writes(FormatHTML) {
+		return nil
+	}
+	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if g.writes(FormatJUnit) {
+		if err := g.writeJUnit(filepath.Join(g.outputDir, "junit.xml"), result); err != nil {
+			return fmt.Errorf("failed to save JUnit report: %w", err)
+		}
+	}
+	if g.writes(FormatSARIF) {
+		if err := g.writeSARIF(filepath.Join(g.outputDir, "driveby.sarif"), result, specPath); err != nil {
+			return fmt.Errorf("failed to save SARIF report: %w", err)
+		}
+	}
+	if g.writes(FormatHTML) {
+		if err := g.saveHTML(filepath.Join(g.outputDir, "report.html"), result); err != nil {
+			return fmt.Errorf("failed to save HTML report: %w", err)
+		}
+	}
+	return nil
+}
+
 // saveJSON saves a report in JSON format
 func (g *Generator) saveJSON(path string, data interface{}) error {
 	log.Debugf("Enter saveJSON with path: %s and data: %+v", path, data)
@@ -227,6 +425,10 @@ func (g *Generator) saveMarkdown(path string, data interface{}) error {
 		return g.writeLoadTestMarkdown(file, v)
 	case []validation.EndpointValidation:
 		return g.writeFunctionalTestMarkdown(file, v)
+	case *validation.FuzzTestResults:
+		return g.writeFuzzMarkdown(file, v)
+	case *validation.SpecDiff:
+		return g.writeDiffMarkdown(file, v)
 	default:
 		log.Debugf("Returning from saveMarkdown with error: %v", fmt.Errorf("unsupported report type: %T", data))
 		return fmt.Errorf("unsupported report type: %T", data)
@@ -340,6 +542,30 @@ Version: %s
 					return err
 				}
 
+				// Write where the findings are in the spec file
+				if err := writeFindingLocations(file, principleResult.Findings); err != nil {
+					return err
+				}
+
+				// Write how the findings affect testing
+				if impact := principleResult.TestImpact; impact != nil && len(impact.Operations) > 0 {
+					if _, err := fmt.Fprintf(file, "\n**Test Impact (%s):**\n", impact.ImpactLevel); err != nil {
+						return fmt.Errorf("failed to write test impact header: %w", err)
+					}
+					for _, opImpact := range impact.Operations {
+						var effects []string
+						if len(opImpact.Skipped) > 0 {
+							effects = append(effects, "skips "+strings.Join(opImpact.Skipped, ", ")+" tests")
+						}
+						if len(opImpact.Downgraded) > 0 {
+							effects = append(effects, "downgrades "+strings.Join(opImpact.Downgraded, ", ")+" failures to warnings")
+						}
+						if _, err := fmt.Fprintf(file, "- %s: %s because %s\n", opImpact.Operation, strings.Join(effects, " and "), opImpact.Reason); err != nil {
+							return fmt.Errorf("failed to write operation impact: %w", err)
+						}
+					}
+				}
+
 				// Write suggested fix if available
 				if principleResult.SuggestedFix != "" {
 					if _, err := fmt.Fprintf(file, "\n**Suggested Fix:**\n%s\n", principleResult.SuggestedFix); err != nil {
@@ -354,6 +580,46 @@ Version: %s
 		}
 	}
 
+	// Write test summary if tests were part of the run
+	if testSummary := report.Summary.TestSummary; testSummary != nil {
+		if _, err := fmt.Fprintf(file, `## Test Summary
+
+- Total Tests: %d
+- Passed Tests: %d
+- Failed Tests: %d
+- Skipped Tests: %d
+- Warnings: %d
+- Functional Status: %s
+- Performance Status: %s
+
+`, testSummary.TotalTests,
+			testSummary.PassedTests,
+			testSummary.FailedTests,
+			testSummary.SkippedTests,
+			testSummary.Warnings,
+			testSummary.FunctionalStatus,
+			testSummary.PerformanceStatus); err != nil {
+			return fmt.Errorf("failed to write test summary: %w", err)
+		}
+		if len(testSummary.SkipReasons) > 0 {
+			if _, err := fmt.Fprintf(file, "### Skipped Phases\n\n"); err != nil {
+				return fmt.Errorf("failed to write skipped phases header: %w", err)
+			}
+			for _, phase := range []validation.ValidationType{validation.ValidationTypeSpec, validation.ValidationTypeFunctional, validation.ValidationTypePerformance} {
+				reason, ok := testSummary.SkipReasons[phase]
+				if !ok {
+					continue
+				}
+				if _, err := fmt.Fprintf(file, "- **%s:** %s\n", phase, reason); err != nil {
+					return fmt.Errorf("failed to write skip reason: %w", err)
+				}
+			}
+			if _, err := fmt.Fprintf(file, "\n"); err != nil {
+				return fmt.Errorf("failed to write skipped phases separator: %w", err)
+			}
+		}
+	}
+
 	// Write auto-fixes if any
 	if len(report.AutoFixes) > 0 {
 		if _, err := fmt.Fprintf(file, "## Automatic Fixes Applied\n\n"); err != nil {
@@ -372,11 +638,25 @@ Version: %s
 			if _, err := fmt.Fprintf(file, "- **Status:** %s\n", fixStatus(fix.Success)); err != nil {
 				return fmt.Errorf("failed to write auto-fix status: %w", err)
 			}
+			if fix.Original.Message != "" {
+				if _, err := fmt.Fprintf(file, "- **Problem:** %s\n", fix.Original.Message); err != nil {
+					return fmt.Errorf("failed to write auto-fix problem: %w", err)
+				}
+			}
 			if fix.Message != "" {
 				if _, err := fmt.Fprintf(file, "- **Message:** %s\n", fix.Message); err != nil {
 					return fmt.Errorf("failed to write auto-fix message: %w", err)
 				}
 			}
+			if fix.Success {
+				before := fix.Before
+				if before == "" {
+					before = "(absent)"
+				}
+				if _, err := fmt.Fprintf(file, "- **Before:** `%s`\n- **After:** `%s`\n", before, fix.Fixed); err != nil {
+					return fmt.Errorf("failed to write auto-fix snippet: %w", err)
+				}
+			}
 			if fix.Error != "" {
 				if _, err := fmt.Fprintf(file, "- **Error:** %s\n", fix.Error); err != nil {
 					return fmt.Errorf("failed to write auto-fix error: %w", err)
@@ -512,6 +792,47 @@ func (g *Generator) writePrincipleDetails(file *os.File, principleResult validat
 			if _, err := fmt.Fprintf(file, "- End: %s\n", details.EndTime.Format(time.RFC3339)); err != nil {
 				return fmt.Errorf("failed to write end time: %w", err)
 			}
+
+			if len(details.Endpoints) > 0 {
+				if _, err := fmt.Fprintf(file, "\n**Endpoints**\n\n"); err != nil {
+					return fmt.Errorf("failed to write endpoints header: %w", err)
+				}
+				if err := writeEndpointMetricsTable(file, details.Endpoints); err != nil {
+					return err
+				}
+			}
+			if details.Baseline != nil {
+				if _, err := fmt.Fprintf(file, "\n**Baseline**\n\n"); err != nil {
+					return fmt.Errorf("failed to write baseline header: %w", err)
+				}
+				if err := writeBaselineComparison(file, details.Baseline); err != nil {
+					return err
+				}
+			}
+			if details.Capacity != nil {
+				if _, err := fmt.Fprintf(file, "\n**Capacity**\n\n"); err != nil {
+					return fmt.Errorf("failed to write capacity header: %w", err)
+				}
+				if err := writeCapacityMetrics(file, details.Capacity); err != nil {
+					return err
+				}
+			}
+			if len(details.Journeys) > 0 {
+				if _, err := fmt.Fprintf(file, "\n**Journeys (%d virtual users)**\n\n", details.VirtualUsers); err != nil {
+					return fmt.Errorf("failed to write journeys header: %w", err)
+				}
+				if err := writeJourneyMetrics(file, details.Journeys); err != nil {
+					return err
+				}
+			}
+			if len(details.Stages) > 0 {
+				if _, err := fmt.Fprintf(file, "\n**Stages (%s profile)**\n\n", details.LoadProfile); err != nil {
+					return fmt.Errorf("failed to write stages header: %w", err)
+				}
+				if err := writeStageMetricsTable(file, details.Stages); err != nil {
+					return err
+				}
+			}
 		}
 	default:
 		// For other principles, format details as JSON
@@ -571,10 +892,273 @@ func (g *Generator) writeLoadTestMarkdown(file *os.File, metrics *validation.Per
 		log.Debugf("Returning from writeLoadTestMarkdown with error: %v", err)
 		return fmt.Errorf("failed to write load test report header: %w", err)
 	}
+	if metrics.Baseline != nil {
+		if _, err := fmt.Fprintf(file, "## Baseline\n\n"); err != nil {
+			return fmt.Errorf("failed to write baseline header: %w", err)
+		}
+		if err := writeBaselineComparison(file, metrics.Baseline); err != nil {
+			return err
+		}
+	}
+	if metrics.Capacity != nil {
+		if _, err := fmt.Fprintf(file, "## Capacity\n\n"); err != nil {
+			return fmt.Errorf("failed to write capacity header: %w", err)
+		}
+		if err := writeCapacityMetrics(file, metrics.Capacity); err != nil {
+			return err
+		}
+	}
+	if len(metrics.Journeys) > 0 {
+		if _, err := fmt.Fprintf(file, "## Journeys (%d virtual users)\n\n", metrics.VirtualUsers); err != nil {
+			return fmt.Errorf("failed to write journeys header: %w", err)
+		}
+		if err := writeJourneyMetrics(file, metrics.Journeys); err != nil {
+			return err
+		}
+	}
+	if len(metrics.Stages) > 0 {
+		if _, err := fmt.Fprintf(file, "## Stages (%s profile)\n\n", metrics.LoadProfile); err != nil {
+			return fmt.Errorf("failed to write stages header: %w", err)
+		}
+		if err := writeStageMetricsTable(file, metrics.Stages); err != nil {
+			return err
+		}
+	}
+	if len(metrics.Endpoints) > 0 {
+		if _, err := fmt.Fprintf(file, "## Endpoints\n\n"); err != nil {
+			return fmt.Errorf("failed to write endpoints header: %w", err)
+		}
+		if err := writeEndpointMetricsTable(file, metrics.Endpoints); err != nil {
+			return err
+		}
+		for _, endpoint := range metrics.Endpoints {
+			for _, failure := range endpoint.Failures {
+				if _, err := fmt.Fprintf(file, "- %s %s: %s\n", endpoint.Method, endpoint.Path, failure); err != nil {
+					return fmt.Errorf("failed to write endpoint failure: %w", err)
+				}
+			}
+		}
+		if _, err := fmt.Fprintf(file, "\n"); err != nil {
+			return fmt.Errorf("failed to write endpoints separator: %w", err)
+		}
+	}
+	if len(metrics.FailedRequests) > 0 {
+		if _, err := fmt.Fprintf(file, "## Failed Request Samples\n\n"); err != nil {
+			return fmt.Errorf("failed to write failed requests header: %w", err)
+		}
+		for _, failed := range metrics.FailedRequests {
+			line := fmt.Sprintf("- %s %s: status %d after %s at %s", failed.Method, failed.Path, failed.StatusCode,
+				failed.Latency, failed.Timestamp.Format(time.RFC3339))
+			if failed.Error != "" {
+				line += fmt.Sprintf(" (%s)", failed.Error)
+			}
+			if _, err := fmt.Fprintf(file, "%s\n", line); err != nil {
+				return fmt.Errorf("failed to write failed request: %w", err)
+			}
+		}
+		if _, err := fmt.Fprintf(file, "\n"); err != nil {
+			return fmt.Errorf("failed to write failed requests separator: %w", err)
+		}
+	}
+	if len(metrics.SkippedTargets) > 0 {
+		if _, err := fmt.Fprintf(file, "## Skipped Targets\n\n"); err != nil {
+			return fmt.Errorf("failed to write skipped targets header: %w", err)
+		}
+		for _, target := range metrics.SkippedTargets {
+			if _, err := fmt.Fprintf(file, "- %s\n", target); err != nil {
+				return fmt.Errorf("failed to write skipped target: %w", err)
+			}
+		}
+	}
 	log.Debugf("Returning from writeLoadTestMarkdown with nil")
 	return nil
 }
 
+// writeFindingLocations lists the spec file positions of a principle's findings
+func writeFindingLocations(file *os.File, findings []validation.Finding) error {
+	var located []validation.Finding
+	for _, finding := range findings {
+		if finding.Line > 0 {
+			located = append(located, finding)
+		}
+	}
+	if len(located) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(file, "\n**Locations:**\n"); err != nil {
+		return fmt.Errorf("failed to write locations header: %w", err)
+	}
+	for _, finding := range located {
+		if _, err := fmt.Fprintf(file, "- Line %s `%s`: %s\n", finding.Location(), finding.Pointer, finding.Message); err != nil {
+			return fmt.Errorf("failed to write location: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeJourneyMetrics writes a summary table of closed-model journeys followed by the
+// step latencies of each journey
+func writeJourneyMetrics(file *os.File, journeys []validation.JourneyMetrics) error {
+	if _, err := fmt.Fprintf(file, "| Journey | Started | Completed | Failed | Journeys/sec | P50 Duration | P95 Duration |\n"+
+		"|---------|---------|-----------|--------|--------------|--------------|--------------|\n"); err != nil {
+		return fmt.Errorf("failed to write journeys table header: %w", err)
+	}
+	for _, journey := range journeys {
+		if _, err := fmt.Fprintf(file, "| %s | %d | %d | %d | %.2f | %s | %s |\n", journey.Name, journey.Started,
+			journey.Completed, journey.Failed, journey.JourneysPerSec, journey.DurationP50, journey.DurationP95); err != nil {
+			return fmt.Errorf("failed to write journey row: %w", err)
+		}
+	}
+	for _, journey := range journeys {
+		if _, err := fmt.Fprintf(file, "\n%s steps:\n\n| Step | Operation | Requests | Error Rate | P50 | P95 | P99 |\n"+
+			"|------|-----------|----------|------------|-----|-----|-----|\n", journey.Name); err != nil {
+			return fmt.Errorf("failed to write journey steps header: %w", err)
+		}
+		for i, step := range journey.Steps {
+			if _, err := fmt.Fprintf(file, "| %d | %s | %d | %.2f%% | %s | %s | %s |\n", i+1, step.Operation,
+				step.TotalRequests, step.ErrorRate*100, step.LatencyP50, step.LatencyP95, step.LatencyP99); err != nil {
+				return fmt.Errorf("failed to write journey step row: %w", err)
+			}
+		}
+	}
+	if _, err := fmt.Fprintf(file, "\n"); err != nil {
+		return fmt.Errorf("failed to write journeys separator: %w", err)
+	}
+	return nil
+}
+
+// writeBaselineComparison writes how a load test compares with the recorded baseline
+func writeBaselineComparison(file *os.File, baseline *validation.BaselineComparison) error {
+	if baseline.Skipped != "" {
+		if _, err := fmt.Fprintf(file, "Not compared: %s.\n", baseline.Skipped); err != nil {
+			return fmt.Errorf("failed to write baseline skip reason: %w", err)
+		}
+	} else {
+		if _, err := fmt.Fprintf(file, "Compared with %s %s, recorded %s.\n\n"+
+			"| Metric | Baseline | Current | Change | Tolerance |\n"+
+			"|--------|----------|---------|--------|-----------|\n"+
+			"| P95 Latency | %s | %s | %+.1f%% | +%.1f%% |\n"+
+			"| Throughput | %.2f/s | %.2f/s | %+.1f%% | -%.1f%% |\n\n",
+			baseline.Environment, baseline.Version, baseline.RecordedAt.Format(time.RFC3339),
+			baseline.BaselineLatencyP95, baseline.LatencyP95, baseline.LatencyP95Change*100, baseline.P95Tolerance*100,
+			baseline.BaselineThroughput, baseline.Throughput, baseline.ThroughputChange*100, baseline.ThroughputTolerance*100); err != nil {
+			return fmt.Errorf("failed to write baseline table: %w", err)
+		}
+		for _, regression := range baseline.Regressions {
+			if _, err := fmt.Fprintf(file, "- ❌ %s\n", regression); err != nil {
+				return fmt.Errorf("failed to write baseline regression: %w", err)
+			}
+		}
+		if len(baseline.Regressions) == 0 {
+			if _, err := fmt.Fprintf(file, "No regressions beyond the tolerances.\n"); err != nil {
+				return fmt.Errorf("failed to write baseline result: %w", err)
+			}
+		}
+	}
+	if baseline.Recorded {
+		if _, err := fmt.Fprintf(file, "\nThis run was recorded as the new baseline.\n"); err != nil {
+			return fmt.Errorf("failed to write baseline update: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintf(file, "\n"); err != nil {
+		return fmt.Errorf("failed to write baseline separator: %w", err)
+	}
+	return nil
+}
+
+// writeCapacityMetrics writes the outcome of a capacity search followed by the rate
+// against latency curve of its probes
+func writeCapacityMetrics(file *os.File, capacity *validation.CapacityResult) error {
+	if _, err := fmt.Fprintf(file, "- Maximum Sustainable Rate: %d req/s\n", capacity.MaxSustainableRate); err != nil {
+		return fmt.Errorf("failed to write maximum sustainable rate: %w", err)
+	}
+	breakingPoint := "not reached"
+	switch {
+	case capacity.BreakingPoint > 0:
+		breakingPoint = fmt.Sprintf("%d req/s (%s)", capacity.BreakingPoint, capacity.BreakingReason)
+	case capacity.CeilingReached:
+		breakingPoint = "not reached below the rate ceiling"
+	}
+	if _, err := fmt.Fprintf(file, "- Breaking Point: %s\n\n", breakingPoint); err != nil {
+		return fmt.Errorf("failed to write breaking point: %w", err)
+	}
+	if _, err := fmt.Fprintf(file, "| Rate | Achieved Rate | Requests | Error Rate | P50 | P95 | P99 | Result |\n"+
+		"|------|---------------|----------|------------|-----|-----|-----|--------|\n"); err != nil {
+		return fmt.Errorf("failed to write capacity table header: %w", err)
+	}
+	for _, probe := range capacity.Curve {
+		result := "passed"
+		if !probe.Passed {
+			result = "failed: " + probe.Reason
+		}
+		if _, err := fmt.Fprintf(file, "| %d/s | %.2f/s | %d | %.2f%% | %s | %s | %s | %s |\n",
+			probe.Rate, probe.RequestsPerSec, probe.TotalRequests, probe.ErrorRate*100,
+			probe.LatencyP50, probe.LatencyP95, probe.LatencyP99, result); err != nil {
+			return fmt.Errorf("failed to write capacity probe row: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintf(file, "\n"); err != nil {
+		return fmt.Errorf("failed to write capacity table separator: %w", err)
+	}
+	return nil
+}
+
+// writeStageMetricsTable writes the per-stage load test breakdown as a table
+func writeStageMetricsTable(file *os.File, stages []validation.StageMetrics) error {
+	if _, err := fmt.Fprintf(file, "| Stage | Window | Planned Rate | Achieved Rate | Requests | Error Rate | P50 | P95 | P99 | P95 Target |\n"+
+		"|-------|--------|--------------|---------------|----------|------------|-----|-----|-----|------------|\n"); err != nil {
+		return fmt.Errorf("failed to write stages table header: %w", err)
+	}
+	for _, stage := range stages {
+		planned := fmt.Sprintf("%.0f/s", stage.StartRate)
+		if stage.EndRate != stage.StartRate {
+			planned = fmt.Sprintf("%.0f-%.0f/s", stage.StartRate, stage.EndRate)
+		}
+		target := "met"
+		if !stage.WithinTarget {
+			target = "exceeded"
+		}
+		if _, err := fmt.Fprintf(file, "| %s | %s-%s | %s | %.2f/s | %d | %.2f%% | %s | %s | %s | %s |\n",
+			stage.Name, stage.Start, stage.Start+stage.Duration, planned, stage.RequestsPerSec, stage.TotalRequests,
+			stage.ErrorRate*100, stage.LatencyP50, stage.LatencyP95, stage.LatencyP99, target); err != nil {
+			return fmt.Errorf("failed to write stage row: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintf(file, "\n"); err != nil {
+		return fmt.Errorf("failed to write stages table separator: %w", err)
+	}
+	return nil
+}
+
+// writeEndpointMetricsTable writes the per-operation load test breakdown as a table
+func writeEndpointMetricsTable(file *os.File, endpoints []validation.EndpointMetrics) error {
+	if _, err := fmt.Fprintf(file, "| Endpoint | Requests | Error Rate | P50 | P95 | P99 | Throughput | Status Codes | SLO |\n"+
+		"|----------|----------|------------|-----|-----|-----|------------|--------------|-----|\n"); err != nil {
+		return fmt.Errorf("failed to write endpoints table header: %w", err)
+	}
+	for _, endpoint := range endpoints {
+		codes := make([]string, 0, len(endpoint.StatusCodes))
+		for code, count := range endpoint.StatusCodes {
+			codes = append(codes, fmt.Sprintf("%s: %d", code, count))
+		}
+		sort.Strings(codes)
+		slo := "met"
+		if !endpoint.Passed {
+			slo = "missed"
+		}
+		if _, err := fmt.Fprintf(file, "| %s %s | %d | %.2f%% | %s | %s | %s | %.2f/s | %s | %s |\n",
+			endpoint.Method, endpoint.Path, endpoint.TotalRequests, endpoint.ErrorRate*100,
+			endpoint.LatencyP50, endpoint.LatencyP95, endpoint.LatencyP99, endpoint.Throughput,
+			strings.Join(codes, ", "), slo); err != nil {
+			return fmt.Errorf("failed to write endpoint row: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintf(file, "\n"); err != nil {
+		return fmt.Errorf("failed to write endpoints table separator: %w", err)
+	}
+	return nil
+}
+
 // writeFunctionalTestMarkdown writes a functional test report in Markdown format
 func (g *Generator) writeFunctionalTestMarkdown(file *os.File, results []validation.EndpointValidation) error {
 	log.Debugf("Enter writeFunctionalTestMarkdown with results: %+v", results)
@@ -627,6 +1211,11 @@ func (g *Generator) writeFunctionalTestMarkdown(file *os.File, results []validat
 				if _, err := fmt.Fprintf(file, "- Response Time: %s\n", endpoint.ResponseTime); err != nil {
 					return fmt.Errorf("failed to write response time: %w", err)
 				}
+				if endpoint.Impact != "" {
+					if _, err := fmt.Fprintf(file, "- Reported as warning: %s\n", endpoint.Impact); err != nil {
+						return fmt.Errorf("failed to write impact: %w", err)
+					}
+				}
 				if len(endpoint.Errors) > 0 {
 					if _, err := fmt.Fprintf(file, "- Errors:\n"); err != nil {
 						return fmt.Errorf("failed to write errors header: %w", err)
@@ -647,6 +1236,132 @@ func (g *Generator) writeFunctionalTestMarkdown(file *os.File, results []validat
 	return nil
 }
 
+// writeFuzzMarkdown writes a fuzzing report in Markdown format
+func (g *Generator) writeFuzzMarkdown(file *os.File, results *validation.FuzzTestResults) error {
+	log.Debugf("Enter writeFuzzMarkdown with results: %+v", results)
+	if _, err := fmt.Fprintf(file, `# Fuzz Test Report
+
+## Summary
+
+- Seed: %d
+- Requests: %d
+- Operations: %d
+- Duration: %s
+- Unique Failures: %d
+
+Re-run with `+"`--seed %d`"+` to replay the same inputs.
+
+`,
+		results.Seed,
+		results.Iterations,
+		results.Operations,
+		results.Duration,
+		len(results.Failures),
+		results.Seed); err != nil {
+		return fmt.Errorf("failed to write fuzz report header: %w", err)
+	}
+
+	if len(results.Failures) == 0 {
+		if _, err := fmt.Fprintf(file, "✅ No failures found.\n"); err != nil {
+			return fmt.Errorf("failed to write fuzz result: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(file, "## Failures\n\n"); err != nil {
+		return fmt.Errorf("failed to write failures header: %w", err)
+	}
+	for i, failure := range results.Failures {
+		if _, err := fmt.Fprintf(file, "### ❌ %s %s — %s\n\n", failure.Method, failure.Path, failure.Kind); err != nil {
+			return fmt.Errorf("failed to write failure header: %w", err)
+		}
+		if _, err := fmt.Fprintf(file, "- Error: %s\n- Status Code: %d\n- Occurrences: %d\n- First Seen: iteration %d\n- Shrink Steps: %d\n- Reproducer: `fuzz-reproducers/%s`\n\n",
+			failure.Error, failure.StatusCode, failure.Occurrences, failure.Iteration, failure.ShrinkSteps, reproducerFileName(i+1, failure)); err != nil {
+			return fmt.Errorf("failed to write failure details: %w", err)
+		}
+		if failure.Curl != "" {
+			if _, err := fmt.Fprintf(file, "```sh\n%s\n```\n\n", failure.Curl); err != nil {
+				return fmt.Errorf("failed to write reproducer command: %w", err)
+			}
+		}
+	}
+
+	log.Debugf("Returning from writeFuzzMarkdown with nil")
+	return nil
+}
+
+// writeDiffMarkdown writes a spec comparison report in Markdown format
+func (g *Generator) writeDiffMarkdown(file *os.File, diff *validation.SpecDiff) error {
+	log.Debugf("Enter writeDiffMarkdown with diff: %+v", diff)
+	status := "✅"
+	if !diff.VersionOK {
+		status = "❌"
+	}
+	if _, err := fmt.Fprintf(file, `# API Change Report
+
+## Summary
+
+- Baseline Version: %s
+- Candidate Version: %s
+- Breaking Changes: %d
+- Non-Breaking Changes: %d
+- Deprecations: %d
+- Required Version Bump: %s
+- Actual Version Bump: %s
+
+%s %s
+
+> %s
+
+`,
+		diff.BaseVersion,
+		diff.CandidateVersion,
+		diff.Breaking,
+		diff.NonBreaking,
+		diff.Deprecations,
+		diff.RequiredBump,
+		diff.ActualBump,
+		status,
+		diff.VersionMessage,
+		validation.DeprecationPolicy); err != nil {
+		return fmt.Errorf("failed to write diff report header: %w", err)
+	}
+
+	sections := []struct {
+		severity validation.ChangeSeverity
+		title    string
+	}{
+		{validation.ChangeBreaking, "❌ Breaking Changes"},
+		{validation.ChangeDeprecation, "⚠️ Deprecations"},
+		{validation.ChangeNonBreaking, "✅ Non-Breaking Changes"},
+	}
+	for _, section := range sections {
+		var changes []validation.SpecChange
+		for _, change := range diff.Changes {
+			if change.Severity == section.severity {
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(file, "## %s (%d)\n\n| Operation | Location | Change |\n|-----------|----------|--------|\n", section.title, len(changes)); err != nil {
+			return fmt.Errorf("failed to write changes header: %w", err)
+		}
+		for _, change := range changes {
+			if _, err := fmt.Fprintf(file, "| %s | %s | %s |\n", change.Operation, change.Location, change.Message); err != nil {
+				return fmt.Errorf("failed to write change: %w", err)
+			}
+		}
+		if _, err := fmt.Fprintf(file, "\n"); err != nil {
+			return fmt.Errorf("failed to write changes separator: %w", err)
+		}
+	}
+
+	log.Debugf("Returning from writeDiffMarkdown with nil")
+	return nil
+}
+
 // countStatus counts the number of endpoints with a given status
 func countStatus(results []validation.EndpointValidation, status string) int {
 	count := 0
@@ -0,0 +1,208 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meter-peter/driveby/internal/validation"
+)
+
+// SARIF 2.1.0 schema and version written to SARIF reports
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifLog is the root of a SARIF report
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun holds the results of one driveby run
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+// sarifRule describes a principle that spec findings are reported under
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	FullDescription      sarifMessage           `json:"fullDescription"`
+	DefaultConfiguration sarifRuleConfig        `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifResult is a single spec finding
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLogicalLocation names the spec element a finding is about by its JSON pointer
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevel maps a principle severity to a SARIF level
+func sarifLevel(severity string) string {
+	switch severity {
+	case "critical", "error":
+		return "error"
+	case "warning":
+		return "warning"
+	}
+	return "note"
+}
+
+// writeSARIF writes the spec findings of a report as SARIF, located in the spec file
+func (g *Generator) writeSARIF(path string, result *validation.ValidationReport, specPath string) error {
+	// Principles registered besides the core ones are known from their results
+	principles := append([]validation.Principle(nil), validation.CorePrinciples...)
+	for _, principleResult := range result.Principles {
+		principles = append(principles, principleResult.Principle)
+	}
+
+	rules := []sarifRule{}
+	ruleIndex := make(map[string]int)
+	for _, principle := range principles {
+		if _, ok := ruleIndex[principle.ID]; ok || !validation.IsSpecPrinciple(principle.ID) {
+			continue
+		}
+		ruleIndex[principle.ID] = len(rules)
+		rules = append(rules, sarifRule{
+			ID:                   principle.ID,
+			Name:                 sarifRuleName(principle.Name),
+			ShortDescription:     sarifMessage{Text: principle.Name},
+			FullDescription:      sarifMessage{Text: principle.Description},
+			DefaultConfiguration: sarifRuleConfig{Level: sarifLevel(principle.Severity)},
+			Properties:           map[string]interface{}{"category": principle.Category, "tags": principle.Tags},
+		})
+	}
+
+	uri := sarifURI(specPath)
+	results := []sarifResult{}
+	for _, finding := range validation.SpecFindings(result) {
+		index, ok := ruleIndex[finding.PrincipleID]
+		if !ok {
+			continue
+		}
+		// Findings whose position is unknown are reported on the first line
+		region := sarifRegion{StartLine: 1}
+		if finding.Line > 0 {
+			region = sarifRegion{StartLine: finding.Line, StartColumn: finding.Column}
+		}
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: uri},
+				Region:           region,
+			},
+		}
+		if finding.Pointer != "" {
+			location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: finding.Pointer, Kind: "object"}}
+		}
+		results = append(results, sarifResult{
+			RuleID:    finding.PrincipleID,
+			RuleIndex: index,
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	report := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "driveby",
+				InformationURI: "https://github.com/meter-peter/driveby",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+	return nil
+}
+
+// sarifRuleName turns a principle name into a PascalCase rule name
+func sarifRuleName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// sarifURI returns the spec location as a URI relative to the working directory when
+// possible, which is how code scanning matches results to repository files
+func sarifURI(specPath string) string {
+	if strings.Contains(specPath, "://") {
+		return specPath
+	}
+	if abs, err := filepath.Abs(specPath); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				return filepath.ToSlash(rel)
+			}
+		}
+		return "file://" + filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(specPath)
+}
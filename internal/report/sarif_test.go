@@ -0,0 +1,122 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/meter-peter/driveby/internal/validation"
+)
+
+func TestSARIFLevel(t *testing.T) {
+	tests := []struct {
+		severity string
+		want     string
+	}{
+		{severity: "critical", want: "error"},
+		{severity: "error", want: "error"},
+		{severity: "warning", want: "warning"},
+		{severity: "info", want: "note"},
+		{severity: "", want: "note"},
+	}
+	for _, tt := range tests {
+		if got := sarifLevel(tt.severity); got != tt.want {
+			t.Errorf("sarifLevel(%q) = %q, want %q", tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestSARIFRuleName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Documentation completeness", want: "DocumentationCompleteness"},
+		{name: "Error responses (4xx/5xx)", want: "ErrorResponses4xx5xx"},
+		{name: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := sarifRuleName(tt.name); got != tt.want {
+			t.Errorf("sarifRuleName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	spec := validation.CorePrinciples[1]
+	latency := validation.CorePrinciples[6]
+	result := &validation.ValidationReport{Principles: []validation.PrincipleResult{
+		{
+			Principle: spec,
+			Message:   "Operations are missing responses",
+			Findings: []validation.Finding{
+				{PrincipleID: spec.ID, Severity: "critical", Message: "GET /tasks has no 400 response", Pointer: "/paths/~1tasks/get", Line: 12, Column: 5},
+				{PrincipleID: spec.ID, Severity: "warning", Message: "The spec has no servers"},
+			},
+		},
+		{Principle: latency, Message: "P95 latency is too high"},
+	}}
+
+	path := filepath.Join(t.TempDir(), "report.sarif")
+	if err := NewGenerator(t.TempDir()).writeSARIF(path, result, "openapi.yaml"); err != nil {
+		t.Fatalf("writeSARIF: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("report has version %q and %d runs, want %q and 1", log.Version, len(log.Runs), sarifVersion)
+	}
+	run := log.Runs[0]
+
+	var ruleIDs []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	if slices.Contains(ruleIDs, latency.ID) || !slices.Contains(ruleIDs, spec.ID) {
+		t.Errorf("rules are %v, want %s and no %s", ruleIDs, spec.ID, latency.ID)
+	}
+
+	if len(run.Results) != 2 {
+		t.Fatalf("report has %d results, want 2", len(run.Results))
+	}
+	tests := []struct {
+		level   string
+		line    int
+		column  int
+		pointer string
+	}{
+		{level: "error", line: 12, column: 5, pointer: "/paths/~1tasks/get"},
+		{level: "warning", line: 1},
+	}
+	for i, tt := range tests {
+		got := run.Results[i]
+		if got.RuleID != spec.ID || ruleIDs[got.RuleIndex] != spec.ID {
+			t.Errorf("result %d is for rule %s at index %d, want %s", i, got.RuleID, got.RuleIndex, spec.ID)
+		}
+		if got.Level != tt.level {
+			t.Errorf("result %d has level %q, want %q", i, got.Level, tt.level)
+		}
+		location := got.Locations[0]
+		if location.PhysicalLocation.ArtifactLocation.URI != "openapi.yaml" {
+			t.Errorf("result %d is in %q, want openapi.yaml", i, location.PhysicalLocation.ArtifactLocation.URI)
+		}
+		if region := location.PhysicalLocation.Region; region.StartLine != tt.line || region.StartColumn != tt.column {
+			t.Errorf("result %d is at %d:%d, want %d:%d", i, region.StartLine, region.StartColumn, tt.line, tt.column)
+		}
+		var pointer string
+		if len(location.LogicalLocations) > 0 {
+			pointer = location.LogicalLocations[0].FullyQualifiedName
+		}
+		if pointer != tt.pointer {
+			t.Errorf("result %d points at %q, want %q", i, pointer, tt.pointer)
+		}
+	}
+}
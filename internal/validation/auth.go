@@ -0,0 +1,210 @@
+package validation
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// authorizeRequest adds the configured credentials to a request as the operation's
+// security requirements ask for them, with the current token of the OAuth2 provider
+// taking the place of a static token. The first requirement whose schemes all have
+// credentials is used; operations declared public with an empty requirement are sent
+// without credentials. When the spec declares no security for the operation, or none of
+// its requirements can be met, the configured auth method is applied as is.
+func authorizeRequest(auth *AuthConfig, doc *openapi3.T, operation *openapi3.Operation, req *http.Request) error {
+	if auth == nil {
+		return nil
+	}
+	requirements, declared := operationSecurity(doc, operation)
+	if declared && len(requirements) == 0 {
+		return nil
+	}
+	if auth.OAuth2 != nil {
+		token, err := auth.OAuth2.Token(req.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get OAuth2 token: %w", err)
+		}
+		withToken := *auth
+		withToken.Token = token
+		auth = &withToken
+	}
+	if !declared {
+		return applyAuthConfig(auth, req)
+	}
+	for _, requirement := range requirements {
+		if applySecurityRequirement(auth, doc, requirement, req) {
+			return nil
+		}
+	}
+	return applyAuthConfig(auth, req)
+}
+
+// operationSecurity returns the security requirements of an operation, its own or the
+// document's, and whether any are declared. An empty declared list makes it public.
+func operationSecurity(doc *openapi3.T, operation *openapi3.Operation) (openapi3.SecurityRequirements, bool) {
+	if operation != nil && operation.Security != nil {
+		return *operation.Security, true
+	}
+	if doc != nil && doc.Security != nil {
+		return doc.Security, true
+	}
+	return nil, false
+}
+
+// applySecurityRequirement applies every scheme of a requirement, and reports false
+// without changing the request when a scheme has no credentials
+func applySecurityRequirement(auth *AuthConfig, doc *openapi3.T, requirement openapi3.SecurityRequirement, req *http.Request) bool {
+	if doc == nil || doc.Components == nil {
+		return false
+	}
+	names := make([]string, 0, len(requirement))
+	for name := range requirement {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var steps []func(*http.Request)
+	for _, name := range names {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref == nil || ref.Value == nil {
+			return false
+		}
+		step, ok := securitySchemeCredential(auth, name, ref.Value)
+		if !ok {
+			return false
+		}
+		steps = append(steps, step)
+	}
+	for _, step := range steps {
+		step(req)
+	}
+	return true
+}
+
+// securitySchemeCredential returns how the credential for a security scheme is added to
+// a request. Credentials given for the scheme by name take precedence over the token,
+// API key or username that match its type.
+func securitySchemeCredential(auth *AuthConfig, name string, scheme *openapi3.SecurityScheme) (func(*http.Request), bool) {
+	credential, named := auth.Schemes[name]
+	switch scheme.Type {
+	case "http":
+		switch strings.ToLower(scheme.Scheme) {
+		case "bearer":
+			token := auth.Token
+			if named {
+				token = credential
+			}
+			if token == "" {
+				return nil, false
+			}
+			return func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}, true
+		case "basic":
+			username, password := auth.Username, auth.Password
+			if named {
+				username, password, _ = strings.Cut(credential, ":")
+			}
+			if username == "" {
+				return nil, false
+			}
+			return func(req *http.Request) {
+				req.SetBasicAuth(username, password)
+			}, true
+		default:
+			// Other HTTP schemes, such as digest, only work with a credential given for them
+			if !named {
+				return nil, false
+			}
+			return func(req *http.Request) {
+				req.Header.Set("Authorization", scheme.Scheme+" "+credential)
+			}, true
+		}
+	case "apiKey":
+		key := auth.APIKey
+		if named {
+			key = credential
+		}
+		if key == "" || scheme.Name == "" {
+			return nil, false
+		}
+		switch scheme.In {
+		case "header":
+			return func(req *http.Request) {
+				req.Header.Set(scheme.Name, key)
+			}, true
+		case "query":
+			return func(req *http.Request) {
+				query := req.URL.Query()
+				query.Set(scheme.Name, key)
+				req.URL.RawQuery = query.Encode()
+			}, true
+		case "cookie":
+			return func(req *http.Request) {
+				req.AddCookie(&http.Cookie{Name: scheme.Name, Value: key})
+			}, true
+		}
+	case "oauth2", "openIdConnect":
+		token := auth.Token
+		if named {
+			token = credential
+		}
+		if token == "" {
+			return nil, false
+		}
+		return func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}, true
+	}
+	return nil, false
+}
+
+// applyAuthConfig adds the headers of the configured auth method to a request. When
+// several methods are configured, the token is preferred over the API key and the
+// API key over basic auth.
+func applyAuthConfig(auth *AuthConfig, req *http.Request) error {
+	if auth == nil {
+		return nil
+	}
+
+	// Add the appropriate auth header
+	if auth.Token != "" {
+		headerName := auth.TokenHeader
+		if headerName == "" {
+			headerName = "Authorization"
+		}
+		tokenType := auth.TokenType
+		if tokenType == "" {
+			tokenType = "Bearer"
+		}
+		req.Header.Set(headerName, fmt.Sprintf("%s %s", tokenType, auth.Token))
+	} else if auth.APIKey != "" {
+		headerName := auth.APIKeyHeader
+		if headerName == "" {
+			headerName = "X-API-Key"
+		}
+		req.Header.Set(headerName, auth.APIKey)
+	} else if auth.Username != "" {
+		// Basic auth
+		credentials := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", auth.Username, auth.Password)))
+		req.Header.Set("Authorization", fmt.Sprintf("Basic %s", credentials))
+	}
+
+	return nil
+}
+
+// ParseAuthScheme parses a credential for a named security scheme, given as
+// "name=value"; basic auth credentials are given as "name=username:password"
+func ParseAuthScheme(value string) (string, string, error) {
+	name, credential, ok := strings.Cut(value, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || credential == "" {
+		return "", "", fmt.Errorf("invalid auth scheme credential %q (expected name=value)", value)
+	}
+	return name, credential, nil
+}
@@ -0,0 +1,306 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Credentials sent by the malformed credentials test; no API should accept them
+var malformedAuth = &AuthConfig{
+	Token:    "driveby-malformed-token",
+	APIKey:   "driveby-malformed-key",
+	Username: "driveby-malformed-user",
+	Password: "driveby-malformed-password",
+}
+
+// ScopedCredential is a token known to be granted a set of OAuth2 scopes, used to check
+// that operations enforce the scopes their security requirements name
+type ScopedCredential struct {
+	Scopes []string
+	Token  string          // Static token
+	OAuth2 *OAuth2Provider // Used instead of Token: fetches tokens granted Scopes
+}
+
+// runAuthTests checks that every secured operation rejects requests without credentials
+// and with malformed credentials with a 401 or 403, and accepts the configured ones. With
+// scoped credentials configured, operations whose requirements name OAuth2 scopes must
+// accept tokens granted those scopes and reject the others. The configured credentials
+// are checked against the endpoint test's response rather than a request of their own.
+// Test cases are returned keyed by "METHOD path".
+func (t *FunctionalTester) runAuthTests(ctx context.Context, doc *openapi3.T, endpoints []EndpointValidation) map[string][]TestCaseResult {
+	responses := make(map[string]EndpointValidation, len(endpoints))
+	for _, endpoint := range endpoints {
+		responses[endpoint.Method+" "+endpoint.Path] = endpoint
+	}
+
+	results := make(map[string][]TestCaseResult)
+	for path, pathItem := range doc.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			if operation.Deprecated {
+				continue
+			}
+			if !hasSecurityRequirement(doc, operation) {
+				continue
+			}
+			key := method + " " + path
+			if reason, ok := t.impact.skipReason(key, TestKindAuth); ok {
+				results[key] = append(results[key], TestCaseResult{
+					Name:        "auth tests",
+					Status:      TestStatusSkipped,
+					Description: "Authentication enforcement was not checked",
+					Error:       reason,
+				})
+				continue
+			}
+
+			input := synthesizeRequest(pathItem, operation)
+			anonymous := t.executeOperationAs(ctx, nil, method, path, operation, input)
+			results[key] = append(results[key], rejectionTestCase("auth: no credentials",
+				"Secured operation called without credentials should answer 401 or 403", input, anonymous))
+			malformed := t.executeOperationAs(ctx, malformedAuth, method, path, operation, input)
+			results[key] = append(results[key], rejectionTestCase("auth: malformed credentials",
+				"Secured operation called with a malformed token, key or password should answer 401 or 403", input, malformed))
+			results[key] = append(results[key], t.acceptanceTestCase(responses[key]))
+
+			if scopes := requiredScopes(doc, operation); len(scopes) > 0 {
+				for _, credential := range t.scopedCredentials() {
+					results[key] = append(results[key], t.scopeTestCase(ctx, method, path, operation, input, scopes, credential))
+				}
+			}
+		}
+	}
+	return results
+}
+
+// authPrincipleResult reports the failed authentication tests under P005, so that they
+// show up in reports and run history next to the spec's security checks. It returns
+// false when no authentication test failed.
+func (t *FunctionalTester) authPrincipleResult(results map[string][]TestCaseResult) (PrincipleResult, bool) {
+	var failures []string
+	var findings []Finding
+	for _, key := range sortedKeys(results) {
+		method, path, _ := strings.Cut(key, " ")
+		for _, tc := range results[key] {
+			if tc.Status != TestStatusFailed {
+				continue
+			}
+			failure := fmt.Sprintf("%s: %s: %s", key, tc.Name, tc.Error)
+			failures = append(failures, failure)
+			findings = append(findings, Finding{
+				PrincipleID: CorePrinciples[4].ID,
+				Severity:    CorePrinciples[4].Severity,
+				Message:     fmt.Sprintf("Authentication test failed: %s", failure),
+				Pointer:     specPointer("paths", path, strings.ToLower(method)),
+			})
+		}
+	}
+	if len(failures) == 0 {
+		return PrincipleResult{}, false
+	}
+	return PrincipleResult{
+		Principle:    CorePrinciples[4], // P005
+		Passed:       false,
+		Message:      fmt.Sprintf("%d authentication tests failed against the running API", len(failures)),
+		Details:      failures,
+		Findings:     locateFindings(t.loader, findings),
+		SuggestedFix: "Answer 401 or 403 to requests without valid credentials or the required OAuth2 scopes",
+	}, true
+}
+
+// rejectionTestCase evaluates the response to a request with missing or bad credentials
+func rejectionTestCase(name, description string, input *RequestInput, validation EndpointValidation) TestCaseResult {
+	tc := TestCaseResult{
+		Name:        name,
+		Description: description,
+		Input:       input,
+		Expected:    []string{"401", "403"},
+		Actual:      validation.StatusCode,
+	}
+	code := validation.StatusCode
+	switch {
+	case code == 0:
+		tc.Status = TestStatusFailed
+		tc.Error = strings.Join(validation.Errors, "; ")
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		tc.Status = TestStatusPassed
+	case code >= http.StatusOK && code < http.StatusMultipleChoices:
+		tc.Status = TestStatusFailed
+		tc.Error = fmt.Sprintf("operation is secured in the spec but answered %d without valid credentials", code)
+	case code >= http.StatusInternalServerError:
+		tc.Status = TestStatusFailed
+		tc.Error = fmt.Sprintf("request without valid credentials caused a server error (status %d)", code)
+	default:
+		// Some APIs hide resources with a 404 or validate the request before authenticating
+		tc.Status = TestStatusWarning
+		tc.Error = fmt.Sprintf("expected 401 or 403 without valid credentials, got %d", code)
+	}
+	return tc
+}
+
+// acceptanceTestCase checks that the configured credentials were accepted by the
+// endpoint test
+func (t *FunctionalTester) acceptanceTestCase(validation EndpointValidation) TestCaseResult {
+	tc := TestCaseResult{
+		Name:        "auth: valid credentials",
+		Description: "Secured operation called with the configured credentials should not answer 401 or 403",
+		Input:       validation.Request,
+		Actual:      validation.StatusCode,
+	}
+	code := validation.StatusCode
+	switch {
+	case t.config.Auth == nil:
+		tc.Status = TestStatusSkipped
+		tc.Error = "no credentials are configured"
+	case code == 0:
+		tc.Status = TestStatusSkipped
+		tc.Error = "the endpoint test got no response"
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		tc.Status = TestStatusFailed
+		tc.Error = fmt.Sprintf("configured credentials were rejected with %d", code)
+	default:
+		tc.Status = TestStatusPassed
+	}
+	return tc
+}
+
+// scopeTestCase sends a request with a scoped token and checks that the operation
+// accepts it exactly when the token is granted the scopes of one of its requirements
+func (t *FunctionalTester) scopeTestCase(ctx context.Context, method, path string, operation *openapi3.Operation, input *RequestInput, required [][]string, credential ScopedCredential) TestCaseResult {
+	tc := TestCaseResult{
+		Name:        fmt.Sprintf("auth: scopes [%s]", strings.Join(credential.Scopes, " ")),
+		Description: fmt.Sprintf("Operation requiring scopes %s should accept a token only when it is granted them", formatScopeAlternatives(required)),
+		Input:       input,
+	}
+	sufficient := false
+	for _, scopes := range required {
+		if containsAll(credential.Scopes, scopes) {
+			sufficient = true
+			break
+		}
+	}
+
+	validation := t.executeOperationAs(ctx, t.scopedAuth(credential), method, path, operation, input)
+	code := validation.StatusCode
+	tc.Actual = code
+	rejected := code == http.StatusUnauthorized || code == http.StatusForbidden
+	switch {
+	case code == 0:
+		tc.Status = TestStatusFailed
+		tc.Error = strings.Join(validation.Errors, "; ")
+	case sufficient && rejected:
+		tc.Status = TestStatusFailed
+		tc.Error = fmt.Sprintf("token granted the required scopes was rejected with %d", code)
+	case !sufficient && code >= http.StatusOK && code < http.StatusMultipleChoices:
+		tc.Status = TestStatusFailed
+		tc.Error = fmt.Sprintf("token without the required scopes was accepted with %d", code)
+	case !sufficient && !rejected:
+		tc.Status = TestStatusWarning
+		tc.Error = fmt.Sprintf("expected 401 or 403 for a token without the required scopes, got %d", code)
+	default:
+		tc.Status = TestStatusPassed
+	}
+	if sufficient {
+		tc.Expected = "accepted"
+	} else {
+		tc.Expected = []string{"401", "403"}
+	}
+	return tc
+}
+
+// scopedAuth returns the configured credentials with the scoped token in place of the
+// token of bearer, OAuth2 and OpenID Connect schemes
+func (t *FunctionalTester) scopedAuth(credential ScopedCredential) *AuthConfig {
+	auth := *t.config.Auth
+	auth.Token = credential.Token
+	auth.OAuth2 = credential.OAuth2
+	auth.ScopedCredentials = nil
+	auth.Schemes = nil
+	if doc := t.loader.GetDocument(); doc != nil && doc.Components != nil {
+		for name, credential := range t.config.Auth.Schemes {
+			if ref := doc.Components.SecuritySchemes[name]; ref != nil && ref.Value != nil && ref.Value.Type == "apiKey" {
+				if auth.Schemes == nil {
+					auth.Schemes = make(map[string]string)
+				}
+				auth.Schemes[name] = credential
+			}
+		}
+	}
+	return &auth
+}
+
+// scopedCredentials returns the configured scoped credentials
+func (t *FunctionalTester) scopedCredentials() []ScopedCredential {
+	if t.config.Auth == nil {
+		return nil
+	}
+	return t.config.Auth.ScopedCredentials
+}
+
+// requiredScopes returns, for each alternative security requirement of an operation
+// that names OAuth2 or OpenID Connect scopes, the scopes it requires. Operations that
+// can be called without scopes have none.
+func requiredScopes(doc *openapi3.T, operation *openapi3.Operation) [][]string {
+	requirements, _ := operationSecurity(doc, operation)
+	var alternatives [][]string
+	for _, requirement := range requirements {
+		var scopes []string
+		for name, names := range requirement {
+			if doc.Components == nil || doc.Components.SecuritySchemes[name] == nil || doc.Components.SecuritySchemes[name].Value == nil {
+				continue
+			}
+			switch doc.Components.SecuritySchemes[name].Value.Type {
+			case "oauth2", "openIdConnect":
+				scopes = append(scopes, names...)
+			}
+		}
+		if len(scopes) == 0 {
+			return nil
+		}
+		sort.Strings(scopes)
+		alternatives = append(alternatives, scopes)
+	}
+	return alternatives
+}
+
+// containsAll reports whether granted includes every required scope
+func containsAll(granted, required []string) bool {
+	for _, scope := range required {
+		found := false
+		for _, g := range granted {
+			if g == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// formatScopeAlternatives describes alternative scope sets, e.g. "[a b] or [c]"
+func formatScopeAlternatives(alternatives [][]string) string {
+	parts := make([]string, len(alternatives))
+	for i, scopes := range alternatives {
+		parts[i] = "[" + strings.Join(scopes, " ") + "]"
+	}
+	return strings.Join(parts, " or ")
+}
+
+// ParseScopedCredential parses a scoped credential given as "<scopes>=<token>", with the
+// scopes separated by "+" or spaces, or as "<scopes>" alone when tokens with those scopes
+// are fetched from the OAuth2 token endpoint
+func ParseScopedCredential(value string) ([]string, string, error) {
+	scopes, token, _ := strings.Cut(value, "=")
+	fields := strings.FieldsFunc(scopes, func(r rune) bool { return r == '+' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, "", fmt.Errorf("invalid scoped credential %q (expected \"scope+scope=token\" or \"scope+scope\")", value)
+	}
+	return fields, strings.TrimSpace(token), nil
+}
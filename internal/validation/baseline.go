@@ -0,0 +1,241 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// BaselineFileName is the name of the performance baseline store in the report directory
+const BaselineFileName = "perf-baselines.json"
+
+// BaselineConfig holds configuration for comparing load tests with earlier runs
+type BaselineConfig struct {
+	Path                string        // Baseline store file
+	Version             string        // Version whose baseline is compared; defaults to the latest of the environment
+	P95Tolerance        float64       // Allowed relative increase of P95 latency, e.g. 0.1 for 10%
+	ThroughputTolerance float64       // Allowed relative decrease of throughput
+	MinP95Increase      time.Duration // P95 increases below this are noise, whatever the tolerance
+	Update              bool          // Record passing runs as the baseline of their environment and version
+}
+
+// Baseline is the recorded performance of an environment and version
+type Baseline struct {
+	Environment string              `json:"environment"`
+	Version     string              `json:"version"`
+	RecordedAt  time.Time           `json:"recorded_at"`
+	LoadShape   string              `json:"load_shape"` // Runs are only compared with baselines of the same load
+	Metrics     *PerformanceMetrics `json:"metrics"`
+}
+
+// BaselineStore keeps one baseline per environment, version and load shape
+type BaselineStore struct {
+	Baselines []Baseline `json:"baselines"`
+}
+
+// LoadBaselineStore reads a baseline store; a missing file is an empty store
+func LoadBaselineStore(path string) (*BaselineStore, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &BaselineStore{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline store: %w", err)
+	}
+	var store BaselineStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline store %s: %w", path, err)
+	}
+	return &store, nil
+}
+
+// Save writes the store, replacing the file only once it is complete
+func (s *BaselineStore) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create baseline directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline store: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write baseline store: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace baseline store: %w", err)
+	}
+	return nil
+}
+
+// Find returns the baseline of an environment and version recorded under a load shape.
+// Without a version, it returns the most recently recorded baseline of the environment.
+func (s *BaselineStore) Find(environment, version, loadShape string) *Baseline {
+	var found *Baseline
+	for i := range s.Baselines {
+		baseline := &s.Baselines[i]
+		if baseline.Environment != environment || baseline.LoadShape != loadShape {
+			continue
+		}
+		if version != "" {
+			if baseline.Version == version {
+				return baseline
+			}
+			continue
+		}
+		if found == nil || baseline.RecordedAt.After(found.RecordedAt) {
+			found = baseline
+		}
+	}
+	return found
+}
+
+// Record stores a baseline, replacing the one of the same environment, version and load shape
+func (s *BaselineStore) Record(baseline Baseline) {
+	for i := range s.Baselines {
+		existing := s.Baselines[i]
+		if existing.Environment == baseline.Environment && existing.Version == baseline.Version && existing.LoadShape == baseline.LoadShape {
+			s.Baselines[i] = baseline
+			return
+		}
+	}
+	s.Baselines = append(s.Baselines, baseline)
+	sort.Slice(s.Baselines, func(i, j int) bool {
+		if s.Baselines[i].Environment != s.Baselines[j].Environment {
+			return s.Baselines[i].Environment < s.Baselines[j].Environment
+		}
+		if s.Baselines[i].Version != s.Baselines[j].Version {
+			return s.Baselines[i].Version < s.Baselines[j].Version
+		}
+		return s.Baselines[i].LoadShape < s.Baselines[j].LoadShape
+	})
+}
+
+// loadShape describes the load a run was generated with. Runs under different load
+// are not comparable.
+func (c *PerformanceTargetConfig) loadShape() string {
+	switch {
+	case c.Model == LoadModelClosed:
+		return fmt.Sprintf("closed model, %d virtual users", c.ConcurrentUsers)
+	case c.Profile.Type == LoadProfileCapacity:
+		return "open model, capacity profile"
+	}
+	profile := c.Profile.Type
+	if profile == "" {
+		profile = LoadProfileConstant
+	}
+	return fmt.Sprintf("open model, %s profile up to %d/s", profile, c.loadRate())
+}
+
+// runThroughput returns the throughput a run is compared on: successful requests per
+// second, or the maximum sustainable rate of a capacity search
+func runThroughput(metrics *PerformanceMetrics) float64 {
+	if metrics.Capacity != nil {
+		return float64(metrics.Capacity.MaxSustainableRate)
+	}
+	return metrics.RequestsPerSec * (1 - metrics.ErrorRate)
+}
+
+// compareBaseline compares a run with a baseline and lists the regressions beyond the
+// configured tolerances
+func compareBaseline(current *PerformanceMetrics, baseline *Baseline, config *BaselineConfig) *BaselineComparison {
+	comparison := &BaselineComparison{
+		Environment:         baseline.Environment,
+		Version:             baseline.Version,
+		RecordedAt:          baseline.RecordedAt,
+		BaselineLatencyP95:  baseline.Metrics.LatencyP95,
+		LatencyP95:          current.LatencyP95,
+		LatencyP95Change:    relativeChange(float64(baseline.Metrics.LatencyP95), float64(current.LatencyP95)),
+		BaselineThroughput:  runThroughput(baseline.Metrics),
+		Throughput:          runThroughput(current),
+		P95Tolerance:        config.P95Tolerance,
+		ThroughputTolerance: config.ThroughputTolerance,
+	}
+	comparison.ThroughputChange = relativeChange(comparison.BaselineThroughput, comparison.Throughput)
+
+	if -comparison.ThroughputChange > config.ThroughputTolerance {
+		comparison.Regressions = append(comparison.Regressions, fmt.Sprintf("Throughput regressed by %.1f%% (%.2f/s to %.2f/s, tolerance %.1f%%)",
+			-comparison.ThroughputChange*100, comparison.BaselineThroughput, comparison.Throughput, config.ThroughputTolerance*100))
+	}
+	if current.Capacity != nil {
+		// A capacity search overloads the API on purpose, so only its rate is compared
+		return comparison
+	}
+	if latencyRegressed(comparison.BaselineLatencyP95, comparison.LatencyP95, config) {
+		comparison.Regressions = append(comparison.Regressions, fmt.Sprintf("P95 latency regressed by %.1f%% (%s to %s, tolerance %.1f%%)",
+			comparison.LatencyP95Change*100, comparison.BaselineLatencyP95, comparison.LatencyP95, config.P95Tolerance*100))
+	}
+
+	// Operations measured in both runs are held to the P95 tolerance too
+	previous := make(map[string]EndpointMetrics, len(baseline.Metrics.Endpoints))
+	for _, endpoint := range baseline.Metrics.Endpoints {
+		previous[endpoint.Method+" "+endpoint.Path] = endpoint
+	}
+	for _, endpoint := range current.Endpoints {
+		before, ok := previous[endpoint.Method+" "+endpoint.Path]
+		if !ok || before.TotalRequests == 0 || endpoint.TotalRequests == 0 {
+			continue
+		}
+		if latencyRegressed(before.LatencyP95, endpoint.LatencyP95, config) {
+			comparison.Regressions = append(comparison.Regressions, fmt.Sprintf("%s %s: P95 latency regressed by %.1f%% (%s to %s)",
+				endpoint.Method, endpoint.Path, relativeChange(float64(before.LatencyP95), float64(endpoint.LatencyP95))*100,
+				before.LatencyP95, endpoint.LatencyP95))
+		}
+	}
+	return comparison
+}
+
+// latencyRegressed reports whether a latency grew beyond both the relative tolerance
+// and the noise floor
+func latencyRegressed(before, after time.Duration, config *BaselineConfig) bool {
+	return after-before > config.MinP95Increase && relativeChange(float64(before), float64(after)) > config.P95Tolerance
+}
+
+// relativeChange returns the change from before to after as a fraction of before
+func relativeChange(before, after float64) float64 {
+	if before == 0 {
+		return 0
+	}
+	return (after - before) / before
+}
+
+// checkBaseline compares a finished run with the configured baseline and, when the run
+// passed, records it as the baseline of its environment and version
+func (t *PerformanceTester) checkBaseline(metrics *PerformanceMetrics, passed bool) (*BaselineComparison, error) {
+	config := t.config.PerformanceTarget.Baseline
+	store, err := LoadBaselineStore(config.Path)
+	if err != nil {
+		return nil, err
+	}
+	shape := t.config.PerformanceTarget.loadShape()
+
+	var comparison *BaselineComparison
+	if baseline := store.Find(t.config.Environment, config.Version, shape); baseline != nil {
+		comparison = compareBaseline(metrics, baseline, config)
+	} else if config.Version != "" {
+		comparison = &BaselineComparison{Skipped: fmt.Sprintf("no baseline recorded for version %s in environment %q under this load (%s)",
+			config.Version, t.config.Environment, shape)}
+	} else {
+		comparison = &BaselineComparison{Skipped: fmt.Sprintf("no baseline recorded for environment %q under this load (%s)",
+			t.config.Environment, shape)}
+	}
+
+	if config.Update && passed && len(comparison.Regressions) == 0 {
+		store.Record(Baseline{
+			Environment: t.config.Environment,
+			Version:     t.config.Version,
+			RecordedAt:  metrics.EndTime,
+			LoadShape:   shape,
+			Metrics:     metrics,
+		})
+		if err := store.Save(config.Path); err != nil {
+			return nil, err
+		}
+		comparison.Recorded = true
+	}
+	return comparison, nil
+}
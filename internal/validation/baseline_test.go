@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// baselineRun builds the metrics of a run with one operation
+func baselineRun(p95 time.Duration, rps float64, endpointP95 time.Duration) *PerformanceMetrics {
+	return &PerformanceMetrics{
+		LatencyP95:     p95,
+		RequestsPerSec: rps,
+		Endpoints: []EndpointMetrics{
+			{Method: "GET", Path: "/tasks", TotalRequests: 10, LatencyP95: endpointP95},
+		},
+	}
+}
+
+func TestCompareBaseline(t *testing.T) {
+	config := &BaselineConfig{P95Tolerance: 0.1, ThroughputTolerance: 0.1, MinP95Increase: 5 * time.Millisecond}
+	ms := time.Millisecond
+	capacityRun := func(rate int, p95 time.Duration) *PerformanceMetrics {
+		run := baselineRun(p95, float64(rate), p95)
+		run.Capacity = &CapacityResult{MaxSustainableRate: rate}
+		return run
+	}
+	tests := []struct {
+		name     string
+		baseline *PerformanceMetrics
+		current  *PerformanceMetrics
+		want     []string // Prefixes of the expected regressions
+	}{
+		{
+			name:     "within tolerances",
+			baseline: baselineRun(100*ms, 100, 100*ms),
+			current:  baselineRun(109*ms, 91, 109*ms),
+		},
+		{
+			name:     "latency regression",
+			baseline: baselineRun(100*ms, 100, 100*ms),
+			current:  baselineRun(150*ms, 100, 100*ms),
+			want:     []string{"P95 latency regressed by 50.0%"},
+		},
+		{
+			name:     "increase below the noise floor",
+			baseline: baselineRun(1*ms, 100, 1*ms),
+			current:  baselineRun(4*ms, 100, 4*ms),
+		},
+		{
+			name:     "throughput regression",
+			baseline: baselineRun(100*ms, 100, 100*ms),
+			current:  baselineRun(100*ms, 80, 100*ms),
+			want:     []string{"Throughput regressed by 20.0%"},
+		},
+		{
+			name:     "operation regression",
+			baseline: baselineRun(100*ms, 100, 100*ms),
+			current:  baselineRun(100*ms, 100, 200*ms),
+			want:     []string{"GET /tasks: P95 latency regressed by 100.0%"},
+		},
+		{
+			name:     "capacity compares the sustainable rate only",
+			baseline: capacityRun(100, 100*ms),
+			current:  capacityRun(80, 500*ms),
+			want:     []string{"Throughput regressed by 20.0%"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := compareBaseline(tt.current, &Baseline{Environment: "staging", Metrics: tt.baseline}, config)
+			if len(comparison.Regressions) != len(tt.want) {
+				t.Fatalf("regressions are %q, want %d", comparison.Regressions, len(tt.want))
+			}
+			for i, prefix := range tt.want {
+				if !strings.HasPrefix(comparison.Regressions[i], prefix) {
+					t.Errorf("regression %q does not start with %q", comparison.Regressions[i], prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestBaselineStoreFind(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	store := &BaselineStore{}
+	store.Record(Baseline{Environment: "staging", Version: "1.0.0", LoadShape: "a", RecordedAt: day(1)})
+	store.Record(Baseline{Environment: "staging", Version: "1.1.0", LoadShape: "a", RecordedAt: day(2)})
+	store.Record(Baseline{Environment: "staging", Version: "1.2.0", LoadShape: "b", RecordedAt: day(3)})
+	store.Record(Baseline{Environment: "production", Version: "1.1.0", LoadShape: "a", RecordedAt: day(4)})
+	// Recording the same environment, version and load again replaces the baseline
+	store.Record(Baseline{Environment: "staging", Version: "1.0.0", LoadShape: "a", RecordedAt: day(1).Add(time.Hour)})
+
+	tests := []struct {
+		name        string
+		environment string
+		version     string
+		shape       string
+		want        string // Version of the baseline found, empty for none
+	}{
+		{name: "latest of the environment and load", environment: "staging", shape: "a", want: "1.1.0"},
+		{name: "given version", environment: "staging", version: "1.0.0", shape: "a", want: "1.0.0"},
+		{name: "version under another load", environment: "staging", version: "1.2.0", shape: "a"},
+		{name: "unknown environment", environment: "dev", shape: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := store.Find(tt.environment, tt.version, tt.shape)
+			var got string
+			if found != nil {
+				got = found.Version
+			}
+			if got != tt.want {
+				t.Errorf("found version %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if len(store.Baselines) != 4 {
+		t.Errorf("store has %d baselines, want 4", len(store.Baselines))
+	}
+}
@@ -0,0 +1,179 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	vegeta "github.com/tsenart/vegeta/v12/lib"
+)
+
+// Defaults for the capacity search
+const (
+	defaultProbeDuration = 10 * time.Second
+	defaultMaxProbes     = 10
+	capacityResolution   = 0.05 // The search stops once the failing rate is within 5% of the passing one
+)
+
+// searchCapacity looks for the highest constant request rate at which the P95 latency
+// and success rate targets hold, probing the API at each rate. Every request is passed
+// to record.
+func (t *PerformanceTester) searchCapacity(ctx context.Context, targets []*loadTarget, record func(*vegeta.Result)) (*CapacityResult, error) {
+	config := t.config.PerformanceTarget
+	profile := config.Profile
+	probeDuration := profile.ProbeDuration
+	if probeDuration == 0 {
+		probeDuration = defaultProbeDuration
+	}
+	maxProbes := profile.MaxProbes
+	if maxProbes == 0 {
+		maxProbes = defaultMaxProbes
+	}
+	start := profile.StartRate
+	if start == 0 {
+		start = int(math.Max(1, math.Floor(float64(config.loadRate())/defaultStartRateRatio)))
+	}
+	return bisectCapacity(start, config.Rate, maxProbes, func(rate int) (CapacityProbe, error) {
+		return t.probeRate(ctx, targets, rate, probeDuration, record)
+	})
+}
+
+// bisectCapacity runs the capacity search with the given probe. The rate doubles from
+// the start rate until a probe fails or the ceiling (when greater than 0) is reached,
+// then a binary search narrows the gap between the last passing and the first failing
+// rate. At most maxProbes probes run.
+func bisectCapacity(start, ceiling, maxProbes int, run func(rate int) (CapacityProbe, error)) (*CapacityResult, error) {
+	rate := start
+	if ceiling > 0 && rate > ceiling {
+		rate = ceiling
+	}
+
+	result := &CapacityResult{}
+	probes := make(map[int]CapacityProbe)
+	probe := func(rate int) (CapacityProbe, error) {
+		p, err := run(rate)
+		if err != nil {
+			return p, err
+		}
+		probes[rate] = p
+		return p, nil
+	}
+
+	// Grow the rate until a probe fails
+	passing, failing := 0, 0
+	for len(probes) < maxProbes {
+		p, err := probe(rate)
+		if err != nil {
+			return nil, err
+		}
+		if !p.Passed {
+			failing = rate
+			break
+		}
+		passing = rate
+		if ceiling > 0 && rate >= ceiling {
+			break
+		}
+		rate *= 2
+		if ceiling > 0 && rate > ceiling {
+			rate = ceiling
+		}
+	}
+
+	// Narrow the gap between the last passing and the first failing rate
+	for failing > 0 && len(probes) < maxProbes {
+		gap := int(math.Max(1, math.Floor(float64(passing)*capacityResolution)))
+		if failing-passing <= gap {
+			break
+		}
+		mid := (passing + failing) / 2
+		p, err := probe(mid)
+		if err != nil {
+			return nil, err
+		}
+		if p.Passed {
+			passing = mid
+		} else {
+			failing = mid
+		}
+	}
+
+	result.MaxSustainableRate = passing
+	if failing > 0 {
+		result.BreakingPoint = failing
+		result.BreakingReason = probes[failing].Reason
+	}
+	result.CeilingReached = failing == 0 && ceiling > 0 && passing >= ceiling
+	for _, p := range probes {
+		result.Curve = append(result.Curve, p)
+	}
+	sort.Slice(result.Curve, func(i, j int) bool { return result.Curve[i].Rate < result.Curve[j].Rate })
+	return result, nil
+}
+
+// probeRate runs a constant-rate attack and checks it against the latency and success
+// rate targets
+func (t *PerformanceTester) probeRate(ctx context.Context, targets []*loadTarget, rate int, duration time.Duration, record func(*vegeta.Result)) (CapacityProbe, error) {
+	metrics := &vegeta.Metrics{}
+	attacker := vegeta.NewAttacker()
+	pacer := vegeta.Rate{Freq: rate, Per: time.Second}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for res := range attacker.Attack(weightedTargeter(targets), pacer, duration, fmt.Sprintf("DriveBy Capacity %d/s", rate)) {
+			record(res)
+			metrics.Add(res)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		attacker.Stop()
+		<-done
+		return CapacityProbe{}, ctx.Err()
+	case <-done:
+	}
+	metrics.Close()
+
+	_, _, errorRate := requestOutcomes(metrics)
+	p := CapacityProbe{
+		Rate:           rate,
+		RequestsPerSec: metrics.Rate,
+		TotalRequests:  metrics.Requests,
+		ErrorRate:      errorRate,
+		LatencyP50:     metrics.Latencies.P50,
+		LatencyP95:     metrics.Latencies.P95,
+		LatencyP99:     metrics.Latencies.P99,
+	}
+
+	config := t.config.PerformanceTarget
+	var reasons []string
+	if config.MaxLatencyP95 > 0 && p.LatencyP95 > config.MaxLatencyP95 {
+		reasons = append(reasons, fmt.Sprintf("P95 latency (%s) exceeded target (%s)", p.LatencyP95, config.MaxLatencyP95))
+	}
+	if successRate := 1.0 - errorRate; config.MinSuccessRate > 0 && successRate < config.MinSuccessRate {
+		reasons = append(reasons, fmt.Sprintf("Success rate (%.2f%%) below target (%.2f%%)", successRate*100, config.MinSuccessRate*100))
+	}
+	p.Passed = len(reasons) == 0
+	p.Reason = strings.Join(reasons, "; ")
+	return p, nil
+}
+
+// capacityMessage summarizes a capacity search for the P007 result
+func capacityMessage(result *CapacityResult) string {
+	switch {
+	case result.MaxSustainableRate == 0:
+		return fmt.Sprintf("No sustainable rate found: %d req/s already failed (%s)", result.BreakingPoint, result.BreakingReason)
+	case result.BreakingPoint > 0:
+		return fmt.Sprintf("Maximum sustainable rate: %d req/s; breaking point %d req/s (%s)",
+			result.MaxSustainableRate, result.BreakingPoint, result.BreakingReason)
+	case result.CeilingReached:
+		return fmt.Sprintf("Maximum sustainable rate: at least %d req/s (the --rate ceiling)", result.MaxSustainableRate)
+	default:
+		return fmt.Sprintf("Maximum sustainable rate: at least %d req/s (probe limit reached before a failure)", result.MaxSustainableRate)
+	}
+}
@@ -0,0 +1,83 @@
+package validation
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestBisectCapacity(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     int
+		ceiling   int
+		maxProbes int
+		limit     int // Highest rate whose probe passes
+		rate      int // Maximum sustainable rate
+		breaking  int
+		atCeiling bool
+		probed    []int
+	}{
+		{
+			name: "doubles then bisects", start: 10, maxProbes: 10, limit: 100,
+			rate: 100, breaking: 105, probed: []int{10, 20, 40, 80, 100, 105, 110, 120, 160},
+		},
+		{
+			name: "stops at the ceiling", start: 10, ceiling: 50, maxProbes: 10, limit: 1000,
+			rate: 50, atCeiling: true, probed: []int{10, 20, 40, 50},
+		},
+		{
+			name: "start above the ceiling", start: 80, ceiling: 50, maxProbes: 10, limit: 1000,
+			rate: 50, atCeiling: true, probed: []int{50},
+		},
+		{
+			name: "first probe fails", start: 10, maxProbes: 10, limit: 5,
+			rate: 5, breaking: 6, probed: []int{5, 6, 7, 10},
+		},
+		{
+			name: "probe limit", start: 10, maxProbes: 3, limit: 1000,
+			rate: 40, probed: []int{10, 20, 40},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := bisectCapacity(tt.start, tt.ceiling, tt.maxProbes, func(rate int) (CapacityProbe, error) {
+				p := CapacityProbe{Rate: rate, Passed: rate <= tt.limit}
+				if !p.Passed {
+					p.Reason = "too slow"
+				}
+				return p, nil
+			})
+			if err != nil {
+				t.Fatalf("bisectCapacity: %v", err)
+			}
+			if result.MaxSustainableRate != tt.rate || result.BreakingPoint != tt.breaking || result.CeilingReached != tt.atCeiling {
+				t.Errorf("result is rate %d, breaking point %d, ceiling reached %v, want %d, %d, %v",
+					result.MaxSustainableRate, result.BreakingPoint, result.CeilingReached, tt.rate, tt.breaking, tt.atCeiling)
+			}
+			if tt.breaking > 0 && result.BreakingReason != "too slow" {
+				t.Errorf("breaking reason is %q, want the failing probe's reason", result.BreakingReason)
+			}
+			var probed []int
+			for _, p := range result.Curve {
+				probed = append(probed, p.Rate)
+			}
+			if !slices.Equal(probed, tt.probed) {
+				t.Errorf("probed rates %v, want %v", probed, tt.probed)
+			}
+		})
+	}
+}
+
+func TestBisectCapacityProbeError(t *testing.T) {
+	failure := errors.New("canceled")
+	_, err := bisectCapacity(10, 0, 10, func(rate int) (CapacityProbe, error) {
+		if rate > 20 {
+			return CapacityProbe{}, failure
+		}
+		return CapacityProbe{Rate: rate, Passed: true}, nil
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("error is %v, want %v", err, failure)
+	}
+}
@@ -0,0 +1,626 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/meter-peter/driveby/internal/openapi"
+)
+
+// ChangeSeverity classifies a change between two versions of a spec
+type ChangeSeverity string
+
+const (
+	ChangeBreaking    ChangeSeverity = "breaking"
+	ChangeNonBreaking ChangeSeverity = "non-breaking"
+	ChangeDeprecation ChangeSeverity = "deprecation"
+)
+
+// DeprecationPolicy states how removals are classified: deprecating an element first
+// is what makes removing it safe for clients
+const DeprecationPolicy = "Removing an operation, parameter or response field is non-breaking when the baseline already marks it deprecated, and breaking otherwise. Removing request fields and non-success responses is always non-breaking."
+
+// VersionBump is the kind of semantic version increment between two specs
+type VersionBump string
+
+const (
+	VersionBumpNone  VersionBump = "none"
+	VersionBumpPatch VersionBump = "patch"
+	VersionBumpMinor VersionBump = "minor"
+	VersionBumpMajor VersionBump = "major"
+)
+
+// maxDiffDepth bounds recursion through self-referencing schemas
+const maxDiffDepth = 10
+
+// SpecChange is a single difference between a baseline and a candidate spec
+type SpecChange struct {
+	Severity  ChangeSeverity `json:"severity"`
+	Kind      string         `json:"kind"`
+	Operation string         `json:"operation,omitempty"`
+	Location  string         `json:"location,omitempty"`
+	Message   string         `json:"message"`
+}
+
+// SpecDiff holds the classified changes between two specs and the version check
+type SpecDiff struct {
+	BaseVersion      string       `json:"base_version"`
+	CandidateVersion string       `json:"candidate_version"`
+	Changes          []SpecChange `json:"changes"`
+	Breaking         int          `json:"breaking"`
+	NonBreaking      int          `json:"non_breaking"`
+	Deprecations     int          `json:"deprecations"`
+	RequiredBump     VersionBump  `json:"required_bump"`
+	ActualBump       VersionBump  `json:"actual_bump"`
+	VersionOK        bool         `json:"version_ok"`
+	VersionMessage   string       `json:"version_message"`
+}
+
+// SpecDiffer compares a candidate spec against a baseline spec
+type SpecDiffer struct {
+	config ValidatorConfig
+}
+
+// NewSpecDiffer creates a new spec differ. SpecPath is the candidate and
+// BaselineSpecPath the spec it is compared against.
+func NewSpecDiffer(config ValidatorConfig) *SpecDiffer {
+	return &SpecDiffer{config: config}
+}
+
+// Diff loads both specs, classifies every change and checks that the candidate's
+// info.version is bumped according to the most severe change
+func (d *SpecDiffer) Diff(ctx context.Context) (*ValidationReport, error) {
+	if d.config.BaselineSpecPath == "" {
+		return nil, fmt.Errorf("baseline spec path is required")
+	}
+
+	baseLoader := openapi.NewLoader()
+	if err := baseLoader.LoadFromFileOrURL(d.config.BaselineSpecPath); err != nil {
+		return nil, fmt.Errorf("failed to load baseline OpenAPI spec: %w", err)
+	}
+	candidateLoader := openapi.NewLoader()
+	if err := candidateLoader.LoadFromFileOrURL(d.config.SpecPath); err != nil {
+		return nil, fmt.Errorf("failed to load candidate OpenAPI spec: %w", err)
+	}
+
+	diff := DiffSpecs(baseLoader.GetDocument(), candidateLoader.GetDocument())
+
+	result := PrincipleResult{
+		Principle: CorePrinciples[7], // P008
+		Passed:    diff.VersionOK,
+		Message:   diff.VersionMessage,
+		Details:   diff,
+	}
+	if !diff.VersionOK {
+		result.SuggestedFix = fmt.Sprintf("Release the candidate as a %s version bump of %s", diff.RequiredBump, diff.BaseVersion)
+		result.Findings = locateFindings(candidateLoader, specFindings(result))
+	}
+
+	report := &ValidationReport{
+		Version:     diff.CandidateVersion,
+		Environment: d.config.Environment,
+		Timestamp:   time.Now(),
+		Principles:  []PrincipleResult{result},
+		TotalChecks: 1,
+	}
+	if result.Passed {
+		report.PassedChecks = 1
+	} else {
+		report.FailedChecks = 1
+	}
+	return report, nil
+}
+
+// DiffSpecs classifies the differences between a baseline and a candidate document
+func DiffSpecs(base, candidate *openapi3.T) *SpecDiff {
+	diff := &SpecDiff{}
+	if base.Info != nil {
+		diff.BaseVersion = base.Info.Version
+	}
+	if candidate.Info != nil {
+		diff.CandidateVersion = candidate.Info.Version
+	}
+
+	c := &specComparer{}
+	c.compareOperations(base, candidate)
+	diff.Changes = c.changes
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		if diff.Changes[i].Operation != diff.Changes[j].Operation {
+			return diff.Changes[i].Operation < diff.Changes[j].Operation
+		}
+		return diff.Changes[i].Location < diff.Changes[j].Location
+	})
+	for _, change := range diff.Changes {
+		switch change.Severity {
+		case ChangeBreaking:
+			diff.Breaking++
+		case ChangeNonBreaking:
+			diff.NonBreaking++
+		case ChangeDeprecation:
+			diff.Deprecations++
+		}
+	}
+
+	checkVersionBump(diff)
+	return diff
+}
+
+// checkVersionBump compares the info.version increment with the most severe change
+func checkVersionBump(diff *SpecDiff) {
+	baseParts, baseErr := parseSemver(diff.BaseVersion)
+	candidateParts, candidateErr := parseSemver(diff.CandidateVersion)
+
+	switch {
+	case diff.Breaking > 0 && baseErr == nil && baseParts[0] == 0:
+		// Before 1.0.0 anything may change in a minor release
+		diff.RequiredBump = VersionBumpMinor
+	case diff.Breaking > 0:
+		diff.RequiredBump = VersionBumpMajor
+	case diff.NonBreaking > 0 || diff.Deprecations > 0:
+		diff.RequiredBump = VersionBumpMinor
+	default:
+		diff.RequiredBump = VersionBumpNone
+	}
+
+	if baseErr != nil || candidateErr != nil {
+		diff.VersionOK = diff.RequiredBump == VersionBumpNone
+		diff.ActualBump = VersionBumpNone
+		diff.VersionMessage = fmt.Sprintf("Cannot compare versions %q and %q: both must follow semantic versioning (e.g., 1.0.0)", diff.BaseVersion, diff.CandidateVersion)
+		return
+	}
+
+	diff.ActualBump = VersionBumpNone
+	switch {
+	case candidateParts[0] > baseParts[0]:
+		diff.ActualBump = VersionBumpMajor
+	case candidateParts[0] == baseParts[0] && candidateParts[1] > baseParts[1]:
+		diff.ActualBump = VersionBumpMinor
+	case candidateParts[0] == baseParts[0] && candidateParts[1] == baseParts[1] && candidateParts[2] > baseParts[2]:
+		diff.ActualBump = VersionBumpPatch
+	case candidateParts != baseParts:
+		diff.VersionOK = false
+		diff.VersionMessage = fmt.Sprintf("Candidate version %s is lower than baseline version %s", diff.CandidateVersion, diff.BaseVersion)
+		return
+	}
+
+	diff.VersionOK = bumpRank(diff.ActualBump) >= bumpRank(diff.RequiredBump)
+	summary := fmt.Sprintf("%d breaking, %d non-breaking and %d deprecation changes", diff.Breaking, diff.NonBreaking, diff.Deprecations)
+	if diff.VersionOK {
+		diff.VersionMessage = fmt.Sprintf("%s; version %s -> %s is a %s bump, which matches.", summary, diff.BaseVersion, diff.CandidateVersion, diff.ActualBump)
+	} else {
+		diff.VersionMessage = fmt.Sprintf("%s require a %s version bump, but %s -> %s is a %s bump.", summary, diff.RequiredBump, diff.BaseVersion, diff.CandidateVersion, diff.ActualBump)
+	}
+}
+
+// bumpRank orders version bumps by size
+func bumpRank(bump VersionBump) int {
+	switch bump {
+	case VersionBumpPatch:
+		return 1
+	case VersionBumpMinor:
+		return 2
+	case VersionBumpMajor:
+		return 3
+	}
+	return 0
+}
+
+var semverPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:[-+].*)?$`)
+
+// parseSemver extracts major, minor and patch from a version such as 1.2.3 or v1.2.3-rc.1
+func parseSemver(version string) ([3]int, error) {
+	var parts [3]int
+	match := semverPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return parts, fmt.Errorf("version %q does not follow semantic versioning", version)
+	}
+	for i := range parts {
+		parts[i], _ = strconv.Atoi(match[i+1])
+	}
+	return parts, nil
+}
+
+// specComparer collects changes while walking two documents
+type specComparer struct {
+	changes []SpecChange
+}
+
+func (c *specComparer) add(severity ChangeSeverity, kind, operation, location, message string) {
+	c.changes = append(c.changes, SpecChange{
+		Severity:  severity,
+		Kind:      kind,
+		Operation: operation,
+		Location:  location,
+		Message:   message,
+	})
+}
+
+// pathParamPattern matches templated path segments
+var pathParamPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// normalizePath replaces parameter names so renamed path parameters still match
+func normalizePath(path string) string {
+	return pathParamPattern.ReplaceAllString(path, "{}")
+}
+
+// compareOperations matches operations by method and normalized path
+func (c *specComparer) compareOperations(base, candidate *openapi3.T) {
+	baseItems := make(map[string]*openapi3.PathItem)
+	if base.Paths != nil {
+		for path, item := range base.Paths.Map() {
+			baseItems[normalizePath(path)] = item
+		}
+	}
+	candidateItems := make(map[string]*openapi3.PathItem)
+	if candidate.Paths != nil {
+		for path, item := range candidate.Paths.Map() {
+			candidateItems[normalizePath(path)] = item
+		}
+	}
+
+	if base.Paths != nil {
+		for path, baseItem := range base.Paths.Map() {
+			candidateItem := candidateItems[normalizePath(path)]
+			for method, baseOp := range baseItem.Operations() {
+				name := method + " " + path
+				var candidateOp *openapi3.Operation
+				if candidateItem != nil {
+					candidateOp = candidateItem.GetOperation(method)
+				}
+				if candidateOp == nil {
+					if baseOp.Deprecated {
+						c.add(ChangeNonBreaking, "operation_removed", name, "", fmt.Sprintf("Deprecated operation %s was removed", name))
+					} else {
+						c.add(ChangeBreaking, "operation_removed", name, "", fmt.Sprintf("Operation %s was removed without being deprecated first", name))
+					}
+					continue
+				}
+				c.compareOperation(name, baseItem, baseOp, candidateItem, candidateOp)
+			}
+		}
+	}
+
+	if candidate.Paths != nil {
+		for path, candidateItem := range candidate.Paths.Map() {
+			baseItem := baseItems[normalizePath(path)]
+			for method, candidateOp := range candidateItem.Operations() {
+				if baseItem != nil && baseItem.GetOperation(method) != nil {
+					continue
+				}
+				name := method + " " + path
+				if candidateOp.Deprecated {
+					c.add(ChangeDeprecation, "operation_added", name, "", fmt.Sprintf("Operation %s was added as deprecated", name))
+				} else {
+					c.add(ChangeNonBreaking, "operation_added", name, "", fmt.Sprintf("Operation %s was added", name))
+				}
+			}
+		}
+	}
+}
+
+// compareOperation compares parameters, request bodies and responses of one operation
+func (c *specComparer) compareOperation(name string, baseItem *openapi3.PathItem, baseOp *openapi3.Operation, candidateItem *openapi3.PathItem, candidateOp *openapi3.Operation) {
+	if !baseOp.Deprecated && candidateOp.Deprecated {
+		c.add(ChangeDeprecation, "operation_deprecated", name, "", fmt.Sprintf("Operation %s was deprecated", name))
+	}
+
+	c.compareParameters(name, operationParameters(baseItem, baseOp), operationParameters(candidateItem, candidateOp))
+	c.compareRequestBodies(name, baseOp.RequestBody, candidateOp.RequestBody)
+	c.compareResponses(name, baseOp.Responses, candidateOp.Responses)
+}
+
+// compareParameters matches parameters by location and name. Path parameters are matched
+// by position, since renaming them does not affect clients.
+func (c *specComparer) compareParameters(operation string, base, candidate openapi3.Parameters) {
+	index := func(params openapi3.Parameters) map[string]*openapi3.Parameter {
+		byKey := make(map[string]*openapi3.Parameter)
+		pathParams := 0
+		for _, ref := range params {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			key := ref.Value.In + ":" + ref.Value.Name
+			if ref.Value.In == openapi3.ParameterInPath {
+				key = fmt.Sprintf("path:#%d", pathParams)
+				pathParams++
+			}
+			byKey[key] = ref.Value
+		}
+		return byKey
+	}
+	baseParams := index(base)
+	candidateParams := index(candidate)
+
+	keys := make([]string, 0, len(baseParams)+len(candidateParams))
+	for key := range baseParams {
+		keys = append(keys, key)
+	}
+	for key := range candidateParams {
+		if _, ok := baseParams[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		baseParam, candidateParam := baseParams[key], candidateParams[key]
+		switch {
+		case baseParam == nil:
+			location := fmt.Sprintf("%s parameter '%s'", candidateParam.In, candidateParam.Name)
+			if candidateParam.Required {
+				c.add(ChangeBreaking, "required_parameter_added", operation, location, fmt.Sprintf("Required %s was added", location))
+			} else {
+				c.add(ChangeNonBreaking, "optional_parameter_added", operation, location, fmt.Sprintf("Optional %s was added", location))
+			}
+		case candidateParam == nil:
+			location := fmt.Sprintf("%s parameter '%s'", baseParam.In, baseParam.Name)
+			if baseParam.Deprecated {
+				c.add(ChangeNonBreaking, "parameter_removed", operation, location, fmt.Sprintf("Deprecated %s was removed", location))
+			} else {
+				c.add(ChangeBreaking, "parameter_removed", operation, location, fmt.Sprintf("%s was removed without being deprecated first", location))
+			}
+		default:
+			location := fmt.Sprintf("%s parameter '%s'", candidateParam.In, candidateParam.Name)
+			if !baseParam.Required && candidateParam.Required {
+				c.add(ChangeBreaking, "parameter_became_required", operation, location, fmt.Sprintf("%s became required", location))
+			} else if baseParam.Required && !candidateParam.Required {
+				c.add(ChangeNonBreaking, "parameter_became_optional", operation, location, fmt.Sprintf("%s became optional", location))
+			}
+			if !baseParam.Deprecated && candidateParam.Deprecated {
+				c.add(ChangeDeprecation, "parameter_deprecated", operation, location, fmt.Sprintf("%s was deprecated", location))
+			}
+			c.compareSchemas(operation, location, schemaOf(baseParam.Schema), schemaOf(candidateParam.Schema), true, 0)
+		}
+	}
+}
+
+// compareRequestBodies compares the JSON request bodies of an operation
+func (c *specComparer) compareRequestBodies(operation string, base, candidate *openapi3.RequestBodyRef) {
+	baseBody, candidateBody := requestBodyOf(base), requestBodyOf(candidate)
+	switch {
+	case baseBody == nil && candidateBody == nil:
+		return
+	case baseBody == nil:
+		if candidateBody.Required {
+			c.add(ChangeBreaking, "required_request_body_added", operation, "request body", "A required request body was added")
+		} else {
+			c.add(ChangeNonBreaking, "request_body_added", operation, "request body", "An optional request body was added")
+		}
+		return
+	case candidateBody == nil:
+		c.add(ChangeBreaking, "request_body_removed", operation, "request body", "The request body was removed")
+		return
+	}
+
+	if !baseBody.Required && candidateBody.Required {
+		c.add(ChangeBreaking, "request_body_became_required", operation, "request body", "The request body became required")
+	}
+	_, baseMedia := openapi.JSONMediaType(baseBody.Content)
+	_, candidateMedia := openapi.JSONMediaType(candidateBody.Content)
+	if baseMedia == nil || candidateMedia == nil {
+		return
+	}
+	c.compareSchemas(operation, "request body", schemaOf(baseMedia.Schema), schemaOf(candidateMedia.Schema), true, 0)
+}
+
+// compareResponses compares documented status codes and their JSON bodies
+func (c *specComparer) compareResponses(operation string, base, candidate *openapi3.Responses) {
+	if base == nil {
+		return
+	}
+	codes := make([]string, 0, base.Len())
+	for code := range base.Map() {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		location := fmt.Sprintf("response %s", code)
+		var candidateRef *openapi3.ResponseRef
+		if candidate != nil {
+			candidateRef = candidate.Value(code)
+		}
+		if candidateRef == nil || candidateRef.Value == nil {
+			if strings.HasPrefix(code, "2") {
+				c.add(ChangeBreaking, "response_removed", operation, location, fmt.Sprintf("Success response %s was removed", code))
+			} else {
+				c.add(ChangeNonBreaking, "response_removed", operation, location, fmt.Sprintf("Response %s was removed", code))
+			}
+			continue
+		}
+		baseRef := base.Value(code)
+		if baseRef == nil || baseRef.Value == nil {
+			continue
+		}
+		_, baseMedia := openapi.JSONMediaType(baseRef.Value.Content)
+		_, candidateMedia := openapi.JSONMediaType(candidateRef.Value.Content)
+		if baseMedia == nil || candidateMedia == nil {
+			if baseMedia != nil {
+				c.add(ChangeBreaking, "response_body_removed", operation, location, fmt.Sprintf("The body of response %s was removed", code))
+			}
+			continue
+		}
+		c.compareSchemas(operation, location+" body", schemaOf(baseMedia.Schema), schemaOf(candidateMedia.Schema), false, 0)
+	}
+
+	if candidate != nil {
+		for code := range candidate.Map() {
+			if base.Value(code) == nil {
+				c.add(ChangeNonBreaking, "response_added", operation, fmt.Sprintf("response %s", code), fmt.Sprintf("Response %s was added", code))
+			}
+		}
+	}
+}
+
+// compareSchemas compares two schemas in the request direction (what clients send) or the
+// response direction (what clients receive). Tightening a request schema or loosening a
+// response schema breaks clients; the opposite does not.
+func (c *specComparer) compareSchemas(operation, location string, base, candidate *openapi3.Schema, request bool, depth int) {
+	if base == nil || candidate == nil || depth > maxDiffDepth {
+		return
+	}
+
+	if base.Type != "" && candidate.Type != "" && base.Type != candidate.Type {
+		severity := ChangeBreaking
+		// An integer is a valid number, so widening what is accepted or narrowing what is
+		// returned keeps clients working
+		if (request && base.Type == "integer" && candidate.Type == "number") ||
+			(!request && base.Type == "number" && candidate.Type == "integer") {
+			severity = ChangeNonBreaking
+		}
+		c.add(severity, "type_changed", operation, location, fmt.Sprintf("Type of %s changed from %s to %s", location, base.Type, candidate.Type))
+		return
+	}
+
+	if !base.Deprecated && candidate.Deprecated {
+		c.add(ChangeDeprecation, "schema_deprecated", operation, location, fmt.Sprintf("%s was deprecated", location))
+	}
+
+	c.compareEnums(operation, location, base.Enum, candidate.Enum, request)
+
+	if base.Items != nil && candidate.Items != nil {
+		c.compareSchemas(operation, location+"[]", schemaOf(base.Items), schemaOf(candidate.Items), request, depth+1)
+	}
+
+	baseProps, baseRequired := flattenObjectSchema(base)
+	candidateProps, candidateRequired := flattenObjectSchema(candidate)
+	if len(baseProps) == 0 && len(candidateProps) == 0 {
+		return
+	}
+	baseRequiredSet := make(map[string]bool, len(baseRequired))
+	for _, name := range baseRequired {
+		baseRequiredSet[name] = true
+	}
+	candidateRequiredSet := make(map[string]bool, len(candidateRequired))
+	for _, name := range candidateRequired {
+		candidateRequiredSet[name] = true
+	}
+
+	names := make([]string, 0, len(baseProps)+len(candidateProps))
+	for name := range baseProps {
+		names = append(names, name)
+	}
+	for name := range candidateProps {
+		if _, ok := baseProps[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := location + "." + name
+		baseProp, candidateProp := schemaOf(baseProps[name]), schemaOf(candidateProps[name])
+		switch {
+		case baseProp == nil && candidateProp != nil:
+			if request && candidateRequiredSet[name] && !candidateProp.ReadOnly {
+				c.add(ChangeBreaking, "required_field_added", operation, field, fmt.Sprintf("Required request field %s was added", field))
+			} else {
+				c.add(ChangeNonBreaking, "field_added", operation, field, fmt.Sprintf("Field %s was added", field))
+			}
+		case baseProp != nil && candidateProp == nil:
+			switch {
+			case request:
+				c.add(ChangeNonBreaking, "request_field_removed", operation, field, fmt.Sprintf("Request field %s was removed", field))
+			case baseProp.Deprecated:
+				c.add(ChangeNonBreaking, "response_field_removed", operation, field, fmt.Sprintf("Deprecated response field %s was removed", field))
+			default:
+				c.add(ChangeBreaking, "response_field_removed", operation, field, fmt.Sprintf("Response field %s was removed without being deprecated first", field))
+			}
+		case baseProp != nil && candidateProp != nil:
+			if request && !baseRequiredSet[name] && candidateRequiredSet[name] {
+				c.add(ChangeBreaking, "field_became_required", operation, field, fmt.Sprintf("Request field %s became required", field))
+			}
+			if !request && baseRequiredSet[name] && !candidateRequiredSet[name] {
+				c.add(ChangeBreaking, "field_became_optional", operation, field, fmt.Sprintf("Response field %s is no longer guaranteed", field))
+			}
+			c.compareSchemas(operation, field, baseProp, candidateProp, request, depth+1)
+		}
+	}
+}
+
+// compareEnums reports removed and added enum values
+func (c *specComparer) compareEnums(operation, location string, base, candidate []interface{}, request bool) {
+	if len(base) == 0 && len(candidate) == 0 {
+		return
+	}
+	if len(candidate) == 0 {
+		if request {
+			c.add(ChangeNonBreaking, "enum_removed", operation, location, fmt.Sprintf("%s is no longer restricted to an enum", location))
+		} else {
+			c.add(ChangeBreaking, "enum_removed", operation, location, fmt.Sprintf("%s may now return values outside the previous enum", location))
+		}
+		return
+	}
+	key := func(v interface{}) string { return fmt.Sprintf("%v", v) }
+	baseSet := make(map[string]bool, len(base))
+	for _, v := range base {
+		baseSet[key(v)] = true
+	}
+	candidateSet := make(map[string]bool, len(candidate))
+	for _, v := range candidate {
+		candidateSet[key(v)] = true
+	}
+
+	var removed, added []string
+	for _, v := range base {
+		if !candidateSet[key(v)] {
+			removed = append(removed, key(v))
+		}
+	}
+	if len(base) > 0 {
+		for _, v := range candidate {
+			if !baseSet[key(v)] {
+				added = append(added, key(v))
+			}
+		}
+	} else {
+		// A previously unrestricted value gained an enum
+		for _, v := range candidate {
+			added = append(added, key(v))
+		}
+		if request {
+			c.add(ChangeBreaking, "enum_narrowed", operation, location, fmt.Sprintf("%s is now restricted to %s", location, strings.Join(added, ", ")))
+		} else {
+			c.add(ChangeNonBreaking, "enum_added", operation, location, fmt.Sprintf("%s is now restricted to %s", location, strings.Join(added, ", ")))
+		}
+		return
+	}
+
+	if len(removed) > 0 {
+		if request {
+			c.add(ChangeBreaking, "enum_narrowed", operation, location, fmt.Sprintf("Values %s are no longer accepted for %s", strings.Join(removed, ", "), location))
+		} else {
+			c.add(ChangeNonBreaking, "enum_narrowed", operation, location, fmt.Sprintf("Values %s are no longer returned for %s", strings.Join(removed, ", "), location))
+		}
+	}
+	if len(added) > 0 {
+		if request {
+			c.add(ChangeNonBreaking, "enum_widened", operation, location, fmt.Sprintf("Values %s are now accepted for %s", strings.Join(added, ", "), location))
+		} else {
+			c.add(ChangeBreaking, "enum_widened", operation, location, fmt.Sprintf("Values %s may now be returned for %s", strings.Join(added, ", "), location))
+		}
+	}
+}
+
+// schemaOf dereferences a schema reference
+func schemaOf(ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil {
+		return nil
+	}
+	return ref.Value
+}
+
+// requestBodyOf dereferences a request body reference
+func requestBodyOf(ref *openapi3.RequestBodyRef) *openapi3.RequestBody {
+	if ref == nil {
+		return nil
+	}
+	return ref.Value
+}
@@ -0,0 +1,212 @@
+package validation
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// loadDiffSpec loads a spec with the given version and paths, given as YAML
+func loadDiffSpec(t *testing.T, version, paths string) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte("openapi: 3.0.3\ninfo: {title: Tasks, version: " + version + "}\npaths:\n" + paths))
+	if err != nil {
+		t.Fatalf("LoadFromData: %v", err)
+	}
+	return doc
+}
+
+func TestDiffSpecsClassification(t *testing.T) {
+	const listTasks = `
+  /tasks:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: string}
+                  legacy: {type: string, deprecated: true}
+`
+	tests := []struct {
+		name      string
+		base      string
+		candidate string
+		want      []string // "severity kind" of each change
+	}{
+		{
+			name:      "no change",
+			base:      listTasks,
+			candidate: listTasks,
+		},
+		{
+			name: "operation removed",
+			base: listTasks + `
+  /tasks/{id}:
+    delete:
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses: {'204': {description: Deleted}}
+`,
+			candidate: listTasks,
+			want:      []string{"breaking operation_removed"},
+		},
+		{
+			name: "deprecated operation removed",
+			base: listTasks + `
+  /tasks/{id}:
+    delete:
+      deprecated: true
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses: {'204': {description: Deleted}}
+`,
+			candidate: listTasks,
+			want:      []string{"non-breaking operation_removed"},
+		},
+		{
+			name: "operation added",
+			base: listTasks,
+			candidate: listTasks + `
+  /tags:
+    get:
+      responses: {'200': {description: OK}}
+`,
+			want: []string{"non-breaking operation_added"},
+		},
+		{
+			name: "parameter removed",
+			base: listTasks,
+			candidate: `
+  /tasks:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: string}
+                  legacy: {type: string, deprecated: true}
+`,
+			want: []string{"breaking parameter_removed"},
+		},
+		{
+			name: "required parameter added and deprecated response field removed",
+			base: listTasks,
+			candidate: `
+  /tasks:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+        - {name: owner, in: query, required: true, schema: {type: string}}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: string}
+`,
+			want: []string{"breaking required_parameter_added", "non-breaking response_field_removed"},
+		},
+		{
+			name: "response field type changed",
+			base: listTasks,
+			candidate: `
+  /tasks:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: integer}
+                  legacy: {type: string, deprecated: true}
+`,
+			want: []string{"breaking type_changed"},
+		},
+		{
+			name: "parameter deprecated",
+			base: listTasks,
+			candidate: `
+  /tasks:
+    get:
+      parameters:
+        - {name: limit, in: query, deprecated: true, schema: {type: integer}}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: string}
+                  legacy: {type: string, deprecated: true}
+`,
+			want: []string{"deprecation parameter_deprecated"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffSpecs(loadDiffSpec(t, "1.0.0", tt.base), loadDiffSpec(t, "1.0.0", tt.candidate))
+			var got []string
+			for _, change := range diff.Changes {
+				got = append(got, string(change.Severity)+" "+change.Kind)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("changes are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckVersionBump(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		candidate string
+		breaking  int
+		other     int
+		required  VersionBump
+		actual    VersionBump
+		ok        bool
+	}{
+		{name: "breaking with major bump", base: "1.2.3", candidate: "2.0.0", breaking: 1, required: VersionBumpMajor, actual: VersionBumpMajor, ok: true},
+		{name: "breaking with minor bump", base: "1.2.3", candidate: "1.3.0", breaking: 1, required: VersionBumpMajor, actual: VersionBumpMinor},
+		{name: "breaking before 1.0.0", base: "0.4.0", candidate: "0.5.0", breaking: 1, required: VersionBumpMinor, actual: VersionBumpMinor, ok: true},
+		{name: "non-breaking with patch bump", base: "1.2.3", candidate: "1.2.4", other: 1, required: VersionBumpMinor, actual: VersionBumpPatch},
+		{name: "no change", base: "v1.2.3", candidate: "v1.2.3", required: VersionBumpNone, actual: VersionBumpNone, ok: true},
+		{name: "version lowered", base: "1.2.3", candidate: "1.1.0", required: VersionBumpNone, actual: VersionBumpNone},
+		{name: "not semver", base: "1.2", candidate: "1.3", other: 1, required: VersionBumpMinor, actual: VersionBumpNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := &SpecDiff{BaseVersion: tt.base, CandidateVersion: tt.candidate, Breaking: tt.breaking, NonBreaking: tt.other}
+			checkVersionBump(diff)
+			if diff.RequiredBump != tt.required || diff.ActualBump != tt.actual || diff.VersionOK != tt.ok {
+				t.Errorf("bump is required %s, actual %s, ok %v, want %s, %s, %v (%s)",
+					diff.RequiredBump, diff.ActualBump, diff.VersionOK, tt.required, tt.actual, tt.ok, diff.VersionMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// apiPrincipleIDs are the principles that test the running API rather than the spec
+var apiPrincipleIDs = []string{"P006", "P007"}
+
+// Finding is a single problem reported by a failed spec principle
+type Finding struct {
+	PrincipleID string
+	Severity    string
+	Check       string // Principle check that failed, when known
+	Message     string
+	Pointer     string // JSON pointer to the spec location, when known
+	Line        int    // Position of the pointer in the spec file; 0 when unknown
+	Column      int
+}
+
+// Location formats the position of a finding in the spec file, e.g. "12:5", or returns
+// an empty string when it is unknown
+func (f Finding) Location() string {
+	if f.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", f.Line, f.Column)
+}
+
+// SpecFindings splits the failed spec principles of a report into one finding per
+// problem, each located in the spec as precisely as the principle's details allow
+func SpecFindings(report *ValidationReport) []Finding {
+	var findings []Finding
+	for _, result := range report.Principles {
+		if result.Passed || !IsSpecPrinciple(result.Principle.ID) {
+			continue
+		}
+		if len(result.Findings) > 0 {
+			// Already located in the spec file by the validator
+			findings = append(findings, result.Findings...)
+			continue
+		}
+		findings = append(findings, specFindings(result)...)
+	}
+	return findings
+}
+
+// specFindings returns the findings of a failed spec principle, or a single finding
+// when its details do not list individual problems
+func specFindings(result PrincipleResult) []Finding {
+	if found := PrincipleFindings(result); len(found) > 0 {
+		return found
+	}
+	return []Finding{{
+		PrincipleID: result.Principle.ID,
+		Severity:    result.Principle.Severity,
+		Message:     result.Message,
+		Pointer:     principlePointer(result.Principle.ID),
+	}}
+}
+
+// PrincipleFindings itemizes the problems listed in a principle's details. It returns
+// nothing when the details do not list individual problems.
+func PrincipleFindings(result PrincipleResult) []Finding {
+	findings := principleFindings(result)
+	for i := range findings {
+		findings[i].PrincipleID = result.Principle.ID
+		findings[i].Severity = result.Principle.Severity
+	}
+	return findings
+}
+
+// IsSpecPrinciple reports whether a principle checks the spec itself. Every principle
+// but those testing the running API does, including registered ones.
+func IsSpecPrinciple(id string) bool {
+	for _, apiID := range apiPrincipleIDs {
+		if id == apiID {
+			return false
+		}
+	}
+	return true
+}
+
+// principleFindings itemizes the problems listed in a principle's details
+func principleFindings(result PrincipleResult) []Finding {
+	var findings []Finding
+	switch details := result.Details.(type) {
+	case []string:
+		// Operations listed by a principle, e.g. those missing authentication
+		for _, item := range details {
+			findings = append(findings, Finding{Message: fmt.Sprintf("%s: %s", result.Message, item), Pointer: itemPointer("", item)})
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"missing_docs", "missing_errors", "missing_validation"} {
+			missing, ok := details[key].(map[string][]string)
+			if !ok {
+				continue
+			}
+			for _, check := range sortedKeys(missing) {
+				for _, item := range missing[check] {
+					findings = append(findings, Finding{
+						Check:   check,
+						Message: fmt.Sprintf("%s: %s", check, item),
+						Pointer: itemPointer(check, item),
+					})
+				}
+			}
+		}
+		if len(findings) > 0 {
+			return findings
+		}
+		checks, _ := details["checks"].(map[string]bool)
+		messages, _ := details["messages"].(map[string]string)
+		for _, check := range sortedKeys(checks) {
+			if checks[check] {
+				continue
+			}
+			message := check
+			if messages[check] != "" {
+				message = fmt.Sprintf("%s: %s", check, messages[check])
+			}
+			findings = append(findings, Finding{Check: check, Message: message, Pointer: checkPointer(check)})
+		}
+	}
+	return findings
+}
+
+// itemPointer locates an item of a principle's details, such as "GET /tasks: 404 response"
+// or a schema name, in the spec
+func itemPointer(check, item string) string {
+	operation, rest, _ := strings.Cut(item, ": ")
+	if strings.HasPrefix(operation, "/") {
+		// Path-level parameters are listed without a method
+		return specPointer("paths", operation)
+	}
+	method, path, ok := strings.Cut(operation, " ")
+	if !ok || !strings.HasPrefix(path, "/") || !isHTTPMethod(method) {
+		if strings.Contains(check, "schemas") || strings.Contains(check, "enums") {
+			return specPointer("components", "schemas", operation)
+		}
+		return ""
+	}
+	if strings.HasSuffix(rest, " schema") {
+		// Schema fields are appended to the path, as in "POST /tasks.status"
+		if i := strings.LastIndex(path, "."); i > strings.LastIndex(path, "/") {
+			path = path[:i]
+		}
+	}
+	tokens := []string{"paths", path, strings.ToLower(method)}
+	fields := strings.Fields(rest)
+	switch {
+	case strings.HasSuffix(rest, "request body"):
+		tokens = append(tokens, "requestBody")
+	case len(fields) >= 2 && fields[len(fields)-1] == "response":
+		tokens = append(tokens, "responses", fields[0])
+	}
+	return specPointer(tokens...)
+}
+
+// checkPointer locates the part of the spec a document-level check is about
+func checkPointer(check string) string {
+	switch {
+	case strings.Contains(check, "OpenAPI version"):
+		return specPointer("openapi")
+	case strings.Contains(check, "info"):
+		return specPointer("info")
+	case strings.Contains(check, "Paths"):
+		return specPointer("paths")
+	case strings.Contains(check, "Components"):
+		return specPointer("components")
+	}
+	return ""
+}
+
+// principlePointer locates a principle's problem when its details do not
+func principlePointer(id string) string {
+	switch id {
+	case "P005":
+		return specPointer("components", "securitySchemes")
+	case "P008":
+		return specPointer("info", "version")
+	}
+	return ""
+}
+
+// isHTTPMethod reports whether a value is an upper-case HTTP method
+func isHTTPMethod(value string) bool {
+	switch value {
+	case "GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE":
+		return true
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,553 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/meter-peter/driveby/internal/openapi"
+)
+
+// errorSchemaName is the component schema shared by the generated error responses
+const errorSchemaName = "Error"
+
+// commonErrorResponses are the shared error responses added to components.responses.
+// They are keyed by status code, which is what P003 looks for.
+var commonErrorResponses = map[string]string{
+	"400": "Bad Request: the request is malformed or violates a documented constraint",
+	"401": "Unauthorized: credentials are missing or invalid",
+	"404": "Not Found: the requested resource does not exist",
+	"500": "Internal Server Error: the request could not be processed",
+}
+
+// Fixer applies safe, deterministic repairs to an OpenAPI spec
+type Fixer struct {
+	config ValidatorConfig
+	loader *openapi.Loader
+	fixes  []AutoFixResult
+}
+
+// NewFixer creates a new fixer instance
+func NewFixer(config ValidatorConfig) *Fixer {
+	return &Fixer{
+		config: config,
+		loader: openapi.NewLoader(),
+	}
+}
+
+// Fix repairs the spec, writes it to the output path and validates the result. The
+// returned report holds the validation of the repaired spec and one AutoFixResult per
+// change. The repaired spec is re-serialized, losing the comments, key order and
+// formatting of the original, so an output path is required; when it is the spec itself,
+// the original is kept next to it with a .orig extension.
+func (f *Fixer) Fix(ctx context.Context) (*ValidationReport, error) {
+	output := f.config.FixOutputPath
+	if output == "" {
+		return nil, fmt.Errorf("an output path for the repaired spec is required")
+	}
+
+	if err := f.loader.LoadFromFileOrURL(f.config.SpecPath); err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+	doc := f.loader.GetDocument()
+	if doc == nil {
+		return nil, fmt.Errorf("failed to get OpenAPI document")
+	}
+
+	f.fixCompliance(doc)
+	f.fixDocumentation(doc)
+	f.fixErrorResponses(doc)
+	f.fixRequestSchemas(doc)
+	f.fixVersion(doc)
+
+	changed := false
+	for _, fix := range f.fixes {
+		if fix.Success {
+			changed = true
+			break
+		}
+	}
+	inPlace := sameFile(output, f.config.SpecPath)
+	if changed || !inPlace {
+		if inPlace {
+			if err := backupFile(output); err != nil {
+				return nil, err
+			}
+		}
+		if err := f.loader.SaveToFile(output); err != nil {
+			return nil, fmt.Errorf("failed to save fixed OpenAPI spec: %w", err)
+		}
+	}
+
+	// Validate the repaired spec so the report shows what is left to do by hand
+	validatorConfig := f.config
+	validatorConfig.SpecPath = output
+	validator, err := NewOpenAPIValidator(validatorConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create validator: %w", err)
+	}
+	report, err := validator.ValidateSpec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate fixed OpenAPI spec: %w", err)
+	}
+	report.AutoFixes = f.fixes
+	return report, nil
+}
+
+// fixCompliance repairs P001 problems: missing required info fields, duplicate
+// operationIds and responses without the required description
+func (f *Fixer) fixCompliance(doc *openapi3.T) {
+	if doc.Info == nil {
+		doc.Info = &openapi3.Info{}
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = "TODO: API title"
+		f.record(0, specPointer("info", "title"), "info.title is missing", "added a placeholder title", nil, doc.Info.Title, nil)
+	}
+
+	seen := make(map[string]bool)
+	forEachOperation(doc, func(path, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation) {
+		id := operation.OperationID
+		if id == "" {
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			return
+		}
+		operation.OperationID = uniqueOperationID(operationIDFor(method, path), seen)
+		seen[operation.OperationID] = true
+		f.record(0, specPointer("paths", path, strings.ToLower(method), "operationId"),
+			fmt.Sprintf("operationId %q is used by more than one operation", id),
+			fmt.Sprintf("renamed to %q", operation.OperationID), id, operation.OperationID, nil)
+	})
+
+	forEachOperation(doc, func(path, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation) {
+		if operation.Responses == nil {
+			return
+		}
+		for _, code := range sortedResponseCodes(operation.Responses) {
+			ref := operation.Responses.Value(code)
+			if ref == nil || ref.Ref != "" || ref.Value == nil {
+				continue
+			}
+			if ref.Value.Description == nil || *ref.Value.Description == "" {
+				description := fmt.Sprintf("TODO: describe the %s response", code)
+				ref.Value.Description = &description
+				f.record(0, specPointer("paths", path, strings.ToLower(method), "responses", code, "description"),
+					"response description is required", "added a placeholder description", nil, description, nil)
+			}
+		}
+	})
+}
+
+// fixDocumentation repairs P002 problems: missing operationIds and missing summaries and
+// descriptions
+func (f *Fixer) fixDocumentation(doc *openapi3.T) {
+	seen := make(map[string]bool)
+	forEachOperation(doc, func(path, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation) {
+		if operation.OperationID != "" {
+			seen[operation.OperationID] = true
+		}
+	})
+
+	if doc.Info.Description == "" {
+		doc.Info.Description = "TODO: describe the API"
+		f.record(1, specPointer("info", "description"), "API has no description", "added a placeholder description", nil, doc.Info.Description, nil)
+	}
+
+	forEachOperation(doc, func(path, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation) {
+		opPointer := []string{"paths", path, strings.ToLower(method)}
+		if operation.OperationID == "" {
+			operation.OperationID = uniqueOperationID(operationIDFor(method, path), seen)
+			seen[operation.OperationID] = true
+			f.record(1, specPointer(append(opPointer, "operationId")...), "operation has no operationId",
+				fmt.Sprintf("generated operationId %q", operation.OperationID), nil, operation.OperationID, nil)
+		}
+		if operation.Summary == "" {
+			operation.Summary = fmt.Sprintf("TODO: summarize %s %s", method, path)
+			f.record(1, specPointer(append(opPointer, "summary")...), "operation has no summary", "added a placeholder summary", nil, operation.Summary, nil)
+		}
+		if operation.Description == "" {
+			operation.Description = fmt.Sprintf("TODO: describe %s %s", method, path)
+			f.record(1, specPointer(append(opPointer, "description")...), "operation has no description", "added a placeholder description", nil, operation.Description, nil)
+		}
+		for i, ref := range operation.Parameters {
+			if ref.Ref != "" || ref.Value == nil || ref.Value.Description != "" {
+				continue
+			}
+			ref.Value.Description = fmt.Sprintf("TODO: describe the %s parameter %s", ref.Value.In, ref.Value.Name)
+			f.record(1, specPointer(append(opPointer, "parameters", strconv.Itoa(i), "description")...),
+				"parameter has no description", "added a placeholder description", nil, ref.Value.Description, nil)
+		}
+		if body := operation.RequestBody; body != nil && body.Ref == "" && body.Value != nil && body.Value.Description == "" {
+			body.Value.Description = "TODO: describe the request body"
+			f.record(1, specPointer(append(opPointer, "requestBody", "description")...),
+				"request body has no description", "added a placeholder description", nil, body.Value.Description, nil)
+		}
+	})
+
+	for _, path := range sortedPaths(doc) {
+		for i, ref := range doc.Paths.Value(path).Parameters {
+			if ref.Ref != "" || ref.Value == nil || ref.Value.Description != "" {
+				continue
+			}
+			ref.Value.Description = fmt.Sprintf("TODO: describe the %s parameter %s", ref.Value.In, ref.Value.Name)
+			f.record(1, specPointer("paths", path, "parameters", strconv.Itoa(i), "description"),
+				"parameter has no description", "added a placeholder description", nil, ref.Value.Description, nil)
+		}
+	}
+	if doc.Components != nil {
+		for _, name := range sortedParameterNames(doc.Components.Parameters) {
+			ref := doc.Components.Parameters[name]
+			if ref.Ref != "" || ref.Value == nil || ref.Value.Description != "" {
+				continue
+			}
+			ref.Value.Description = fmt.Sprintf("TODO: describe the %s parameter %s", ref.Value.In, ref.Value.Name)
+			f.record(1, specPointer("components", "parameters", name, "description"),
+				"parameter has no description", "added a placeholder description", nil, ref.Value.Description, nil)
+		}
+	}
+}
+
+// fixErrorResponses repairs P003 problems: operations without 4xx or 5xx responses get
+// references to shared error responses, which are added to components.responses
+func (f *Fixer) fixErrorResponses(doc *openapi3.T) {
+	if doc.Components == nil {
+		doc.Components = &openapi3.Components{}
+	}
+
+	var errorSchema *openapi3.SchemaRef
+	shared := func(code string) *openapi3.ResponseRef {
+		if existing, ok := doc.Components.Responses[code]; ok {
+			return &openapi3.ResponseRef{Ref: "#/components/responses/" + code, Value: existing.Value}
+		}
+		if errorSchema == nil {
+			errorSchema = f.errorSchema(doc)
+		}
+		response := openapi3.NewResponse().
+			WithDescription(commonErrorResponses[code]).
+			WithContent(openapi3.NewContentWithJSONSchemaRef(errorSchema))
+		if doc.Components.Responses == nil {
+			doc.Components.Responses = make(openapi3.ResponseBodies)
+		}
+		doc.Components.Responses[code] = &openapi3.ResponseRef{Value: response}
+		f.record(2, specPointer("components", "responses", code), "no shared error response is defined for "+code,
+			"added a shared error response", nil, response, nil)
+		return &openapi3.ResponseRef{Ref: "#/components/responses/" + code, Value: response}
+	}
+
+	forEachOperation(doc, func(path, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation) {
+		if operation.Responses == nil {
+			operation.Responses = openapi3.NewResponsesWithCapacity(0)
+		}
+		has4xx, has5xx := false, false
+		for code := range operation.Responses.Map() {
+			has4xx = has4xx || strings.HasPrefix(code, "4")
+			has5xx = has5xx || strings.HasPrefix(code, "5")
+		}
+
+		var codes []string
+		if !has4xx {
+			codes = append(codes, "400")
+			if hasSecurityRequirement(doc, operation) {
+				codes = append(codes, "401")
+			}
+			if strings.Contains(path, "{") {
+				codes = append(codes, "404")
+			}
+		}
+		if !has5xx {
+			codes = append(codes, "500")
+		}
+		for _, code := range codes {
+			ref := shared(code)
+			operation.Responses.Set(code, ref)
+			var impact *TestImpact
+			if strings.HasPrefix(code, "4") {
+				impact = &TestImpact{CanRunTests: true, AffectedTests: []string{TestKindNegative}, ImpactLevel: ImpactLevelLow,
+					Recommendations: []string{"Negative tests can now expect a documented 4xx response"}}
+			}
+			f.record(2, specPointer("paths", path, strings.ToLower(method), "responses", code),
+				fmt.Sprintf("operation documents no %sxx response", code[:1]),
+				"referenced the shared error response", nil, map[string]string{"$ref": ref.Ref}, impact)
+		}
+	})
+}
+
+// errorSchema returns a reference to the shared error schema, adding it to
+// components.schemas if it does not exist
+func (f *Fixer) errorSchema(doc *openapi3.T) *openapi3.SchemaRef {
+	if existing, ok := doc.Components.Schemas[errorSchemaName]; ok {
+		return &openapi3.SchemaRef{Ref: "#/components/schemas/" + errorSchemaName, Value: existing.Value}
+	}
+	schema := openapi3.NewObjectSchema().
+		WithProperty("code", openapi3.NewStringSchema()).
+		WithProperty("message", openapi3.NewStringSchema()).
+		WithProperty("details", openapi3.NewObjectSchema())
+	schema.Required = []string{"code", "message"}
+	schema.Description = "Error returned by all error responses"
+	if doc.Components.Schemas == nil {
+		doc.Components.Schemas = make(openapi3.Schemas)
+	}
+	doc.Components.Schemas[errorSchemaName] = &openapi3.SchemaRef{Value: schema}
+	f.record(2, specPointer("components", "schemas", errorSchemaName), "no shared error schema is defined",
+		"added an error schema with code, message and details", nil, schema, nil)
+	return &openapi3.SchemaRef{Ref: "#/components/schemas/" + errorSchemaName, Value: schema}
+}
+
+// fixRequestSchemas repairs P004 problems: parameters without a schema get a string
+// schema, which is how every parameter travels on the wire, and path parameters are
+// marked required
+func (f *Fixer) fixRequestSchemas(doc *openapi3.T) {
+	fixParameter := func(pointer []string, param *openapi3.Parameter) {
+		if param.Schema == nil && param.Content == nil {
+			param.Schema = openapi3.NewStringSchema().NewRef()
+			f.record(3, specPointer(append(pointer, "schema")...), fmt.Sprintf("parameter %s has no schema", param.Name),
+				"added a string schema", nil, param.Schema.Value,
+				&TestImpact{CanRunTests: true, AffectedTests: []string{TestKindEndpoint, TestKindLoad}, ImpactLevel: ImpactLevelMedium,
+					Recommendations: []string{"Narrow the generated string schema to the parameter's real type"}})
+		}
+		if param.In == openapi3.ParameterInPath && !param.Required {
+			param.Required = true
+			f.record(3, specPointer(append(pointer, "required")...), fmt.Sprintf("path parameter %s is not marked required", param.Name),
+				"marked the path parameter required", false, true, nil)
+		}
+	}
+
+	for _, path := range sortedPaths(doc) {
+		pathItem := doc.Paths.Value(path)
+		for i, ref := range pathItem.Parameters {
+			if ref.Ref == "" && ref.Value != nil {
+				fixParameter([]string{"paths", path, "parameters", strconv.Itoa(i)}, ref.Value)
+			}
+		}
+		for _, method := range sortedMethods(pathItem) {
+			for i, ref := range pathItem.GetOperation(method).Parameters {
+				if ref.Ref == "" && ref.Value != nil {
+					fixParameter([]string{"paths", path, strings.ToLower(method), "parameters", strconv.Itoa(i)}, ref.Value)
+				}
+			}
+		}
+	}
+	if doc.Components != nil {
+		for _, name := range sortedParameterNames(doc.Components.Parameters) {
+			ref := doc.Components.Parameters[name]
+			if ref.Ref == "" && ref.Value != nil {
+				fixParameter([]string{"components", "parameters", name}, ref.Value)
+			}
+		}
+	}
+}
+
+// fixVersion repairs P008 problems: info.version is normalized to MAJOR.MINOR.PATCH, or
+// taken from the configured version when missing
+func (f *Fixer) fixVersion(doc *openapi3.T) {
+	version := doc.Info.Version
+	pointer := specPointer("info", "version")
+	if version == "" {
+		if _, err := parseSemver(f.config.Version); err != nil {
+			f.fail(7, pointer, "API version is not specified", "no semantic version is configured to use instead")
+			return
+		}
+		doc.Info.Version = strings.TrimPrefix(strings.TrimSpace(f.config.Version), "v")
+		f.record(7, pointer, "API version is not specified", "used the configured version", nil, doc.Info.Version, nil)
+		return
+	}
+
+	normalized, err := normalizeSemver(version)
+	if err != nil {
+		f.fail(7, pointer, fmt.Sprintf("API version %q does not follow semantic versioning", version), err.Error())
+		return
+	}
+	if normalized != version {
+		doc.Info.Version = normalized
+		f.record(7, pointer, fmt.Sprintf("API version %q does not follow semantic versioning", version),
+			"normalized the version", version, normalized, nil)
+	}
+}
+
+// normalizeSemver turns versions such as "v1", "1.2" or "v1.2.3-beta" into MAJOR.MINOR.PATCH
+// form, keeping any pre-release or build suffix
+func normalizeSemver(version string) (string, error) {
+	core := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(version), "v"), "V")
+	suffix := ""
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core, suffix = core[:i], core[i:]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return "", fmt.Errorf("version %q has more than three numeric parts", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("version %q has a non-numeric part %q", version, part)
+		}
+		parts[i] = strconv.Itoa(n)
+	}
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	return strings.Join(parts, ".") + suffix, nil
+}
+
+// record adds a successful fix. before and after are rendered as JSON snippets; a nil
+// before means the value did not exist.
+func (f *Fixer) record(principle int, location, problem, message string, before, after interface{}, impact *TestImpact) {
+	f.fixes = append(f.fixes, AutoFixResult{
+		PrincipleID: CorePrinciples[principle].ID,
+		Timestamp:   time.Now(),
+		Location:    location,
+		Success:     true,
+		Message:     message,
+		Original: PrincipleResult{
+			Principle: CorePrinciples[principle],
+			Message:   problem,
+		},
+		Before:     snippet(before),
+		Fixed:      snippet(after),
+		TestImpact: impact,
+	})
+}
+
+// fail adds a fix that could not be applied
+func (f *Fixer) fail(principle int, location, problem, reason string) {
+	f.fixes = append(f.fixes, AutoFixResult{
+		PrincipleID: CorePrinciples[principle].ID,
+		Timestamp:   time.Now(),
+		Location:    location,
+		Success:     false,
+		Error:       reason,
+		Original: PrincipleResult{
+			Principle: CorePrinciples[principle],
+			Message:   problem,
+		},
+	})
+}
+
+// snippet renders a value as compact JSON
+func snippet(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// sameFile reports whether two paths name the same existing file
+func sameFile(a, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+// backupFile copies a file to the same path with a .orig extension
+func backupFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec for backup: %w", err)
+	}
+	if err := os.WriteFile(path+".orig", data, 0644); err != nil {
+		return fmt.Errorf("failed to back up OpenAPI spec: %w", err)
+	}
+	log.Infof("Backed up the original OpenAPI spec to %s.orig", path)
+	return nil
+}
+
+// specPointer builds a JSON pointer into the spec from unescaped reference tokens
+func specPointer(tokens ...string) string {
+	escaped := make([]string, len(tokens))
+	for i, token := range tokens {
+		escaped[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+	}
+	return "#/" + strings.Join(escaped, "/")
+}
+
+// forEachOperation visits every operation in a stable order
+func forEachOperation(doc *openapi3.T, visit func(path, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation)) {
+	for _, path := range sortedPaths(doc) {
+		pathItem := doc.Paths.Value(path)
+		for _, method := range sortedMethods(pathItem) {
+			visit(path, method, pathItem, pathItem.GetOperation(method))
+		}
+	}
+}
+
+// operationIDFor derives an operationId from the method and path, e.g. GET /tasks/{id}
+// becomes getTasksById
+func operationIDFor(method, path string) string {
+	words := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			words = append(words, "by")
+			segment = strings.Trim(segment, "{}")
+		}
+		words = append(words, strings.FieldsFunc(segment, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+
+	var id strings.Builder
+	for i, word := range words {
+		if word == "" {
+			continue
+		}
+		if i == 0 {
+			id.WriteString(word)
+			continue
+		}
+		runes := []rune(word)
+		id.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+	}
+	return id.String()
+}
+
+// uniqueOperationID appends a number to id until it is not in seen
+func uniqueOperationID(id string, seen map[string]bool) string {
+	if !seen[id] {
+		return id
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s%d", id, n)
+		if !seen[candidate] {
+			return candidate
+		}
+	}
+}
+
+// sortedResponseCodes returns the documented response codes in a stable order
+func sortedResponseCodes(responses *openapi3.Responses) []string {
+	var codes []string
+	for code := range responses.Map() {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// sortedParameterNames returns the names of component parameters in a stable order
+func sortedParameterNames(parameters openapi3.ParametersMap) []string {
+	var names []string
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -5,10 +5,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
-	"encoding/base64"
-
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/meter-peter/driveby/internal/openapi"
 )
@@ -18,6 +17,7 @@ type FunctionalTester struct {
 	config ValidatorConfig
 	loader *openapi.Loader
 	client *http.Client
+	impact testImpactIndex
 }
 
 // NewFunctionalTester creates a new functional tester instance
@@ -34,6 +34,12 @@ func NewFunctionalTester(config ValidatorConfig) *FunctionalTester {
 	}
 }
 
+// UseTestImpact makes the tester skip or downgrade the tests that the given spec
+// validation results report as affected
+func (t *FunctionalTester) UseTestImpact(principles []PrincipleResult) {
+	t.impact = newTestImpactIndex(principles)
+}
+
 // TestEndpoints runs functional tests against all endpoints
 func (t *FunctionalTester) TestEndpoints(ctx context.Context) (*ValidationReport, error) {
 	// Load OpenAPI spec
@@ -45,21 +51,65 @@ func (t *FunctionalTester) TestEndpoints(ctx context.Context) (*ValidationReport
 		return nil, fmt.Errorf("failed to get OpenAPI document")
 	}
 
+	startTime := time.Now()
+
 	// Test all endpoints
 	endpointResult, err := t.validateEndpoints(ctx, doc)
 	if err != nil {
 		return nil, fmt.Errorf("endpoint functional testing failed: %w", err)
 	}
 
+	// Report failures caused by spec problems as warnings
+	for i := range endpointResult.Endpoints {
+		epVal := &endpointResult.Endpoints[i]
+		if reason, ok := t.impact.downgradeReason(epVal.Method+" "+epVal.Path, TestKindEndpoint); ok {
+			epVal.Impact = reason
+			if epVal.Status == "error" {
+				epVal.Status = "warning"
+			}
+		}
+	}
+
+	// Chain create/read/update/delete operations against real resources
+	testCases := t.runScenarios(ctx, doc)
+
+	// Run user-authored test suites
+	suiteCases, err := t.runSuites(ctx, doc)
+	if err != nil {
+		return nil, fmt.Errorf("test suite execution failed: %w", err)
+	}
+	for key, cases := range suiteCases {
+		testCases[key] = append(testCases[key], cases...)
+	}
+
+	// Check that requests violating documented constraints are rejected
+	for key, cases := range t.runNegativeTests(ctx, doc) {
+		testCases[key] = append(testCases[key], cases...)
+	}
+
+	// Check that secured operations enforce authentication and OAuth2 scopes
+	authCases := t.runAuthTests(ctx, doc, endpointResult.Endpoints)
+	for key, cases := range authCases {
+		testCases[key] = append(testCases[key], cases...)
+	}
+
 	// Analyze results
 	allSuccess := true
 	var failedEndpoints []string
 	for _, epVal := range endpointResult.Endpoints {
-		if epVal.Status != "success" {
+		if epVal.Status != "success" && epVal.Impact == "" {
 			allSuccess = false
 			failedEndpoints = append(failedEndpoints, fmt.Sprintf("%s %s (Status: %s, Code: %d)", epVal.Method, epVal.Path, epVal.Status, epVal.StatusCode))
 		}
 	}
+	for key, cases := range testCases {
+		for _, tc := range cases {
+			if tc.Status == TestStatusFailed {
+				allSuccess = false
+				failedEndpoints = append(failedEndpoints, fmt.Sprintf("%s (Test case: %s)", key, tc.Name))
+			}
+		}
+	}
 
 	// Create report
 	principleResult := PrincipleResult{
@@ -88,10 +138,98 @@ func (t *FunctionalTester) TestEndpoints(ctx context.Context) (*ValidationReport
 	} else {
 		report.FailedChecks = 1
 	}
+	// Security the running API does not enforce also fails P005, next to its spec checks
+	if authResult, failed := t.authPrincipleResult(authCases); failed {
+		report.Principles = append(report.Principles, authResult)
+		report.TotalChecks++
+		report.FailedChecks++
+	}
+
+	functional := buildFunctionalResults(endpointResult.Endpoints, testCases)
+	report.TestResults = &TestResults{
+		Functional: functional,
+		StartTime:  startTime,
+		EndTime:    time.Now(),
+		Status:     TestStatusPassed,
+	}
+	if !allSuccess {
+		report.TestResults.Status = TestStatusFailed
+	}
 
 	return report, nil
 }
 
+// buildFunctionalResults converts endpoint validations and scenario test cases into FunctionalTestResults
+func buildFunctionalResults(endpoints []EndpointValidation, testCases map[string][]TestCaseResult) *FunctionalTestResults {
+	results := &FunctionalTestResults{
+		TotalEndpoints: len(endpoints),
+	}
+
+	var totalTime time.Duration
+	responded := 0
+	for _, epVal := range endpoints {
+		result := EndpointTestResult{
+			Method:       epVal.Method,
+			Path:         epVal.Path,
+			StatusCode:   epVal.StatusCode,
+			ResponseTime: epVal.ResponseTime,
+			Errors:       epVal.Errors,
+			TestCases:    testCases[epVal.Method+" "+epVal.Path],
+		}
+		if epVal.Impact != "" {
+			result.Notes = append(result.Notes, fmt.Sprintf("Endpoint failures are reported as warnings (%s)", epVal.Impact))
+		}
+
+		switch epVal.Status {
+		case "success":
+			result.Status = TestStatusPassed
+		case "warning":
+			result.Status = TestStatusWarning
+		default:
+			result.Status = TestStatusFailed
+		}
+		for _, tc := range result.TestCases {
+			switch tc.Status {
+			case TestStatusFailed:
+				result.Status = TestStatusFailed
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", tc.Name, tc.Error))
+			case TestStatusWarning:
+				if result.Status == TestStatusPassed {
+					result.Status = TestStatusWarning
+				}
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s", tc.Name, tc.Error))
+			}
+		}
+
+		switch result.Status {
+		case TestStatusPassed, TestStatusWarning:
+			results.PassedEndpoints++
+		case TestStatusSkipped:
+			results.SkippedEndpoints++
+		default:
+			results.FailedEndpoints++
+		}
+		results.TestedEndpoints++
+
+		if epVal.StatusCode != 0 {
+			responded++
+			totalTime += epVal.ResponseTime
+			if epVal.ResponseTime > results.MaxResponseTime {
+				results.MaxResponseTime = epVal.ResponseTime
+			}
+			if results.MinResponseTime == 0 || epVal.ResponseTime < results.MinResponseTime {
+				results.MinResponseTime = epVal.ResponseTime
+			}
+		}
+		results.EndpointResults = append(results.EndpointResults, result)
+	}
+
+	if responded > 0 {
+		results.AverageResponseTime = totalTime / time.Duration(responded)
+	}
+	return results
+}
+
 // validateEndpoints tests each endpoint in the OpenAPI spec
 func (t *FunctionalTester) validateEndpoints(ctx context.Context, doc *openapi3.T) (*EndpointValidationResult, error) {
 	result := &EndpointValidationResult{}
@@ -102,119 +240,111 @@ func (t *FunctionalTester) validateEndpoints(ctx context.Context, doc *openapi3.
 				continue
 			}
 
-			url := fmt.Sprintf("%s%s", t.config.BaseURL, path)
-			req, err := http.NewRequestWithContext(ctx, method, url, nil)
-			if err != nil {
-				result.Endpoints = append(result.Endpoints, EndpointValidation{
-					Method: method,
-					Path:   path,
-					Status: "error",
-					Errors: []string{fmt.Sprintf("Failed to create request: %v", err)},
-				})
-				continue
-			}
-
-			// Add authentication if configured
-			if t.config.Auth != nil {
-				if err := t.addAuthHeaders(req); err != nil {
-					result.Endpoints = append(result.Endpoints, EndpointValidation{
-						Method: method,
-						Path:   path,
-						Status: "error",
-						Errors: []string{fmt.Sprintf("Failed to add authentication: %v", err)},
-					})
-					continue
+			input := synthesizeRequest(pathItem, operation)
+			endpoint := t.executeOperation(ctx, method, path, operation, input)
+			// A rejection is expected when a parameter's pattern is too complex to generate
+			// a matching value for
+			if endpoint.Status == "error" && endpoint.StatusCode >= 400 && endpoint.StatusCode < 500 {
+				if names := unmatchedPatterns(pathItem, operation, input); len(names) > 0 {
+					endpoint.Status = "warning"
+					endpoint.Impact = fmt.Sprintf("no value matching the pattern of %s could be generated", strings.Join(names, ", "))
 				}
 			}
+			result.Endpoints = append(result.Endpoints, endpoint)
+		}
+	}
 
-			req.Header.Set("Accept", "application/json")
-			req.Header.Set("Content-Type", "application/json")
+	return result, nil
+}
 
-			startTime := time.Now()
-			resp, err := t.client.Do(req)
-			responseTime := time.Since(startTime)
+// executeOperation sends a single request for an operation and checks the response
+// status code and body against the documentation
+func (t *FunctionalTester) executeOperation(ctx context.Context, method, path string, operation *openapi3.Operation, input *RequestInput) EndpointValidation {
+	return t.executeOperationAs(ctx, t.config.Auth, method, path, operation, input)
+}
 
-			validation := EndpointValidation{
-				Method:       method,
-				Path:         path,
-				ResponseTime: responseTime,
-			}
+// executeOperationAs is executeOperation with the given credentials, or none when auth is nil
+func (t *FunctionalTester) executeOperationAs(ctx context.Context, auth *AuthConfig, method, path string, operation *openapi3.Operation, input *RequestInput) EndpointValidation {
+	req, err := buildHTTPRequest(ctx, t.config.BaseURL, method, path, input)
+	if err != nil {
+		return EndpointValidation{
+			Method:  method,
+			Path:    path,
+			Status:  "error",
+			Request: input,
+			Errors:  []string{fmt.Sprintf("Failed to create request: %v", err)},
+		}
+	}
 
-			if err != nil {
-				validation.Status = "error"
-				validation.Errors = []string{fmt.Sprintf("Request failed: %v", err)}
-			} else {
-				// Ensure response body is closed
-				body, err := io.ReadAll(resp.Body)
-				resp.Body.Close()
-				if err != nil {
-					validation.Status = "error"
-					validation.Errors = []string{fmt.Sprintf("Failed to read response body: %v", err)}
-				} else {
-					validation.StatusCode = resp.StatusCode
-					validation.ResponseBody = body
-
-					// Check if status code is documented
-					if _, documented := operation.Responses.Map()[fmt.Sprintf("%d", resp.StatusCode)]; documented {
-						// If documented, it's a success regardless of status code
-						validation.Status = "success"
-					} else {
-						validation.Status = "warning"
-						validation.Errors = []string{fmt.Sprintf("Status code %d is not documented in the OpenAPI spec", resp.StatusCode)}
-					}
-				}
+	// Add authentication if configured
+	if auth != nil {
+		if err := authorizeRequest(auth, t.loader.GetDocument(), operation, req); err != nil {
+			return EndpointValidation{
+				Method:  method,
+				Path:    path,
+				Status:  "error",
+				Request: input,
+				Errors:  []string{fmt.Sprintf("Failed to add authentication: %v", err)},
 			}
-
-			result.Endpoints = append(result.Endpoints, validation)
 		}
 	}
 
-	return result, nil
-}
+	startTime := time.Now()
+	resp, err := t.client.Do(req)
+	responseTime := time.Since(startTime)
 
-// addAuthHeaders adds authentication headers to the request based on the configured auth method
-func (t *FunctionalTester) addAuthHeaders(req *http.Request) error {
-	if t.config.Auth == nil {
-		return nil
+	validation := EndpointValidation{
+		Method:       method,
+		Path:         path,
+		ResponseTime: responseTime,
+		Request:      input,
 	}
 
-	// Only one authentication method should be used
-	authMethods := 0
-	if t.config.Auth.Token != "" {
-		authMethods++
-	}
-	if t.config.Auth.APIKey != "" {
-		authMethods++
+	if err != nil {
+		validation.Status = "error"
+		validation.Errors = []string{fmt.Sprintf("Request failed: %v", err)}
+		return validation
 	}
-	if t.config.Auth.Username != "" {
-		authMethods++
+
+	// Ensure response body is closed
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		validation.Status = "error"
+		validation.Errors = []string{fmt.Sprintf("Failed to read response body: %v", err)}
+		return validation
 	}
-	if authMethods > 1 {
-		return fmt.Errorf("only one authentication method can be specified")
+	validation.StatusCode = resp.StatusCode
+	validation.ResponseBody = body
+	validation.ResponseHeaders = resp.Header
+
+	// Check if status code is documented
+	if _, documented := operation.Responses.Map()[fmt.Sprintf("%d", resp.StatusCode)]; documented {
+		// If documented, it's a success regardless of status code
+		validation.Status = "success"
+	} else {
+		validation.Status = "warning"
+		validation.Errors = []string{fmt.Sprintf("Status code %d is not documented in the OpenAPI spec", resp.StatusCode)}
 	}
 
-	// Add the appropriate auth header
-	if t.config.Auth.Token != "" {
-		headerName := t.config.Auth.TokenHeader
-		if headerName == "" {
-			headerName = "Authorization"
-		}
-		tokenType := t.config.Auth.TokenType
-		if tokenType == "" {
-			tokenType = "Bearer"
-		}
-		req.Header.Set(headerName, fmt.Sprintf("%s %s", tokenType, t.config.Auth.Token))
-	} else if t.config.Auth.APIKey != "" {
-		headerName := t.config.Auth.APIKeyHeader
-		if headerName == "" {
-			headerName = "X-API-Key"
+	// Check the body against the schema documented for this status and content type
+	mismatches, err := validateResponseBody(operation, resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		validation.Status = "error"
+		validation.Errors = append(validation.Errors, fmt.Sprintf("Response schema validation failed: %v", err))
+	} else if len(mismatches) > 0 {
+		validation.Status = "error"
+		validation.SchemaErrors = mismatches
+		for _, mismatch := range mismatches {
+			validation.Errors = append(validation.Errors, fmt.Sprintf("Response schema mismatch at %s", mismatch))
 		}
-		req.Header.Set(headerName, t.config.Auth.APIKey)
-	} else if t.config.Auth.Username != "" {
-		// Basic auth
-		auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", t.config.Auth.Username, t.config.Auth.Password)))
-		req.Header.Set("Authorization", fmt.Sprintf("Basic %s", auth))
 	}
 
-	return nil
+	return validation
+}
+
+// addAuthHeaders adds the configured credentials to a request as the operation's security
+// requirements ask for them
+func (t *FunctionalTester) addAuthHeaders(req *http.Request, operation *openapi3.Operation) error {
+	return authorizeRequest(t.config.Auth, t.loader.GetDocument(), operation, req)
 }
@@ -0,0 +1,757 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/meter-peter/driveby/internal/openapi"
+)
+
+// Fuzz failure kinds
+const (
+	FuzzFailureServerError        = "server_error"
+	FuzzFailureTimeout            = "timeout"
+	FuzzFailureUndocumentedStatus = "undocumented_status"
+	FuzzFailureConnection         = "connection_error"
+)
+
+const (
+	defaultFuzzIterations = 1000
+	maxShrinkAttempts     = 200
+	maxFuzzDepth          = 5
+	fuzzMutationRate      = 0.2
+)
+
+// FuzzTestResults contains the results of a fuzzing run
+type FuzzTestResults struct {
+	Seed       int64
+	Iterations int
+	Operations int
+	Duration   time.Duration
+	Status     TestStatus
+	Failures   []FuzzFailure
+}
+
+// FuzzFailure is a unique failure found while fuzzing, together with the minimal input
+// that still reproduces it
+type FuzzFailure struct {
+	Kind        string
+	Method      string
+	Path        string
+	OperationID string
+	StatusCode  int
+	Error       string
+	Seed        int64
+	Iteration   int
+	Occurrences int
+	ShrinkSteps int
+	Input       *RequestInput // Shrunk reproducer
+	Original    *RequestInput // Input as first generated
+	Curl        string
+}
+
+// signature identifies failures that are considered the same finding
+func (f *FuzzFailure) signature() string {
+	return fmt.Sprintf("%s %s %s %d", f.Kind, f.Method, f.Path, f.StatusCode)
+}
+
+// Fuzzer sends randomized requests generated from the OpenAPI spec
+type Fuzzer struct {
+	config ValidatorConfig
+	loader *openapi.Loader
+	tester *FunctionalTester
+}
+
+// fuzzTarget is an operation that can be fuzzed
+type fuzzTarget struct {
+	method    string
+	path      string
+	pathItem  *openapi3.PathItem
+	operation *openapi3.Operation
+}
+
+// NewFuzzer creates a new fuzzer instance
+func NewFuzzer(config ValidatorConfig) *Fuzzer {
+	tester := NewFunctionalTester(config)
+	return &Fuzzer{
+		config: tester.config,
+		loader: tester.loader,
+		tester: tester,
+	}
+}
+
+// Fuzz sends randomized requests to every documented operation until the iteration or
+// time budget is spent. Server errors, timeouts and undocumented status codes are
+// recorded once per operation and status, with the failing input shrunk to a minimal
+// reproducer.
+func (f *Fuzzer) Fuzz(ctx context.Context) (*ValidationReport, error) {
+	if err := f.loader.LoadFromFileOrURL(f.config.SpecPath); err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+	doc := f.loader.GetDocument()
+	if doc == nil {
+		return nil, fmt.Errorf("failed to get OpenAPI document")
+	}
+
+	targets := fuzzTargets(doc)
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no operations to fuzz")
+	}
+
+	cfg := FuzzConfig{}
+	if f.config.Fuzz != nil {
+		cfg = *f.config.Fuzz
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	if cfg.Iterations == 0 && cfg.Duration == 0 {
+		cfg.Iterations = defaultFuzzIterations
+	}
+
+	startTime := time.Now()
+	var deadline time.Time
+	if cfg.Duration > 0 {
+		deadline = startTime.Add(cfg.Duration)
+	}
+	log.Infof("Fuzzing %d operations with seed %d", len(targets), cfg.Seed)
+
+	results := &FuzzTestResults{Seed: cfg.Seed, Operations: len(targets)}
+	seen := make(map[string]int)
+	for i := 0; cfg.Iterations == 0 || i < cfg.Iterations; i++ {
+		if ctx.Err() != nil || (!deadline.IsZero() && time.Now().After(deadline)) {
+			break
+		}
+
+		// Each iteration has its own source so any single input can be regenerated
+		// from the seed and iteration number
+		rng := rand.New(rand.NewSource(cfg.Seed + int64(i)*1000003))
+		target := targets[rng.Intn(len(targets))]
+		input := (&fuzzGenerator{rng: rng}).request(target.pathItem, target.operation)
+		results.Iterations++
+
+		failure, err := f.check(ctx, target, input)
+		if err != nil {
+			return nil, err
+		}
+		if failure == nil {
+			continue
+		}
+		if idx, ok := seen[failure.signature()]; ok {
+			results.Failures[idx].Occurrences++
+			continue
+		}
+
+		failure.Seed = cfg.Seed
+		failure.Iteration = i
+		failure.Occurrences = 1
+		failure.Original = input
+		failure.Input, failure.ShrinkSteps = f.shrink(ctx, target, input, failure)
+		failure.Curl = curlCommand(f.config.BaseURL, target.method, target.path, failure.Input)
+		log.Infof("Fuzzing found %s on %s %s (status %d), shrunk in %d steps", failure.Kind, failure.Method, failure.Path, failure.StatusCode, failure.ShrinkSteps)
+
+		seen[failure.signature()] = len(results.Failures)
+		results.Failures = append(results.Failures, *failure)
+	}
+	results.Duration = time.Since(startTime)
+
+	results.Status = TestStatusPassed
+	if len(results.Failures) > 0 {
+		results.Status = TestStatusFailed
+	}
+
+	principleResult := PrincipleResult{
+		Principle: CorePrinciples[5], // P006: Endpoint Functional Testing
+		Passed:    len(results.Failures) == 0,
+		Details:   results,
+	}
+	if principleResult.Passed {
+		principleResult.Message = fmt.Sprintf("No failures found in %d fuzzed requests (seed %d).", results.Iterations, results.Seed)
+	} else {
+		principleResult.Message = fmt.Sprintf("Fuzzing found %d unique failures in %d requests (seed %d).", len(results.Failures), results.Iterations, results.Seed)
+	}
+
+	report := &ValidationReport{
+		Version:     f.config.Version,
+		Environment: f.config.Environment,
+		Timestamp:   time.Now(),
+		Principles:  []PrincipleResult{principleResult},
+		TotalChecks: 1,
+		TestResults: &TestResults{
+			Fuzz:      results,
+			StartTime: startTime,
+			EndTime:   time.Now(),
+			Status:    results.Status,
+		},
+	}
+	if principleResult.Passed {
+		report.PassedChecks = 1
+	} else {
+		report.FailedChecks = 1
+	}
+
+	return report, nil
+}
+
+// fuzzTargets lists the non-deprecated operations of a document in a stable order
+func fuzzTargets(doc *openapi3.T) []fuzzTarget {
+	var targets []fuzzTarget
+	for path, pathItem := range doc.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			if operation.Deprecated {
+				continue
+			}
+			targets = append(targets, fuzzTarget{method: method, path: path, pathItem: pathItem, operation: operation})
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].path != targets[j].path {
+			return targets[i].path < targets[j].path
+		}
+		return targets[i].method < targets[j].method
+	})
+	return targets
+}
+
+// check sends one fuzzed request and returns a failure when the API answers with a 5xx
+// or undocumented status, times out or drops the connection. The error is only set when
+// the request could not be authenticated.
+func (f *Fuzzer) check(ctx context.Context, target fuzzTarget, input *RequestInput) (*FuzzFailure, error) {
+	req, err := buildHTTPRequest(ctx, f.config.BaseURL, target.method, target.path, input)
+	if err != nil {
+		// Inputs that cannot be encoded say nothing about the API
+		return nil, nil
+	}
+	if err := f.tester.addAuthHeaders(req, target.operation); err != nil {
+		return nil, fmt.Errorf("failed to add authentication: %w", err)
+	}
+
+	failure := &FuzzFailure{
+		Method:      target.method,
+		Path:        target.path,
+		OperationID: target.operation.OperationID,
+	}
+
+	resp, err := f.tester.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			failure.Kind = FuzzFailureTimeout
+		} else {
+			failure.Kind = FuzzFailureConnection
+		}
+		failure.Error = err.Error()
+		return failure, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	failure.StatusCode = resp.StatusCode
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		failure.Kind = FuzzFailureServerError
+		failure.Error = fmt.Sprintf("server error with status %d", resp.StatusCode)
+	case target.operation.Responses == nil ||
+		(target.operation.Responses.Status(resp.StatusCode) == nil && target.operation.Responses.Default() == nil):
+		failure.Kind = FuzzFailureUndocumentedStatus
+		failure.Error = fmt.Sprintf("status code %d is not documented", resp.StatusCode)
+	default:
+		return nil, nil
+	}
+	return failure, nil
+}
+
+// shrink greedily simplifies a failing input for as long as the simplified input still
+// produces the same failure. Timeouts and connection errors are not shrunk, since every
+// attempt would wait for the full timeout and the input is rarely the cause.
+func (f *Fuzzer) shrink(ctx context.Context, target fuzzTarget, input *RequestInput, failure *FuzzFailure) (*RequestInput, int) {
+	if failure.Kind == FuzzFailureTimeout || failure.Kind == FuzzFailureConnection {
+		return input, 0
+	}
+
+	current := input
+	steps, attempts := 0, 0
+	for attempts < maxShrinkAttempts {
+		improved := false
+		for _, candidate := range shrinkRequest(current) {
+			if attempts >= maxShrinkAttempts || ctx.Err() != nil {
+				break
+			}
+			attempts++
+			got, err := f.check(ctx, target, candidate)
+			if err == nil && got != nil && got.signature() == failure.signature() {
+				current = candidate
+				steps++
+				improved = true
+				break
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return current, steps
+}
+
+// shrinkRequest returns simpler variants of a request input, most aggressive first
+func shrinkRequest(input *RequestInput) []*RequestInput {
+	var candidates []*RequestInput
+
+	if input.Body != nil {
+		candidate := cloneRequestInput(input)
+		candidate.Body = nil
+		candidates = append(candidates, candidate)
+	}
+
+	removable := []func(*RequestInput) map[string]interface{}{
+		func(in *RequestInput) map[string]interface{} { return in.QueryParams },
+		func(in *RequestInput) map[string]interface{} { return in.Headers },
+		func(in *RequestInput) map[string]interface{} { return in.Cookies },
+	}
+	for _, values := range removable {
+		for _, name := range sortedInputKeys(values(input)) {
+			candidate := cloneRequestInput(input)
+			delete(values(candidate), name)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if input.Body != nil {
+		for _, body := range shrinkValue(input.Body) {
+			candidate := cloneRequestInput(input)
+			candidate.Body = body
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	params := []func(*RequestInput) map[string]interface{}{
+		func(in *RequestInput) map[string]interface{} { return in.PathParams },
+		func(in *RequestInput) map[string]interface{} { return in.QueryParams },
+		func(in *RequestInput) map[string]interface{} { return in.Headers },
+		func(in *RequestInput) map[string]interface{} { return in.Cookies },
+	}
+	for _, values := range params {
+		for _, name := range sortedInputKeys(values(input)) {
+			for _, value := range shrinkValue(values(input)[name]) {
+				candidate := cloneRequestInput(input)
+				values(candidate)[name] = value
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+
+	return candidates
+}
+
+// shrinkValue returns simpler variants of a JSON value
+func shrinkValue(value interface{}) []interface{} {
+	var candidates []interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := sortedInputKeys(v)
+		for _, key := range keys {
+			smaller := cloneJSON(v).(map[string]interface{})
+			delete(smaller, key)
+			candidates = append(candidates, smaller)
+		}
+		for _, key := range keys {
+			for _, child := range shrinkValue(v[key]) {
+				smaller := cloneJSON(v).(map[string]interface{})
+				smaller[key] = child
+				candidates = append(candidates, smaller)
+			}
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			break
+		}
+		candidates = append(candidates, []interface{}{})
+		if len(v) > 1 {
+			candidates = append(candidates, cloneJSON(v[:len(v)/2]))
+		}
+		for i := range v {
+			smaller := append(cloneJSON(v[:i]).([]interface{}), cloneJSON(v[i+1:]).([]interface{})...)
+			candidates = append(candidates, smaller)
+		}
+		for i := range v {
+			for _, child := range shrinkValue(v[i]) {
+				smaller := cloneJSON(v).([]interface{})
+				smaller[i] = child
+				candidates = append(candidates, smaller)
+			}
+		}
+	case string:
+		runes := []rune(v)
+		if len(runes) > 0 {
+			candidates = append(candidates, "")
+		}
+		if len(runes) > 1 {
+			candidates = append(candidates, string(runes[:len(runes)/2]))
+		}
+	case float64:
+		if v != 0 {
+			candidates = append(candidates, 0.0)
+		}
+		if math.Abs(v) > 1 && !math.IsInf(v, 0) {
+			candidates = append(candidates, math.Trunc(v/2))
+		}
+	case bool:
+		if v {
+			candidates = append(candidates, false)
+		}
+	}
+	return candidates
+}
+
+// cloneRequestInput deep-copies a request input
+func cloneRequestInput(input *RequestInput) *RequestInput {
+	clone := &RequestInput{
+		PathParams:  make(map[string]interface{}, len(input.PathParams)),
+		QueryParams: make(map[string]interface{}, len(input.QueryParams)),
+		Headers:     make(map[string]interface{}, len(input.Headers)),
+		Cookies:     make(map[string]interface{}, len(input.Cookies)),
+		Body:        cloneJSON(input.Body),
+		ContentType: input.ContentType,
+	}
+	for k, v := range input.PathParams {
+		clone.PathParams[k] = cloneJSON(v)
+	}
+	for k, v := range input.QueryParams {
+		clone.QueryParams[k] = cloneJSON(v)
+	}
+	for k, v := range input.Headers {
+		clone.Headers[k] = cloneJSON(v)
+	}
+	for k, v := range input.Cookies {
+		clone.Cookies[k] = cloneJSON(v)
+	}
+	return clone
+}
+
+// curlCommand renders a request input as a curl command line. Authentication headers
+// are left out so reproducers can be shared.
+func curlCommand(baseURL, method, path string, input *RequestInput) string {
+	req, err := buildHTTPRequest(context.Background(), baseURL, method, path, input)
+	if err != nil {
+		return ""
+	}
+
+	parts := []string{"curl", "-X", method}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			parts = append(parts, "-H", shellQuote(name+": "+value))
+		}
+	}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err == nil && len(body) > 0 {
+			parts = append(parts, "--data-raw", shellQuote(string(body)))
+		}
+	}
+	parts = append(parts, shellQuote(req.URL.String()))
+	return strings.Join(parts, " ")
+}
+
+// shellQuote wraps a value in single quotes for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// fuzzGenerator produces random values from schemas. Most values respect the schema;
+// a share are deliberately hostile (boundary violations, wrong types, huge or special
+// strings) to probe input handling.
+type fuzzGenerator struct {
+	rng *rand.Rand
+}
+
+// fuzzStrings are values known to upset parsers, templating and storage layers
+var fuzzStrings = []string{
+	"'",
+	`"`,
+	"\\",
+	"' OR '1'='1",
+	"<script>alert(1)</script>",
+	"../../../../etc/passwd",
+	"%00",
+	"\x00",
+	"{{7*7}}",
+	"${jndi:ldap://localhost/a}",
+	"\U0001F600",
+	"\uFEFF",
+	"\u202E",
+	"NaN",
+	"-0",
+	"1e309",
+	"null",
+	"[]",
+	"{}",
+}
+
+// fuzzNumbers are numeric edge cases
+var fuzzNumbers = []float64{
+	0, -1, 1, math.MaxInt32 + 1, math.MinInt32 - 1, 1 << 53, -(1 << 53), 1e308, -1e308, 0.1, -0.5,
+}
+
+const fuzzAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 -_.,;:!?@#$%&*()[]{}/+=~éüß漢字"
+
+// request generates a random request for an operation. Path and required parameters are
+// always present; optional parameters and bodies are included at random.
+func (g *fuzzGenerator) request(pathItem *openapi3.PathItem, operation *openapi3.Operation) *RequestInput {
+	input := &RequestInput{
+		PathParams:  make(map[string]interface{}),
+		QueryParams: make(map[string]interface{}),
+		Headers:     make(map[string]interface{}),
+		Cookies:     make(map[string]interface{}),
+	}
+
+	for _, ref := range operationParameters(pathItem, operation) {
+		param := ref.Value
+		if reservedHeaders[strings.ToLower(param.Name)] && param.In == openapi3.ParameterInHeader {
+			continue
+		}
+		if !param.Required && param.In != openapi3.ParameterInPath && g.rng.Intn(2) == 0 {
+			continue
+		}
+		var schema *openapi3.Schema
+		if param.Schema != nil {
+			schema = param.Schema.Value
+		}
+		value := g.value(schema, 0)
+
+		switch param.In {
+		case openapi3.ParameterInPath:
+			// An empty segment changes the route rather than the parameter
+			if openapi.FormatParameterValue(value) == "" {
+				value = openapi.ParameterExample(param)
+			}
+			input.PathParams[param.Name] = value
+		case openapi3.ParameterInQuery:
+			input.QueryParams[param.Name] = value
+		case openapi3.ParameterInHeader:
+			input.Headers[param.Name] = printableASCII(openapi.FormatParameterValue(value))
+		case openapi3.ParameterInCookie:
+			input.Cookies[param.Name] = printableASCII(openapi.FormatParameterValue(value))
+		}
+	}
+
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		body := operation.RequestBody.Value
+		contentType, mediaType := openapi.JSONMediaType(body.Content)
+		if mediaType != nil && (body.Required || g.rng.Intn(10) != 0) {
+			input.ContentType = contentType
+			var schema *openapi3.Schema
+			if mediaType.Schema != nil {
+				schema = mediaType.Schema.Value
+			}
+			input.Body = g.value(schema, 0)
+		}
+	}
+
+	return input
+}
+
+// value generates a random value for a schema
+func (g *fuzzGenerator) value(schema *openapi3.Schema, depth int) interface{} {
+	if schema == nil || depth > maxFuzzDepth {
+		return g.primitive()
+	}
+	if g.rng.Float64() < fuzzMutationRate {
+		return g.mutant(schema)
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[g.rng.Intn(len(schema.Enum))]
+	}
+	if alternatives := append(append(openapi3.SchemaRefs{}, schema.OneOf...), schema.AnyOf...); len(alternatives) > 0 {
+		if ref := alternatives[g.rng.Intn(len(alternatives))]; ref != nil {
+			return g.value(ref.Value, depth+1)
+		}
+	}
+	if len(schema.AllOf) > 0 {
+		properties, required := flattenObjectSchema(schema)
+		return g.object(properties, required, depth)
+	}
+
+	switch schema.Type {
+	case "string":
+		return g.str(schema)
+	case "integer":
+		return math.Round(g.number(schema))
+	case "number":
+		return g.number(schema)
+	case "boolean":
+		return g.rng.Intn(2) == 0
+	case "array":
+		return g.array(schema, depth)
+	case "object":
+		return g.object(schema.Properties, schema.Required, depth)
+	}
+	switch {
+	case len(schema.Properties) > 0:
+		return g.object(schema.Properties, schema.Required, depth)
+	case schema.Items != nil:
+		return g.array(schema, depth)
+	}
+	return g.primitive()
+}
+
+// mutant generates a hostile value for a schema
+func (g *fuzzGenerator) mutant(schema *openapi3.Schema) interface{} {
+	switch g.rng.Intn(8) {
+	case 0:
+		return nil
+	case 1:
+		return strings.Repeat("A", 1024+g.rng.Intn(8192))
+	case 2:
+		return fuzzStrings[g.rng.Intn(len(fuzzStrings))]
+	case 3:
+		return fuzzNumbers[g.rng.Intn(len(fuzzNumbers))]
+	case 4:
+		return g.rng.Intn(2) == 0
+	case 5:
+		return []interface{}{nil}
+	case 6:
+		return map[string]interface{}{}
+	default:
+		if violations := schemaViolations(schema); len(violations) > 0 {
+			return violations[g.rng.Intn(len(violations))].value
+		}
+		return ""
+	}
+}
+
+// primitive generates a random scalar of a random type
+func (g *fuzzGenerator) primitive() interface{} {
+	switch g.rng.Intn(3) {
+	case 0:
+		return g.str(&openapi3.Schema{})
+	case 1:
+		return g.number(&openapi3.Schema{})
+	default:
+		return g.rng.Intn(2) == 0
+	}
+}
+
+// str generates a string within the schema's length limits, using a valid value for
+// the declared format half of the time
+func (g *fuzzGenerator) str(schema *openapi3.Schema) string {
+	if (schema.Format != "" || schema.Pattern != "") && g.rng.Intn(2) == 0 {
+		if value, ok := openapi.SchemaExample(schema).(string); ok {
+			return value
+		}
+	}
+
+	minLength := int(schema.MinLength)
+	maxLength := minLength + 24
+	if schema.MaxLength != nil && int(*schema.MaxLength) < maxLength {
+		maxLength = int(*schema.MaxLength)
+	}
+	length := minLength
+	if maxLength > minLength {
+		length += g.rng.Intn(maxLength - minLength + 1)
+	}
+
+	alphabet := []rune(fuzzAlphabet)
+	runes := make([]rune, length)
+	for i := range runes {
+		runes[i] = alphabet[g.rng.Intn(len(alphabet))]
+	}
+	return string(runes)
+}
+
+// number generates a number within the schema's bounds, picking the bounds themselves
+// now and then
+func (g *fuzzGenerator) number(schema *openapi3.Schema) float64 {
+	low, high := -1000.0, 1000.0
+	switch {
+	case schema.Min != nil && schema.Max != nil:
+		low, high = *schema.Min, *schema.Max
+	case schema.Min != nil:
+		low, high = *schema.Min, *schema.Min+1000
+	case schema.Max != nil:
+		low, high = *schema.Max-1000, *schema.Max
+	}
+
+	switch g.rng.Intn(8) {
+	case 0:
+		if !schema.ExclusiveMin {
+			return low
+		}
+	case 1:
+		if !schema.ExclusiveMax {
+			return high
+		}
+	}
+	return low + g.rng.Float64()*(high-low)
+}
+
+// array generates an array within the schema's item limits
+func (g *fuzzGenerator) array(schema *openapi3.Schema, depth int) []interface{} {
+	count := int(schema.MinItems) + g.rng.Intn(4)
+	if schema.MaxItems != nil && count > int(*schema.MaxItems) {
+		count = int(*schema.MaxItems)
+	}
+	items := make([]interface{}, count)
+	for i := range items {
+		var itemSchema *openapi3.Schema
+		if schema.Items != nil {
+			itemSchema = schema.Items.Value
+		}
+		items[i] = g.value(itemSchema, depth+1)
+	}
+	return items
+}
+
+// object generates an object with all required and a random subset of optional properties
+func (g *fuzzGenerator) object(properties openapi3.Schemas, required []string, depth int) map[string]interface{} {
+	requiredSet := make(map[string]bool, len(required))
+	for _, name := range required {
+		requiredSet[name] = true
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	obj := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		prop := properties[name]
+		if prop == nil || prop.Value == nil || prop.Value.ReadOnly {
+			continue
+		}
+		if !requiredSet[name] && g.rng.Intn(2) == 0 {
+			continue
+		}
+		obj[name] = g.value(prop.Value, depth+1)
+	}
+	return obj
+}
+
+// printableASCII drops characters that cannot be sent in a header or cookie value
+func printableASCII(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return -1
+		}
+		return r
+	}, value)
+}
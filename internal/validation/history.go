@@ -0,0 +1,248 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryDirName is the name of the run history directory in the report directory
+const HistoryDirName = "history"
+
+// historyTimeFormat names history files so that they sort chronologically
+const historyTimeFormat = "20060102T150405.000Z"
+
+// HistoryRetention limits how much of the run history is kept
+type HistoryRetention struct {
+	MaxRuns int           // Runs kept per environment; unlimited when 0
+	MaxAge  time.Duration // Runs older than this are removed; kept forever when 0
+}
+
+// HistoryRecord is a validation report kept in the run history
+type HistoryRecord struct {
+	ID      string            `json:"id"`
+	Command string            `json:"command"` // CLI command that produced the report
+	Report  *ValidationReport `json:"report"`
+}
+
+// HistoryFilter selects runs from the history. Empty fields match every run.
+type HistoryFilter struct {
+	Environment string
+	Version     string
+	Command     string
+	Limit       int // Most recent runs returned; all when 0
+}
+
+// HistoryStore persists validation reports as one JSON file per run, named by
+// timestamp, environment and version
+type HistoryStore struct {
+	dir       string
+	retention HistoryRetention
+}
+
+// NewHistoryStore creates a history store in the given directory
+func NewHistoryStore(dir string, retention HistoryRetention) *HistoryStore {
+	return &HistoryStore{
+		dir:       dir,
+		retention: retention,
+	}
+}
+
+// unsafeIDChars matches the characters of environments and versions that are replaced in run IDs
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// runIDPattern matches a run ID, which never leaves the history directory
+var runIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Save records a report and applies the retention to its environment. It returns the run ID.
+func (s *HistoryStore) Save(command string, report *ValidationReport) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create history directory: %w", err)
+	}
+	id := strings.Join([]string{
+		report.Timestamp.UTC().Format(historyTimeFormat),
+		unsafeIDChars.ReplaceAllString(report.Environment, "-"),
+		unsafeIDChars.ReplaceAllString(report.Version, "-"),
+	}, "_")
+	data, err := json.MarshalIndent(HistoryRecord{ID: id, Command: command, Report: report}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal history record: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, id+".json"), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write history record: %w", err)
+	}
+	if err := s.prune(report.Environment); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// List returns the runs matching the filter, most recent first
+func (s *HistoryStore) List(filter HistoryFilter) ([]HistoryRecord, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+	var records []HistoryRecord
+	for _, id := range ids {
+		record, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if !filter.matches(record) {
+			continue
+		}
+		records = append(records, *record)
+		if filter.Limit > 0 && len(records) == filter.Limit {
+			break
+		}
+	}
+	return records, nil
+}
+
+// Get returns a single run
+func (s *HistoryStore) Get(id string) (*HistoryRecord, error) {
+	if !runIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid run ID %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("run %s not found in %s", id, s.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run %s: %w", id, err)
+	}
+	var record HistoryRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse run %s: %w", id, err)
+	}
+	if record.Report == nil {
+		return nil, fmt.Errorf("run %s has no report", id)
+	}
+	return &record, nil
+}
+
+// prune removes the runs of an environment beyond the retention limits
+func (s *HistoryStore) prune(environment string) error {
+	if s.retention.MaxRuns <= 0 && s.retention.MaxAge <= 0 {
+		return nil
+	}
+	records, err := s.List(HistoryFilter{Environment: environment})
+	if err != nil {
+		return err
+	}
+	for i, record := range records {
+		expired := s.retention.MaxAge > 0 && time.Since(record.Report.Timestamp) > s.retention.MaxAge
+		if (s.retention.MaxRuns > 0 && i >= s.retention.MaxRuns) || expired {
+			if err := os.Remove(filepath.Join(s.dir, record.ID+".json")); err != nil {
+				return fmt.Errorf("failed to remove run %s: %w", record.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether a run passes the filter
+func (f HistoryFilter) matches(record *HistoryRecord) bool {
+	return (f.Environment == "" || record.Report.Environment == f.Environment) &&
+		(f.Version == "" || record.Report.Version == f.Version) &&
+		(f.Command == "" || record.Command == f.Command)
+}
+
+// RunSummary holds the figures of a run that are followed over time
+type RunSummary struct {
+	ID             string
+	Command        string
+	Environment    string
+	Version        string
+	Timestamp      time.Time
+	TotalChecks    int
+	PassedChecks   int
+	PassRate       float64 // Passed checks over total checks
+	CriticalIssues int     // Failed critical principles
+	Status         TestStatus
+	HasPerformance bool // The run included a load test
+	LatencyP95     time.Duration
+	RequestsPerSec float64
+}
+
+// SummarizeRun extracts the followed figures from a run
+func SummarizeRun(record HistoryRecord) RunSummary {
+	report := record.Report
+	summary := RunSummary{
+		ID:           record.ID,
+		Command:      record.Command,
+		Environment:  report.Environment,
+		Version:      report.Version,
+		Timestamp:    report.Timestamp,
+		TotalChecks:  report.TotalChecks,
+		PassedChecks: report.PassedChecks,
+	}
+	if report.TotalChecks > 0 {
+		summary.PassRate = float64(report.PassedChecks) / float64(report.TotalChecks)
+	}
+	for _, principle := range report.Principles {
+		if !principle.Passed && principle.Principle.Severity == "critical" {
+			summary.CriticalIssues++
+		}
+	}
+	if report.TestResults != nil {
+		summary.Status = report.TestResults.Status
+		if performance := report.TestResults.Performance; performance != nil {
+			summary.HasPerformance = true
+			summary.LatencyP95 = performance.LatencyP95
+			summary.RequestsPerSec = performance.RequestsPerSecond
+		}
+	}
+	return summary
+}
+
+// HistoryTrend follows runs over time
+type HistoryTrend struct {
+	Runs                 []RunSummary // Oldest first
+	PassRateChange       float64      // Percentage points from the first to the last run
+	CriticalIssuesChange int
+	LatencyP95Change     float64 // Relative change between the first and last runs with a load test
+}
+
+// NewHistoryTrend computes the trend of runs given most recent first, as List returns them
+func NewHistoryTrend(records []HistoryRecord) HistoryTrend {
+	var trend HistoryTrend
+	for i := len(records) - 1; i >= 0; i-- {
+		trend.Runs = append(trend.Runs, SummarizeRun(records[i]))
+	}
+	if len(trend.Runs) < 2 {
+		return trend
+	}
+	first, last := trend.Runs[0], trend.Runs[len(trend.Runs)-1]
+	trend.PassRateChange = (last.PassRate - first.PassRate) * 100
+	trend.CriticalIssuesChange = last.CriticalIssues - first.CriticalIssues
+
+	var loadRuns []RunSummary
+	for _, run := range trend.Runs {
+		if run.HasPerformance {
+			loadRuns = append(loadRuns, run)
+		}
+	}
+	if len(loadRuns) >= 2 {
+		trend.LatencyP95Change = relativeChange(float64(loadRuns[0].LatencyP95), float64(loadRuns[len(loadRuns)-1].LatencyP95))
+	}
+	return trend
+}
@@ -0,0 +1,305 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Kinds of tests that a spec validation finding can affect
+const (
+	TestKindEndpoint = "endpoint" // The generated request sent to each operation
+	TestKindNegative = "negative" // Constraint-violating requests
+	TestKindAuth     = "auth"     // Authentication enforcement checks
+	TestKindLoad     = "load"     // Load test targets
+)
+
+// OperationImpact describes how a finding in a single operation affects its tests
+type OperationImpact struct {
+	Operation  string   // "METHOD path"
+	Skipped    []string // Test kinds that cannot run
+	Downgraded []string // Test kinds whose failures are reported as warnings
+	Reason     string
+}
+
+// assessTestImpact determines, operation by operation, which tests a principle's findings
+// prevent or weaken. Principles without operation-level findings have no impact. Tests
+// cannot run when every operation has all the tests that apply to it skipped.
+func assessTestImpact(principleID string, doc *openapi3.T) *TestImpact {
+	impact := &TestImpact{
+		ImpactLevel: ImpactLevelNone,
+	}
+
+	operations := 0
+	blocked := 0
+	for _, path := range sortedPaths(doc) {
+		pathItem := doc.Paths.Value(path)
+		for _, method := range sortedMethods(pathItem) {
+			operation := pathItem.GetOperation(method)
+			if operation.Deprecated {
+				continue
+			}
+			operations++
+			key := method + " " + path
+			before := len(impact.Operations)
+			switch principleID {
+			case "P002":
+				if !hasSuccessResponse(operation) {
+					impact.Operations = append(impact.Operations, OperationImpact{
+						Operation:  key,
+						Skipped:    []string{TestKindLoad},
+						Downgraded: []string{TestKindEndpoint},
+						Reason:     "no 2xx response is documented, so a successful call has no documented outcome",
+					})
+				}
+			case "P003":
+				if len(documentedClientErrorCodes(operation)) == 0 {
+					impact.Operations = append(impact.Operations, OperationImpact{
+						Operation:  key,
+						Downgraded: []string{TestKindNegative},
+						Reason:     "no 4xx response is documented, so rejecting invalid input is not part of the contract",
+					})
+				}
+			case "P004":
+				if opImpact, ok := requestSchemaImpact(key, pathItem, operation); ok {
+					impact.Operations = append(impact.Operations, opImpact)
+				}
+			case "P005":
+				if !hasSecurityRequirement(doc, operation) {
+					impact.Operations = append(impact.Operations, OperationImpact{
+						Operation: key,
+						Skipped:   []string{TestKindAuth},
+						Reason:    "no security scheme applies, so there is no authentication to check",
+					})
+				}
+			}
+			if len(impact.Operations) > before && skipsAllTests(doc, operation, impact.Operations[before]) {
+				blocked++
+			}
+		}
+	}
+	impact.CanRunTests = operations == 0 || blocked < operations
+
+	affected := make(map[string]bool)
+	for _, opImpact := range impact.Operations {
+		for _, kind := range append(append([]string{}, opImpact.Skipped...), opImpact.Downgraded...) {
+			affected[kind] = true
+		}
+		if level := operationImpactLevel(opImpact); impactRank(level) > impactRank(impact.ImpactLevel) {
+			impact.ImpactLevel = level
+		}
+	}
+	for kind := range affected {
+		impact.AffectedTests = append(impact.AffectedTests, kind)
+	}
+	sort.Strings(impact.AffectedTests)
+
+	if len(impact.Operations) > 0 {
+		switch principleID {
+		case "P002":
+			impact.Recommendations = append(impact.Recommendations, "Document a 2xx response for every operation")
+		case "P003":
+			impact.Recommendations = append(impact.Recommendations, "Document the 4xx responses returned for invalid requests")
+		case "P004":
+			impact.Recommendations = append(impact.Recommendations, "Add schemas to all request bodies and parameters")
+		case "P005":
+			impact.Recommendations = append(impact.Recommendations, "Apply a security scheme to every operation that requires authentication")
+		}
+	}
+	return impact
+}
+
+// requestSchemaImpact reports operations whose request cannot be generated because a
+// body or parameter schema is missing
+func requestSchemaImpact(key string, pathItem *openapi3.PathItem, operation *openapi3.Operation) (OperationImpact, bool) {
+	if body := operation.RequestBody; body != nil && body.Value != nil {
+		missing := len(body.Value.Content) == 0
+		for _, mediaType := range body.Value.Content {
+			if mediaType.Schema == nil || mediaType.Schema.Value == nil {
+				missing = true
+			}
+		}
+		if missing {
+			return OperationImpact{
+				Operation:  key,
+				Skipped:    []string{TestKindNegative, TestKindLoad},
+				Downgraded: []string{TestKindEndpoint},
+				Reason:     "the request body has no schema, so a valid body cannot be generated",
+			}, true
+		}
+	}
+
+	var unschemed []string
+	requiredMissing := false
+	for _, ref := range operationParameters(pathItem, operation) {
+		if ref.Value == nil || ref.Value.Schema != nil || ref.Value.Content != nil {
+			continue
+		}
+		unschemed = append(unschemed, ref.Value.Name)
+		if ref.Value.Required {
+			requiredMissing = true
+		}
+	}
+	if len(unschemed) == 0 {
+		return OperationImpact{}, false
+	}
+	opImpact := OperationImpact{
+		Operation:  key,
+		Downgraded: []string{TestKindEndpoint},
+		Reason:     fmt.Sprintf("parameters without schema (%s) cannot be generated", strings.Join(unschemed, ", ")),
+	}
+	if requiredMissing {
+		opImpact.Skipped = []string{TestKindLoad}
+	}
+	return opImpact, true
+}
+
+// skipsAllTests reports whether an operation impact skips every kind of test that applies
+// to the operation. Authentication tests only apply to secured operations.
+func skipsAllTests(doc *openapi3.T, operation *openapi3.Operation, opImpact OperationImpact) bool {
+	kinds := []string{TestKindEndpoint, TestKindNegative, TestKindLoad}
+	if hasSecurityRequirement(doc, operation) {
+		kinds = append(kinds, TestKindAuth)
+	}
+	for _, kind := range kinds {
+		if !containsString(opImpact.Skipped, kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSuccessResponse reports whether an operation documents a 2xx response
+func hasSuccessResponse(operation *openapi3.Operation) bool {
+	if operation.Responses == nil {
+		return false
+	}
+	for code := range operation.Responses.Map() {
+		if strings.HasPrefix(code, "2") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSecurityRequirement reports whether a non-empty security requirement applies to an
+// operation, either its own or the document's
+func hasSecurityRequirement(doc *openapi3.T, operation *openapi3.Operation) bool {
+	if operation.Security != nil {
+		return len(*operation.Security) > 0
+	}
+	return len(doc.Security) > 0
+}
+
+// operationImpactLevel rates how much of an operation's testing is lost
+func operationImpactLevel(opImpact OperationImpact) ImpactLevel {
+	for _, kind := range opImpact.Skipped {
+		if kind == TestKindEndpoint || kind == TestKindLoad {
+			return ImpactLevelHigh
+		}
+	}
+	if len(opImpact.Skipped) > 0 {
+		return ImpactLevelMedium
+	}
+	if len(opImpact.Downgraded) > 0 {
+		return ImpactLevelLow
+	}
+	return ImpactLevelNone
+}
+
+// impactRank orders impact levels from none to critical
+func impactRank(level ImpactLevel) int {
+	switch level {
+	case ImpactLevelLow:
+		return 1
+	case ImpactLevelMedium:
+		return 2
+	case ImpactLevelHigh:
+		return 3
+	case ImpactLevelCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// sortedPaths returns the document's paths in a stable order
+func sortedPaths(doc *openapi3.T) []string {
+	var paths []string
+	if doc.Paths == nil {
+		return paths
+	}
+	for path := range doc.Paths.Map() {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// sortedMethods returns a path item's methods in a stable order
+func sortedMethods(pathItem *openapi3.PathItem) []string {
+	var methods []string
+	for method := range pathItem.Operations() {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// testImpactIndex looks up, per operation and test kind, why spec validation findings skip
+// or downgrade a test
+type testImpactIndex map[string]*operationTestImpact
+
+type operationTestImpact struct {
+	skipped    map[string][]string // Test kind to reasons
+	downgraded map[string][]string
+}
+
+// newTestImpactIndex indexes the test impact recorded on spec validation results
+func newTestImpactIndex(principles []PrincipleResult) testImpactIndex {
+	index := make(testImpactIndex)
+	for _, principle := range principles {
+		if principle.TestImpact == nil {
+			continue
+		}
+		for _, opImpact := range principle.TestImpact.Operations {
+			entry, ok := index[opImpact.Operation]
+			if !ok {
+				entry = &operationTestImpact{
+					skipped:    make(map[string][]string),
+					downgraded: make(map[string][]string),
+				}
+				index[opImpact.Operation] = entry
+			}
+			reason := fmt.Sprintf("%s: %s", principle.Principle.ID, opImpact.Reason)
+			for _, kind := range opImpact.Skipped {
+				entry.skipped[kind] = append(entry.skipped[kind], reason)
+			}
+			for _, kind := range opImpact.Downgraded {
+				entry.downgraded[kind] = append(entry.downgraded[kind], reason)
+			}
+		}
+	}
+	return index
+}
+
+// skipReason returns why a kind of test cannot run for an operation
+func (idx testImpactIndex) skipReason(operation, kind string) (string, bool) {
+	entry, ok := idx[operation]
+	if !ok || len(entry.skipped[kind]) == 0 {
+		return "", false
+	}
+	return strings.Join(entry.skipped[kind], "; "), true
+}
+
+// downgradeReason returns why failures of a kind of test are reported as warnings for an
+// operation
+func (idx testImpactIndex) downgradeReason(operation, kind string) (string, bool) {
+	entry, ok := idx[operation]
+	if !ok || len(entry.downgraded[kind]) == 0 {
+		return "", false
+	}
+	return strings.Join(entry.downgraded[kind], "; "), true
+}
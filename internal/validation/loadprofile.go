@@ -0,0 +1,281 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	vegeta "github.com/tsenart/vegeta/v12/lib"
+)
+
+// LoadProfileType names the shape of the request rate over a load test
+type LoadProfileType string
+
+const (
+	LoadProfileConstant LoadProfileType = "constant" // Rate for the whole test
+	LoadProfileRamp     LoadProfileType = "ramp"     // Linear increase from StartRate to Rate
+	LoadProfileStep     LoadProfileType = "step"     // Staircase of Steps levels from StartRate to Rate
+	LoadProfileSpike    LoadProfileType = "spike"    // StartRate with a burst at Rate in the middle
+	LoadProfileSoak     LoadProfileType = "soak"     // Rate held for a long time, reported in Steps windows
+	LoadProfileCapacity LoadProfileType = "capacity" // Search for the highest rate that meets the targets
+)
+
+// Defaults for unset load profile settings
+const (
+	defaultProfileSteps   = 5
+	defaultStartRateRatio = 10 // StartRate defaults to Rate divided by this
+)
+
+// LoadProfile describes how the request rate changes over a load test. Rates are in
+// requests per second.
+type LoadProfile struct {
+	Type          LoadProfileType
+	StartRate     int           // Initial rate of ramp and step, baseline of spike; defaults to Rate/10
+	Steps         int           // Stages of ramp, step and soak; defaults to 5
+	SpikeDuration time.Duration // Length of the spike; defaults to a fifth of the test
+	ProbeDuration time.Duration // Length of each capacity probe; defaults to 10s
+	MaxProbes     int           // Probes a capacity search may run; defaults to 10
+}
+
+// loadStage is a part of a load test whose rate changes linearly from startRate to endRate
+type loadStage struct {
+	name      string
+	duration  time.Duration
+	startRate float64
+	endRate   float64
+}
+
+// hits returns the number of requests a stage sends in its first elapsed duration
+func (s loadStage) hits(elapsed time.Duration) float64 {
+	t := elapsed.Seconds()
+	slope := (s.endRate - s.startRate) / s.duration.Seconds()
+	return s.startRate*t + slope*t*t/2
+}
+
+// rate returns the stage's request rate after an elapsed duration
+func (s loadStage) rate(elapsed time.Duration) float64 {
+	return s.startRate + (s.endRate-s.startRate)*elapsed.Seconds()/s.duration.Seconds()
+}
+
+// timeForHits returns when, within the stage, the given number of requests has been sent
+func (s loadStage) timeForHits(hits float64) time.Duration {
+	slope := (s.endRate - s.startRate) / s.duration.Seconds()
+	var seconds float64
+	if slope == 0 {
+		seconds = hits / s.startRate
+	} else {
+		seconds = (-s.startRate + math.Sqrt(s.startRate*s.startRate+2*slope*hits)) / slope
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// loadRate returns the peak request rate, which falls back to the concurrent users setting
+func (c *PerformanceTargetConfig) loadRate() int {
+	if c.Rate > 0 {
+		return c.Rate
+	}
+	return c.ConcurrentUsers
+}
+
+// loadDuration returns the test duration
+func (c *PerformanceTargetConfig) loadDuration() time.Duration {
+	if c.Duration > 0 {
+		return c.Duration
+	}
+	return 5 * time.Minute // Default duration
+}
+
+// loadStages expands the configured load profile into stages
+func (c *PerformanceTargetConfig) loadStages() ([]loadStage, error) {
+	rate := float64(c.loadRate())
+	if rate <= 0 {
+		return nil, fmt.Errorf("load rate must be greater than 0")
+	}
+	duration := c.loadDuration()
+	profile := c.Profile
+
+	startRate := float64(profile.StartRate)
+	if startRate == 0 {
+		startRate = math.Max(1, math.Floor(rate/defaultStartRateRatio))
+	}
+	if startRate < 0 || (startRate > rate && profile.Type != LoadProfileCapacity) {
+		return nil, fmt.Errorf("start rate must be between 1 and the load rate (%d)", int(rate))
+	}
+	steps := profile.Steps
+	if steps == 0 {
+		steps = defaultProfileSteps
+	}
+	if steps < 0 {
+		return nil, fmt.Errorf("profile steps must be greater than 0")
+	}
+	stepDuration := duration / time.Duration(steps)
+
+	var stages []loadStage
+	switch profile.Type {
+	case "", LoadProfileConstant:
+		stages = append(stages, loadStage{name: "constant", duration: duration, startRate: rate, endRate: rate})
+	case LoadProfileRamp:
+		for i := 0; i < steps; i++ {
+			stages = append(stages, loadStage{
+				name:      fmt.Sprintf("ramp %d/%d", i+1, steps),
+				duration:  stepDuration,
+				startRate: startRate + (rate-startRate)*float64(i)/float64(steps),
+				endRate:   startRate + (rate-startRate)*float64(i+1)/float64(steps),
+			})
+		}
+	case LoadProfileStep:
+		for i := 0; i < steps; i++ {
+			level := rate
+			if steps > 1 {
+				level = math.Round(startRate + (rate-startRate)*float64(i)/float64(steps-1))
+			}
+			stages = append(stages, loadStage{
+				name:      fmt.Sprintf("step %d/%d", i+1, steps),
+				duration:  stepDuration,
+				startRate: level,
+				endRate:   level,
+			})
+		}
+	case LoadProfileSpike:
+		spike := profile.SpikeDuration
+		if spike == 0 {
+			spike = duration / 5
+		}
+		if spike >= duration {
+			return nil, fmt.Errorf("spike duration (%s) must be shorter than the test duration (%s)", spike, duration)
+		}
+		before := (duration - spike) / 2
+		stages = append(stages,
+			loadStage{name: "baseline", duration: before, startRate: startRate, endRate: startRate},
+			loadStage{name: "spike", duration: spike, startRate: rate, endRate: rate},
+			loadStage{name: "recovery", duration: duration - spike - before, startRate: startRate, endRate: startRate},
+		)
+	case LoadProfileSoak:
+		for i := 0; i < steps; i++ {
+			stages = append(stages, loadStage{
+				name:      fmt.Sprintf("soak %d/%d", i+1, steps),
+				duration:  stepDuration,
+				startRate: rate,
+				endRate:   rate,
+			})
+		}
+	case LoadProfileCapacity:
+		// The capacity search chooses its rates as it goes
+		if c.MaxLatencyP95 <= 0 && c.MinSuccessRate <= 0 {
+			return nil, fmt.Errorf("the capacity profile needs a P95 latency or success rate target")
+		}
+		if profile.MaxProbes < 0 || profile.ProbeDuration < 0 {
+			return nil, fmt.Errorf("capacity probes and probe duration must not be negative")
+		}
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown load profile %q (expected constant, ramp, step, spike, soak or capacity)", profile.Type)
+	}
+
+	for _, stage := range stages {
+		if stage.duration <= 0 {
+			return nil, fmt.Errorf("test duration (%s) is too short for %d stages", duration, len(stages))
+		}
+	}
+	return stages, nil
+}
+
+// stagedPacer paces an attack through a sequence of load stages
+type stagedPacer struct {
+	stages []loadStage
+}
+
+var _ vegeta.Pacer = stagedPacer{}
+
+// Pace implements vegeta.Pacer by waiting until the next request is due. Requests that
+// are behind schedule are sent at once.
+func (p stagedPacer) Pace(elapsed time.Duration, hits uint64) (time.Duration, bool) {
+	var offset time.Duration
+	var planned float64
+	next := float64(hits + 1)
+	for _, stage := range p.stages {
+		stageHits := stage.hits(stage.duration)
+		if planned+stageHits >= next {
+			due := offset + stage.timeForHits(next-planned)
+			if due < elapsed {
+				return 0, false
+			}
+			return due - elapsed, false
+		}
+		offset += stage.duration
+		planned += stageHits
+	}
+	return 0, true // Every planned request has been sent
+}
+
+// Rate implements vegeta.Pacer
+func (p stagedPacer) Rate(elapsed time.Duration) float64 {
+	var offset time.Duration
+	for _, stage := range p.stages {
+		if elapsed < offset+stage.duration {
+			return stage.rate(elapsed - offset)
+		}
+		offset += stage.duration
+	}
+	return 0
+}
+
+// stageCollector keeps load test metrics per stage
+type stageCollector struct {
+	stages  []loadStage
+	start   time.Time
+	metrics []*vegeta.Metrics
+}
+
+func newStageCollector(stages []loadStage) *stageCollector {
+	collector := &stageCollector{stages: stages, metrics: make([]*vegeta.Metrics, len(stages))}
+	for i := range collector.metrics {
+		collector.metrics[i] = &vegeta.Metrics{}
+	}
+	return collector
+}
+
+// begin marks the start of the attack that stage offsets are measured from
+func (c *stageCollector) begin(start time.Time) {
+	c.start = start
+}
+
+// add attributes a result to the stage during which it was sent
+func (c *stageCollector) add(res *vegeta.Result) {
+	elapsed := res.Timestamp.Sub(c.start)
+	var offset time.Duration
+	for i, stage := range c.stages {
+		offset += stage.duration
+		if elapsed < offset || i == len(c.stages)-1 {
+			c.metrics[i].Add(res)
+			return
+		}
+	}
+}
+
+// results closes the per-stage metrics and checks each stage's P95 against the target
+func (c *stageCollector) results(maxLatencyP95 time.Duration) []StageMetrics {
+	var results []StageMetrics
+	var offset time.Duration
+	for i, stage := range c.stages {
+		metrics := c.metrics[i]
+		metrics.Close()
+		_, _, errorRate := requestOutcomes(metrics)
+		results = append(results, StageMetrics{
+			Name:           stage.name,
+			Start:          offset,
+			Duration:       stage.duration,
+			StartRate:      stage.startRate,
+			EndRate:        stage.endRate,
+			TotalRequests:  metrics.Requests,
+			RequestsPerSec: float64(metrics.Requests) / stage.duration.Seconds(),
+			ErrorRate:      errorRate,
+			LatencyP50:     metrics.Latencies.P50,
+			LatencyP95:     metrics.Latencies.P95,
+			LatencyP99:     metrics.Latencies.P99,
+			WithinTarget:   maxLatencyP95 == 0 || metrics.Latencies.P95 <= maxLatencyP95,
+		})
+		offset += stage.duration
+	}
+	return results
+}
@@ -0,0 +1,133 @@
+package validation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStagedPacerPace(t *testing.T) {
+	pacer := stagedPacer{stages: []loadStage{
+		{name: "ramp", duration: 2 * time.Second, startRate: 0, endRate: 8}, // 8 requests
+		{name: "constant", duration: time.Second, startRate: 20, endRate: 20},
+	}}
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		hits    uint64
+		wait    time.Duration
+		stop    bool
+	}{
+		{name: "second request of the ramp", elapsed: 0, hits: 1, wait: time.Second},
+		{name: "last request of the ramp", elapsed: 1500 * time.Millisecond, hits: 7, wait: 500 * time.Millisecond},
+		{name: "first request of the next stage", elapsed: 2 * time.Second, hits: 8, wait: 50 * time.Millisecond},
+		{name: "behind schedule", elapsed: 2500 * time.Millisecond, hits: 9},
+		{name: "every request sent", elapsed: 3 * time.Second, hits: 28, stop: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, stop := pacer.Pace(tt.elapsed, tt.hits)
+			if stop != tt.stop || (wait-tt.wait).Abs() > time.Microsecond {
+				t.Errorf("Pace(%s, %d) = %s, %v, want %s, %v", tt.elapsed, tt.hits, wait, stop, tt.wait, tt.stop)
+			}
+		})
+	}
+}
+
+func TestStagedPacerRate(t *testing.T) {
+	pacer := stagedPacer{stages: []loadStage{
+		{name: "ramp", duration: 2 * time.Second, startRate: 0, endRate: 8},
+		{name: "constant", duration: time.Second, startRate: 20, endRate: 20},
+	}}
+	tests := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{elapsed: 0, want: 0},
+		{elapsed: time.Second, want: 4},
+		{elapsed: 2500 * time.Millisecond, want: 20},
+		{elapsed: 4 * time.Second, want: 0},
+	}
+	for _, tt := range tests {
+		if got := pacer.Rate(tt.elapsed); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Rate(%s) = %v, want %v", tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestLoadStages(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PerformanceTargetConfig
+		want    []loadStage
+		wantErr bool
+	}{
+		{
+			name:   "constant",
+			config: PerformanceTargetConfig{Rate: 50, Duration: time.Minute},
+			want:   []loadStage{{name: "constant", duration: time.Minute, startRate: 50, endRate: 50}},
+		},
+		{
+			name:   "ramp",
+			config: PerformanceTargetConfig{Rate: 100, Duration: time.Minute, Profile: LoadProfile{Type: LoadProfileRamp, StartRate: 20, Steps: 2}},
+			want: []loadStage{
+				{name: "ramp 1/2", duration: 30 * time.Second, startRate: 20, endRate: 60},
+				{name: "ramp 2/2", duration: 30 * time.Second, startRate: 60, endRate: 100},
+			},
+		},
+		{
+			name:   "step",
+			config: PerformanceTargetConfig{Rate: 100, Duration: time.Minute, Profile: LoadProfile{Type: LoadProfileStep, Steps: 3}},
+			want: []loadStage{
+				{name: "step 1/3", duration: 20 * time.Second, startRate: 10, endRate: 10},
+				{name: "step 2/3", duration: 20 * time.Second, startRate: 55, endRate: 55},
+				{name: "step 3/3", duration: 20 * time.Second, startRate: 100, endRate: 100},
+			},
+		},
+		{
+			name:   "spike",
+			config: PerformanceTargetConfig{Rate: 100, Duration: 100 * time.Second, Profile: LoadProfile{Type: LoadProfileSpike, StartRate: 10}},
+			want: []loadStage{
+				{name: "baseline", duration: 40 * time.Second, startRate: 10, endRate: 10},
+				{name: "spike", duration: 20 * time.Second, startRate: 100, endRate: 100},
+				{name: "recovery", duration: 40 * time.Second, startRate: 10, endRate: 10},
+			},
+		},
+		{
+			name:   "concurrent users as rate",
+			config: PerformanceTargetConfig{ConcurrentUsers: 5, Duration: time.Second},
+			want:   []loadStage{{name: "constant", duration: time.Second, startRate: 5, endRate: 5}},
+		},
+		{
+			name:    "start rate above rate",
+			config:  PerformanceTargetConfig{Rate: 10, Duration: time.Minute, Profile: LoadProfile{Type: LoadProfileRamp, StartRate: 20}},
+			wantErr: true,
+		},
+		{
+			name:    "spike longer than the test",
+			config:  PerformanceTargetConfig{Rate: 10, Duration: time.Minute, Profile: LoadProfile{Type: LoadProfileSpike, SpikeDuration: time.Minute}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown profile",
+			config:  PerformanceTargetConfig{Rate: 10, Duration: time.Minute, Profile: LoadProfile{Type: "wave"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.loadStages()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadStages() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("loadStages() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("stage %d is %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
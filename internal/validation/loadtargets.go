@@ -0,0 +1,304 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	vegeta "github.com/tsenart/vegeta/v12/lib"
+)
+
+// Weight keys that cover a class of operations
+const (
+	LoadWeightReads  = "reads"  // GET, HEAD and OPTIONS operations
+	LoadWeightWrites = "writes" // Every other method
+)
+
+// loadTarget is an operation's prepared load test request and its share of the traffic
+type loadTarget struct {
+	operation   string // "METHOD path"
+	operationID string
+	method      string
+	path        string
+	tags        []string
+	target      vegeta.Target
+	weight      float64
+	authorize   func(*http.Request) error // Renews the credentials of requests that carry an OAuth2 token; nil otherwise
+}
+
+// current returns the target's request with the current OAuth2 token. Should the token
+// endpoint fail, the request keeps its last token and the API's answer is recorded.
+func (l *loadTarget) current() vegeta.Target {
+	if l.authorize == nil {
+		return l.target
+	}
+	req, err := http.NewRequest(l.target.Method, l.target.URL, nil)
+	if err != nil {
+		return l.target
+	}
+	req.Header = l.target.Header.Clone()
+	if err := l.authorize(req); err != nil {
+		log.Debugf("Keeping the previous credentials of %s: %v", l.operation, err)
+		return l.target
+	}
+	target := l.target
+	target.Header = req.Header
+	return target
+}
+
+// tagTargetURL marks a target's URL with the operation it sends, so that results, which
+// carry the URL of their target, are attributed to the right operation even when two
+// operations synthesize the same URL, such as GET /tasks/{id} and GET /tasks/{name}. The
+// tag is a URL fragment, which is not sent to the API.
+func tagTargetURL(targetURL, operation string) string {
+	return targetURL + "#" + url.PathEscape(operation)
+}
+
+// loadTargetSet holds the operations that a load test can send
+type loadTargetSet struct {
+	operations   map[string]*loadTarget // Every prepared operation, which journeys may use
+	operationIDs map[string]string
+	weighted     []*loadTarget     // Operations with a positive weight, ordered by path and method
+	skipped      []string          // Operations left out of the load test and why
+	excluded     map[string]string // Operations journeys may not use and why
+}
+
+// buildLoadTargets prepares a request for every operation selected by the include and
+// exclude filters, with parameters and bodies taken from the spec's examples and schemas
+// and the configured authentication applied, then assigns the traffic weights
+func (t *PerformanceTester) buildLoadTargets(doc *openapi3.T) *loadTargetSet {
+	targets := t.config.PerformanceTarget
+	set := &loadTargetSet{
+		operations:   make(map[string]*loadTarget),
+		operationIDs: make(map[string]string),
+		excluded:     make(map[string]string),
+	}
+
+	var prepared []*loadTarget
+	for _, path := range sortedPaths(doc) {
+		pathItem := doc.Paths.Value(path)
+		for _, method := range sortedMethods(pathItem) {
+			operation := pathItem.GetOperation(method)
+			key := method + " " + path
+			if !targets.selects(key, operation) {
+				set.excluded[key] = "excluded by the load test filters"
+				continue
+			}
+			if reason, ok := t.impact.skipReason(key, TestKindLoad); ok {
+				set.skipped = append(set.skipped, fmt.Sprintf("%s (%s)", key, reason))
+				set.excluded[key] = reason
+				continue
+			}
+			target, err := t.loadRequest(pathItem, operation, method, path)
+			if err != nil {
+				reason := fmt.Sprintf("cannot build a request: %v", err)
+				set.skipped = append(set.skipped, fmt.Sprintf("%s (%s)", key, reason))
+				set.excluded[key] = reason
+				continue
+			}
+			target.URL = tagTargetURL(target.URL, key)
+			loadTarget := &loadTarget{
+				operation:   key,
+				operationID: operation.OperationID,
+				method:      method,
+				path:        path,
+				tags:        operation.Tags,
+				target:      target,
+			}
+			if auth := t.config.Auth; auth != nil && auth.OAuth2 != nil {
+				operation := operation
+				loadTarget.authorize = func(req *http.Request) error {
+					return authorizeRequest(auth, doc, operation, req)
+				}
+			}
+			set.operations[key] = loadTarget
+			if operation.OperationID != "" {
+				set.operationIDs[operation.OperationID] = key
+			}
+			prepared = append(prepared, loadTarget)
+		}
+	}
+
+	for _, target := range prepared {
+		target.weight = targets.operationWeight(target, prepared)
+		if target.weight > 0 {
+			set.weighted = append(set.weighted, target)
+		} else if _, own := targets.ownWeight(target); !own && (target.method == "DELETE" || target.method == "PATCH") {
+			set.skipped = append(set.skipped, fmt.Sprintf("%s (DELETE and PATCH operations are only load tested when given a weight)", target.operation))
+		}
+	}
+	return set
+}
+
+// loadRequest renders an operation's synthesized request, with authentication, as a vegeta target
+func (t *PerformanceTester) loadRequest(pathItem *openapi3.PathItem, operation *openapi3.Operation, method, path string) (vegeta.Target, error) {
+	input := synthesizeRequest(pathItem, operation)
+	req, err := buildHTTPRequest(context.Background(), t.config.BaseURL, method, path, input)
+	if err != nil {
+		return vegeta.Target{}, err
+	}
+	if err := authorizeRequest(t.config.Auth, t.loader.GetDocument(), operation, req); err != nil {
+		return vegeta.Target{}, err
+	}
+	var body []byte
+	if req.Body != nil {
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return vegeta.Target{}, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	return vegeta.Target{
+		Method: method,
+		URL:    req.URL.String(),
+		Body:   body,
+		Header: req.Header,
+	}, nil
+}
+
+// selects reports whether the include and exclude filters let an operation into the load test
+func (c *PerformanceTargetConfig) selects(key string, operation *openapi3.Operation) bool {
+	if len(c.Include) > 0 && !matchesAnyFilter(c.Include, key, operation) {
+		return false
+	}
+	return !matchesAnyFilter(c.Exclude, key, operation)
+}
+
+// matchesAnyFilter reports whether an operation matches a filter given as "METHOD path",
+// operationId or "tag:<name>"
+func matchesAnyFilter(filters []string, key string, operation *openapi3.Operation) bool {
+	for _, filter := range filters {
+		filter = normalizeOperationKey(filter)
+		if filter == key || (operation.OperationID != "" && filter == operation.OperationID) {
+			return true
+		}
+		if tag, ok := strings.CutPrefix(filter, "tag:"); ok {
+			for _, opTag := range operation.Tags {
+				if opTag == tag {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// operationWeight resolves an operation's traffic weight. A weight keyed by "METHOD path"
+// or operationId applies to that operation alone. A weight keyed by "tag:<name>", or by
+// "reads" or "writes", is split evenly between the operations it covers that have no
+// weight of their own; tags take precedence over classes. Operations without any weight
+// get 1, except DELETE and PATCH, which get 0.
+func (c *PerformanceTargetConfig) operationWeight(target *loadTarget, all []*loadTarget) float64 {
+	if weight, ok := c.ownWeight(target); ok {
+		return weight
+	}
+	group, ok := c.weightGroup(target)
+	if !ok {
+		if target.method == "DELETE" || target.method == "PATCH" {
+			return 0
+		}
+		return 1
+	}
+	members := 0
+	for _, other := range all {
+		if _, own := c.ownWeight(other); own {
+			continue
+		}
+		if otherGroup, ok := c.weightGroup(other); ok && otherGroup == group {
+			members++
+		}
+	}
+	return c.Weights[group] / float64(members)
+}
+
+// ownWeight returns the weight configured for an operation itself
+func (c *PerformanceTargetConfig) ownWeight(target *loadTarget) (float64, bool) {
+	if weight, ok := c.Weights[target.operation]; ok {
+		return weight, true
+	}
+	if target.operationID != "" {
+		if weight, ok := c.Weights[target.operationID]; ok {
+			return weight, true
+		}
+	}
+	return 0, false
+}
+
+// weightGroup returns the tag or class weight key that covers an operation
+func (c *PerformanceTargetConfig) weightGroup(target *loadTarget) (string, bool) {
+	for _, tag := range target.tags {
+		if _, ok := c.Weights["tag:"+tag]; ok {
+			return "tag:" + tag, true
+		}
+	}
+	class := LoadWeightWrites
+	switch target.method {
+	case "GET", "HEAD", "OPTIONS":
+		class = LoadWeightReads
+	}
+	if _, ok := c.Weights[class]; ok {
+		return class, true
+	}
+	return "", false
+}
+
+// weightedTargeter returns a targeter that spreads requests across targets in proportion
+// to their weights, using smooth weighted round-robin so the mix is even at any point in
+// the run rather than only on average
+func weightedTargeter(targets []*loadTarget) vegeta.Targeter {
+	var mu sync.Mutex
+	current := make([]float64, len(targets))
+	total := 0.0
+	for _, target := range targets {
+		total += target.weight
+	}
+	return func(tgt *vegeta.Target) error {
+		if tgt == nil {
+			return vegeta.ErrNilTarget
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		best := 0
+		for i, target := range targets {
+			current[i] += target.weight
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		*tgt = targets[best].current()
+		return nil
+	}
+}
+
+// ParseLoadWeight parses a traffic weight of the form "<key>=<weight>", where the key is
+// "METHOD path", an operationId, "tag:<name>", "reads" or "writes"
+func ParseLoadWeight(value string) (string, float64, error) {
+	idx := strings.LastIndex(value, "=")
+	if idx <= 0 {
+		return "", 0, fmt.Errorf("invalid load weight %q: expected <operation|tag:name|reads|writes>=<weight>", value)
+	}
+	key := normalizeOperationKey(value[:idx])
+	weight, err := strconv.ParseFloat(strings.TrimSpace(value[idx+1:]), 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid load weight for %s: %w", key, err)
+	}
+	if weight < 0 {
+		return "", 0, fmt.Errorf("load weight for %s must not be negative", key)
+	}
+	return key, weight, nil
+}
+
+// normalizeOperationKey trims an operation reference and upper-cases the method of a
+// "METHOD path" reference
+func normalizeOperationKey(value string) string {
+	value = strings.TrimSpace(value)
+	if method, path, ok := strings.Cut(value, " "); ok {
+		return strings.ToUpper(method) + " " + strings.TrimSpace(path)
+	}
+	return value
+}
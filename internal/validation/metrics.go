@@ -1,16 +1,23 @@
 package validation
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
 
 // EndpointValidation represents the result of validating a single endpoint
 type EndpointValidation struct {
-	Method       string        `json:"method"`
-	Path         string        `json:"path"`
-	Status       string        `json:"status"`
-	StatusCode   int           `json:"status_code"`
-	ResponseTime time.Duration `json:"response_time"`
-	ResponseBody []byte        `json:"response_body,omitempty"`
-	Errors       []string      `json:"errors,omitempty"`
+	Method          string           `json:"method"`
+	Path            string           `json:"path"`
+	Status          string           `json:"status"`
+	StatusCode      int              `json:"status_code"`
+	ResponseTime    time.Duration    `json:"response_time"`
+	Request         *RequestInput    `json:"request,omitempty"`
+	ResponseBody    []byte           `json:"response_body,omitempty"`
+	ResponseHeaders http.Header      `json:"-"`
+	Errors          []string         `json:"errors,omitempty"`
+	SchemaErrors    []SchemaMismatch `json:"schema_errors,omitempty"`
+	Impact          string           `json:"impact,omitempty"` // Why failures are reported as warnings
 }
 
 // EndpointValidationResult holds the results of endpoint validation
@@ -20,16 +27,125 @@ type EndpointValidationResult struct {
 
 // PerformanceMetrics holds metrics from a performance test run
 type PerformanceMetrics struct {
-	StartTime      time.Time     `json:"start_time"`
-	EndTime        time.Time     `json:"end_time"`
+	StartTime      time.Time           `json:"start_time"`
+	EndTime        time.Time           `json:"end_time"`
+	TotalRequests  uint64              `json:"total_requests"`
+	SuccessCount   uint64              `json:"success_count"`
+	ErrorCount     uint64              `json:"error_count"`
+	ErrorRate      float64             `json:"error_rate"`
+	LatencyP50     time.Duration       `json:"latency_p50"`
+	LatencyP95     time.Duration       `json:"latency_p95"`
+	LatencyP99     time.Duration       `json:"latency_p99"`
+	RequestsPerSec float64             `json:"requests_per_sec"`
+	SkippedTargets []string            `json:"skipped_targets,omitempty"`
+	Endpoints      []EndpointMetrics   `json:"endpoints,omitempty"`
+	FailedRequests []FailedRequest     `json:"failed_requests,omitempty"`
+	LoadProfile    LoadProfileType     `json:"load_profile,omitempty"`
+	Stages         []StageMetrics      `json:"stages,omitempty"`
+	LoadModel      LoadModel           `json:"load_model"`
+	VirtualUsers   int                 `json:"virtual_users,omitempty"`
+	Journeys       []JourneyMetrics    `json:"journeys,omitempty"`
+	Capacity       *CapacityResult     `json:"capacity,omitempty"`
+	Baseline       *BaselineComparison `json:"baseline,omitempty"`
+}
+
+// BaselineComparison holds the comparison of a load test with a recorded baseline
+type BaselineComparison struct {
+	Environment         string        `json:"environment,omitempty"` // Environment and version of the baseline
+	Version             string        `json:"version,omitempty"`
+	RecordedAt          time.Time     `json:"recorded_at,omitempty"`
+	Skipped             string        `json:"skipped,omitempty"` // Why the run was not compared
+	BaselineLatencyP95  time.Duration `json:"baseline_latency_p95,omitempty"`
+	LatencyP95          time.Duration `json:"latency_p95,omitempty"`
+	LatencyP95Change    float64       `json:"latency_p95_change"` // Relative to the baseline
+	BaselineThroughput  float64       `json:"baseline_throughput,omitempty"`
+	Throughput          float64       `json:"throughput,omitempty"` // Successful requests per second, or the maximum sustainable rate
+	ThroughputChange    float64       `json:"throughput_change"`
+	P95Tolerance        float64       `json:"p95_tolerance"`
+	ThroughputTolerance float64       `json:"throughput_tolerance"`
+	Regressions         []string      `json:"regressions,omitempty"`
+	Recorded            bool          `json:"recorded"` // The run became the baseline of its environment and version
+}
+
+// CapacityResult holds the outcome of a search for the maximum sustainable request rate
+type CapacityResult struct {
+	MaxSustainableRate int             `json:"max_sustainable_rate"`     // Highest probed rate that met the targets
+	BreakingPoint      int             `json:"breaking_point,omitempty"` // Lowest probed rate that missed them
+	BreakingReason     string          `json:"breaking_reason,omitempty"`
+	CeilingReached     bool            `json:"ceiling_reached,omitempty"` // The search stopped at the configured peak rate
+	Curve              []CapacityProbe `json:"curve"`                     // Probes ordered by rate
+}
+
+// CapacityProbe holds the metrics of a single constant-rate probe
+type CapacityProbe struct {
+	Rate           int           `json:"rate"` // Planned requests per second
+	RequestsPerSec float64       `json:"requests_per_sec"`
 	TotalRequests  uint64        `json:"total_requests"`
-	SuccessCount   uint64        `json:"success_count"`
-	ErrorCount     uint64        `json:"error_count"`
 	ErrorRate      float64       `json:"error_rate"`
 	LatencyP50     time.Duration `json:"latency_p50"`
 	LatencyP95     time.Duration `json:"latency_p95"`
 	LatencyP99     time.Duration `json:"latency_p99"`
-	RequestsPerSec float64       `json:"requests_per_sec"`
+	Passed         bool          `json:"passed"`
+	Reason         string        `json:"reason,omitempty"`
+}
+
+// JourneyMetrics holds the closed-model results of a single journey
+type JourneyMetrics struct {
+	Name           string               `json:"name"`
+	Started        uint64               `json:"started"`
+	Completed      uint64               `json:"completed"` // Every step succeeded
+	Failed         uint64               `json:"failed"`    // Stopped at an unsuccessful step
+	JourneysPerSec float64              `json:"journeys_per_sec"`
+	DurationP50    time.Duration        `json:"duration_p50"` // Completed journeys, including think time
+	DurationP95    time.Duration        `json:"duration_p95"`
+	Steps          []JourneyStepMetrics `json:"steps"`
+}
+
+// JourneyStepMetrics holds the latencies of a single journey step
+type JourneyStepMetrics struct {
+	Operation     string        `json:"operation"`
+	TotalRequests uint64        `json:"total_requests"`
+	ErrorRate     float64       `json:"error_rate"`
+	LatencyP50    time.Duration `json:"latency_p50"`
+	LatencyP95    time.Duration `json:"latency_p95"`
+	LatencyP99    time.Duration `json:"latency_p99"`
+}
+
+// StageMetrics holds the load test metrics of a single load profile stage
+type StageMetrics struct {
+	Name           string        `json:"name"`
+	Start          time.Duration `json:"start"` // Offset from the start of the test
+	Duration       time.Duration `json:"duration"`
+	StartRate      float64       `json:"start_rate"` // Planned requests per second
+	EndRate        float64       `json:"end_rate"`
+	TotalRequests  uint64        `json:"total_requests"`
+	RequestsPerSec float64       `json:"requests_per_sec"` // Achieved requests per second
+	ErrorRate      float64       `json:"error_rate"`
+	LatencyP50     time.Duration `json:"latency_p50"`
+	LatencyP95     time.Duration `json:"latency_p95"`
+	LatencyP99     time.Duration `json:"latency_p99"`
+	WithinTarget   bool          `json:"within_target"` // P95 latency met the global target
+}
+
+// EndpointMetrics holds the load test metrics and SLO outcome of a single operation
+type EndpointMetrics struct {
+	Method         string         `json:"method"`
+	Path           string         `json:"path"`
+	OperationID    string         `json:"operation_id,omitempty"`
+	Share          float64        `json:"share,omitempty"` // Planned share of the open-model traffic
+	TotalRequests  uint64         `json:"total_requests"`
+	SuccessCount   uint64         `json:"success_count"`
+	ErrorCount     uint64         `json:"error_count"`
+	ErrorRate      float64        `json:"error_rate"`
+	LatencyP50     time.Duration  `json:"latency_p50"`
+	LatencyP95     time.Duration  `json:"latency_p95"`
+	LatencyP99     time.Duration  `json:"latency_p99"`
+	RequestsPerSec float64        `json:"requests_per_sec"`
+	Throughput     float64        `json:"throughput"` // Successful requests per second
+	StatusCodes    map[string]int `json:"status_codes"`
+	Target         EndpointTarget `json:"target"`
+	Passed         bool           `json:"passed"`
+	Failures       []string       `json:"failures,omitempty"`
 }
 
 // PerformanceTestResult holds the result of a performance test run
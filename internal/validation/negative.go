@@ -0,0 +1,344 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/meter-peter/driveby/internal/openapi"
+)
+
+// negativeCase is a request that deliberately violates one documented constraint
+type negativeCase struct {
+	Name  string
+	Input *RequestInput
+	// InPath is set when the violated value is a path parameter, in which case a 404 is
+	// an acceptable rejection
+	InPath bool
+}
+
+// runNegativeTests sends requests that break each documented constraint of every operation
+// and checks that the API rejects them with a documented 4xx response. Test cases are
+// returned keyed by "METHOD path".
+func (t *FunctionalTester) runNegativeTests(ctx context.Context, doc *openapi3.T) map[string][]TestCaseResult {
+	results := make(map[string][]TestCaseResult)
+	for path, pathItem := range doc.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			if operation.Deprecated {
+				continue
+			}
+			key := method + " " + path
+			if reason, ok := t.impact.skipReason(key, TestKindNegative); ok {
+				results[key] = append(results[key], TestCaseResult{
+					Name:        "negative tests",
+					Status:      TestStatusSkipped,
+					Description: "Requests violating documented constraints were not sent",
+					Error:       reason,
+				})
+				continue
+			}
+			downgrade, downgraded := t.impact.downgradeReason(key, TestKindNegative)
+			for _, nc := range generateNegativeCases(pathItem, operation) {
+				validation := t.executeOperation(ctx, method, path, operation, nc.Input)
+				tc := negativeTestCase(nc, operation, validation)
+				if downgraded && tc.Status == TestStatusFailed {
+					tc.Status = TestStatusWarning
+					tc.Error = fmt.Sprintf("%s (reported as a warning: %s)", tc.Error, downgrade)
+				}
+				results[key] = append(results[key], tc)
+			}
+		}
+	}
+	return results
+}
+
+// negativeTestCase evaluates the response to a constraint-violating request
+func negativeTestCase(nc negativeCase, operation *openapi3.Operation, validation EndpointValidation) TestCaseResult {
+	tc := TestCaseResult{
+		Name:        "negative: " + nc.Name,
+		Description: "Request violating a documented constraint should be rejected with a documented 4xx response",
+		Input:       nc.Input,
+		Expected:    documentedClientErrorCodes(operation),
+		Actual:      validation.StatusCode,
+	}
+
+	code := validation.StatusCode
+	switch {
+	case code == 0:
+		tc.Status = TestStatusFailed
+		tc.Error = strings.Join(validation.Errors, "; ")
+	case code >= http.StatusOK && code < http.StatusMultipleChoices:
+		tc.Status = TestStatusFailed
+		tc.Error = fmt.Sprintf("invalid request was accepted with status %d", code)
+	case code >= http.StatusInternalServerError:
+		tc.Status = TestStatusFailed
+		tc.Error = fmt.Sprintf("invalid request caused a server error (status %d)", code)
+	case code == http.StatusNotFound && !nc.InPath:
+		// The synthesized path did not match an existing resource, so the constraint was
+		// never reached
+		tc.Status = TestStatusSkipped
+		tc.Error = "resource not found; constraint was not exercised"
+	case isClientError(code):
+		if operation.Responses != nil && operation.Responses.Status(code) == nil && operation.Responses.Default() == nil {
+			tc.Status = TestStatusWarning
+			tc.Error = fmt.Sprintf("invalid request was rejected with undocumented status %d", code)
+		} else {
+			tc.Status = TestStatusPassed
+		}
+	default:
+		tc.Status = TestStatusWarning
+		tc.Error = fmt.Sprintf("unexpected status %d for invalid request", code)
+	}
+	return tc
+}
+
+// documentedClientErrorCodes lists the 4xx status codes documented for an operation
+func documentedClientErrorCodes(operation *openapi3.Operation) []string {
+	var codes []string
+	if operation.Responses == nil {
+		return codes
+	}
+	for code := range operation.Responses.Map() {
+		if strings.HasPrefix(code, "4") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// generateNegativeCases derives constraint-violating requests from an operation's parameter
+// and request body schemas. Each case starts from a valid synthesized request and breaks
+// exactly one constraint.
+func generateNegativeCases(pathItem *openapi3.PathItem, operation *openapi3.Operation) []negativeCase {
+	var cases []negativeCase
+
+	for _, ref := range operationParameters(pathItem, operation) {
+		param := ref.Value
+		if param.In == openapi3.ParameterInCookie || reservedHeaders[strings.ToLower(param.Name)] {
+			continue
+		}
+		label := fmt.Sprintf("%s parameter '%s'", param.In, param.Name)
+
+		if param.Required && param.In != openapi3.ParameterInPath {
+			input := synthesizeRequest(pathItem, operation)
+			delete(paramValues(input, param.In), param.Name)
+			cases = append(cases, negativeCase{Name: "missing required " + label, Input: input})
+		}
+
+		if param.Schema == nil || param.Schema.Value == nil {
+			continue
+		}
+		for _, violation := range schemaViolations(param.Schema.Value) {
+			// Parameters are strings on the wire, so a number is a valid string parameter
+			if violation.wrongType && param.Schema.Value.Type == "string" {
+				continue
+			}
+			input := synthesizeRequest(pathItem, operation)
+			paramValues(input, param.In)[param.Name] = violation.value
+			cases = append(cases, negativeCase{
+				Name:   fmt.Sprintf("%s %s", label, violation.name),
+				Input:  input,
+				InPath: param.In == openapi3.ParameterInPath,
+			})
+		}
+	}
+
+	if operation.RequestBody == nil || operation.RequestBody.Value == nil {
+		return cases
+	}
+	base := synthesizeRequest(pathItem, operation)
+	if base.Body == nil {
+		return cases
+	}
+
+	if operation.RequestBody.Value.Required {
+		input := synthesizeRequest(pathItem, operation)
+		input.Body = nil
+		cases = append(cases, negativeCase{Name: "missing required request body", Input: input})
+	}
+
+	_, mediaType := openapi.JSONMediaType(operation.RequestBody.Value.Content)
+	if mediaType == nil || mediaType.Schema == nil || mediaType.Schema.Value == nil {
+		return cases
+	}
+	schema := mediaType.Schema.Value
+	properties, required := flattenObjectSchema(schema)
+
+	if _, ok := base.Body.(map[string]interface{}); !ok {
+		return cases
+	}
+
+	for _, name := range required {
+		input := synthesizeRequest(pathItem, operation)
+		body := cloneJSON(input.Body).(map[string]interface{})
+		delete(body, name)
+		input.Body = body
+		cases = append(cases, negativeCase{Name: fmt.Sprintf("missing required body field '%s'", name), Input: input})
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop := properties[name]
+		if prop == nil || prop.Value == nil || prop.Value.ReadOnly {
+			continue
+		}
+		for _, violation := range schemaViolations(prop.Value) {
+			input := synthesizeRequest(pathItem, operation)
+			body := cloneJSON(input.Body).(map[string]interface{})
+			body[name] = violation.value
+			input.Body = body
+			cases = append(cases, negativeCase{Name: fmt.Sprintf("body field '%s' %s", name, violation.name), Input: input})
+		}
+	}
+
+	return cases
+}
+
+// constraintViolation is a value that breaks one constraint of a schema
+type constraintViolation struct {
+	name      string
+	value     interface{}
+	wrongType bool
+}
+
+// schemaViolations returns values that each break exactly one constraint of a schema
+func schemaViolations(schema *openapi3.Schema) []constraintViolation {
+	var violations []constraintViolation
+
+	switch schema.Type {
+	case "string":
+		violations = append(violations, constraintViolation{name: "with wrong type", value: 12345, wrongType: true})
+		if schema.MinLength > 0 {
+			violations = append(violations, constraintViolation{
+				name:  fmt.Sprintf("shorter than minLength %d", schema.MinLength),
+				value: strings.Repeat("a", int(schema.MinLength)-1),
+			})
+		}
+		if schema.MaxLength != nil {
+			violations = append(violations, constraintViolation{
+				name:  fmt.Sprintf("longer than maxLength %d", *schema.MaxLength),
+				value: strings.Repeat("a", int(*schema.MaxLength)+1),
+			})
+		}
+		if len(schema.Enum) > 0 {
+			violations = append(violations, constraintViolation{name: "outside enum", value: "driveby-invalid-enum-value"})
+		}
+		switch schema.Format {
+		case "uuid", "email", "date", "date-time":
+			violations = append(violations, constraintViolation{name: fmt.Sprintf("with invalid %s format", schema.Format), value: "not-a-" + schema.Format})
+		}
+	case "integer", "number":
+		violations = append(violations, constraintViolation{name: "with wrong type", value: "not-a-number", wrongType: true})
+		if schema.Min != nil {
+			value := *schema.Min - 1
+			if schema.ExclusiveMin {
+				value = *schema.Min
+			}
+			violations = append(violations, constraintViolation{name: fmt.Sprintf("below minimum %v", *schema.Min), value: value})
+		}
+		if schema.Max != nil {
+			value := *schema.Max + 1
+			if schema.ExclusiveMax {
+				value = *schema.Max
+			}
+			violations = append(violations, constraintViolation{name: fmt.Sprintf("above maximum %v", *schema.Max), value: value})
+		}
+		if len(schema.Enum) > 0 {
+			violations = append(violations, constraintViolation{name: "outside enum", value: -987654321})
+		}
+	case "boolean":
+		violations = append(violations, constraintViolation{name: "with wrong type", value: "not-a-boolean", wrongType: true})
+	case "array":
+		violations = append(violations, constraintViolation{name: "with wrong type", value: "not-an-array", wrongType: true})
+		if schema.MaxItems != nil {
+			items := make([]interface{}, int(*schema.MaxItems)+1)
+			violations = append(violations, constraintViolation{name: fmt.Sprintf("with more than %d items", *schema.MaxItems), value: items})
+		}
+	case "object":
+		violations = append(violations, constraintViolation{name: "with wrong type", value: "not-an-object", wrongType: true})
+	}
+
+	return violations
+}
+
+// flattenObjectSchema merges the properties and required fields of an object schema,
+// including those contributed through allOf
+func flattenObjectSchema(schema *openapi3.Schema) (openapi3.Schemas, []string) {
+	properties := make(openapi3.Schemas)
+	requiredSet := make(map[string]bool)
+
+	var walk func(s *openapi3.Schema, depth int)
+	walk = func(s *openapi3.Schema, depth int) {
+		if s == nil || depth > 8 {
+			return
+		}
+		for name, prop := range s.Properties {
+			properties[name] = prop
+		}
+		for _, name := range s.Required {
+			requiredSet[name] = true
+		}
+		for _, sub := range s.AllOf {
+			if sub != nil {
+				walk(sub.Value, depth+1)
+			}
+		}
+	}
+	walk(schema, 0)
+
+	required := make([]string, 0, len(requiredSet))
+	for name := range requiredSet {
+		if prop, ok := properties[name]; ok && prop != nil && prop.Value != nil && prop.Value.ReadOnly {
+			continue
+		}
+		required = append(required, name)
+	}
+	sort.Strings(required)
+	return properties, required
+}
+
+// paramValues returns the input map holding parameters of the given location
+func paramValues(input *RequestInput, in string) map[string]interface{} {
+	switch in {
+	case openapi3.ParameterInPath:
+		return input.PathParams
+	case openapi3.ParameterInQuery:
+		return input.QueryParams
+	case openapi3.ParameterInHeader:
+		return input.Headers
+	default:
+		return input.Cookies
+	}
+}
+
+// cloneJSON deep-copies a decoded JSON value
+func cloneJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = cloneJSON(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = cloneJSON(item)
+		}
+		return out
+	}
+	return value
+}
+
+// isClientError reports whether a status code is a 4xx
+func isClientError(code int) bool {
+	return code >= http.StatusBadRequest && code < http.StatusInternalServerError
+}
@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestGenerateNegativeCasesWrongType(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		schema    *openapi3.Schema
+		wrongType bool // Whether a wrong type case is generated
+	}{
+		{name: "string path parameter", in: openapi3.ParameterInPath, schema: openapi3.NewStringSchema()},
+		{name: "string query parameter", in: openapi3.ParameterInQuery, schema: openapi3.NewStringSchema()},
+		{name: "string header parameter", in: openapi3.ParameterInHeader, schema: openapi3.NewStringSchema()},
+		{name: "integer path parameter", in: openapi3.ParameterInPath, schema: openapi3.NewIntegerSchema(), wrongType: true},
+		{name: "integer query parameter", in: openapi3.ParameterInQuery, schema: openapi3.NewIntegerSchema(), wrongType: true},
+		{name: "boolean header parameter", in: openapi3.ParameterInHeader, schema: openapi3.NewBoolSchema(), wrongType: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param := &openapi3.Parameter{Name: "value", In: tt.in, Required: true, Schema: tt.schema.NewRef()}
+			operation := &openapi3.Operation{Parameters: openapi3.Parameters{{Value: param}}}
+
+			var names []string
+			for _, nc := range generateNegativeCases(&openapi3.PathItem{}, operation) {
+				names = append(names, nc.Name)
+			}
+			want := tt.in + " parameter 'value' with wrong type"
+			if got := slices.Contains(names, want); got != tt.wrongType {
+				t.Errorf("cases %v contain %q: %v, want %v", names, want, got, tt.wrongType)
+			}
+		})
+	}
+}
+
+func TestSchemaViolations(t *testing.T) {
+	maxLength := uint64(5)
+	minimum, maximum := 1.0, 10.0
+	tests := []struct {
+		name   string
+		schema *openapi3.Schema
+		want   []string
+	}{
+		{
+			name:   "plain string",
+			schema: openapi3.NewStringSchema(),
+			want:   []string{"with wrong type"},
+		},
+		{
+			name:   "constrained string",
+			schema: &openapi3.Schema{Type: "string", MinLength: 2, MaxLength: &maxLength, Format: "uuid"},
+			want:   []string{"with wrong type", "shorter than minLength 2", "longer than maxLength 5", "with invalid uuid format"},
+		},
+		{
+			name:   "string enum",
+			schema: &openapi3.Schema{Type: "string", Enum: []interface{}{"open", "closed"}},
+			want:   []string{"with wrong type", "outside enum"},
+		},
+		{
+			name:   "bounded integer",
+			schema: &openapi3.Schema{Type: "integer", Min: &minimum, Max: &maximum},
+			want:   []string{"with wrong type", "below minimum 1", "above maximum 10"},
+		},
+		{
+			name:   "boolean",
+			schema: openapi3.NewBoolSchema(),
+			want:   []string{"with wrong type"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, violation := range schemaViolations(tt.schema) {
+				got = append(got, violation.name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("violations are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth2 grant types the token provider can use
+const (
+	OAuth2GrantClientCredentials = "client_credentials"
+	OAuth2GrantPassword          = "password" // Resource owner password credentials, for legacy APIs
+)
+
+// Defaults of the token provider
+const (
+	defaultOAuth2RefreshBefore = 30 * time.Second
+	defaultOAuth2Timeout       = 10 * time.Second
+	oauth2RetryInterval        = time.Second // Minimum time between failed token requests
+)
+
+// OAuth2Config configures fetching access tokens from an OAuth2 token endpoint
+type OAuth2Config struct {
+	TokenURL      string
+	GrantType     string // OAuth2GrantClientCredentials (default) or OAuth2GrantPassword
+	ClientID      string
+	ClientSecret  string // Sent with HTTP basic authentication; public clients send only the client ID
+	Username      string // Password grant only
+	Password      string
+	Scopes        []string
+	RefreshBefore time.Duration // Tokens are refreshed this long before they expire; defaults to 30s
+	Timeout       time.Duration // Timeout of token requests; defaults to 10s
+}
+
+// OAuth2Provider fetches access tokens from a token endpoint, caches them and refreshes
+// them before they expire, so that runs outlasting a token keep authenticating. A single
+// provider is shared by everything that sends requests during a run.
+type OAuth2Provider struct {
+	config OAuth2Config
+	client *http.Client
+
+	mu           sync.Mutex
+	token        string
+	refreshToken string
+	expiry       time.Time     // Zero when the token endpoint gave no lifetime
+	refreshAt    time.Time     // When the token is refreshed, ahead of its expiry
+	retryAt      time.Time     // Failed token requests are not retried before this time
+	err          error         // Error of the last failed token request
+	fetching     chan struct{} // Closed when the token request in flight is done; nil when none is
+}
+
+// oauth2Token is a token issued by the token endpoint
+type oauth2Token struct {
+	accessToken  string
+	refreshToken string
+	expiry       time.Time
+	refreshAt    time.Time
+}
+
+// oauth2TokenResponse is a successful token endpoint response
+type oauth2TokenResponse struct {
+	AccessToken  string      `json:"access_token"`
+	TokenType    string      `json:"token_type"`
+	ExpiresIn    json.Number `json:"expires_in"`
+	RefreshToken string      `json:"refresh_token"`
+}
+
+// oauth2ErrorResponse is a token endpoint error response
+type oauth2ErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// NewOAuth2Provider creates a token provider. No token is fetched until one is needed.
+func NewOAuth2Provider(config OAuth2Config) (*OAuth2Provider, error) {
+	if config.TokenURL == "" {
+		return nil, fmt.Errorf("OAuth2 token URL is required")
+	}
+	if _, err := url.ParseRequestURI(config.TokenURL); err != nil {
+		return nil, fmt.Errorf("invalid OAuth2 token URL: %w", err)
+	}
+	if config.GrantType == "" {
+		config.GrantType = OAuth2GrantClientCredentials
+	}
+	switch config.GrantType {
+	case OAuth2GrantClientCredentials:
+		if config.ClientID == "" {
+			return nil, fmt.Errorf("OAuth2 client ID is required for the client_credentials grant")
+		}
+	case OAuth2GrantPassword:
+		if config.Username == "" {
+			return nil, fmt.Errorf("username is required for the OAuth2 password grant")
+		}
+	default:
+		return nil, fmt.Errorf("unknown OAuth2 grant type %q (expected %s or %s)", config.GrantType, OAuth2GrantClientCredentials, OAuth2GrantPassword)
+	}
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = defaultOAuth2RefreshBefore
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultOAuth2Timeout
+	}
+	return &OAuth2Provider{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Token returns a valid access token, fetching a new one when there is none or the
+// cached one is about to expire. A single token request is in flight at a time, and
+// callers only wait for it when there is no valid token: while a token that has not
+// expired yet is refreshed, or the token endpoint fails, that token keeps being returned.
+func (p *OAuth2Provider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	now := time.Now()
+	if p.token != "" && (p.expiry.IsZero() || now.Before(p.refreshAt)) {
+		token := p.token
+		p.mu.Unlock()
+		return token, nil
+	}
+	if p.fetching == nil && !now.Before(p.retryAt) {
+		p.fetching = make(chan struct{})
+		// The request outlives the caller's context, as other callers may wait for it;
+		// the client timeout bounds it
+		go p.fetch(context.WithoutCancel(ctx), p.refreshToken, p.fetching)
+	}
+	if p.token != "" && now.Before(p.expiry) {
+		token := p.token
+		p.mu.Unlock()
+		return token, nil
+	}
+	fetching, err, retryAt := p.fetching, p.err, p.retryAt
+	p.mu.Unlock()
+	if fetching == nil {
+		return "", fmt.Errorf("OAuth2 token endpoint failed recently; retrying after %s: %w", retryAt.Format(time.RFC3339), err)
+	}
+
+	select {
+	case <-fetching:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && (p.expiry.IsZero() || time.Now().Before(p.expiry)) {
+		return p.token, nil
+	}
+	return "", p.err
+}
+
+// fetch requests a new token without holding the lock and stores the outcome, closing
+// done when finished
+func (p *OAuth2Provider) fetch(ctx context.Context, refreshToken string, done chan struct{}) {
+	token, err := p.requestToken(ctx, refreshToken)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fetching = nil
+	close(done)
+	if err != nil {
+		p.err = err
+		p.retryAt = time.Now().Add(oauth2RetryInterval)
+		if p.token != "" && time.Now().Before(p.expiry) {
+			log.Warnf("Failed to refresh OAuth2 token, using the current one until it expires at %s: %v", p.expiry.Format(time.RFC3339), err)
+		}
+		return
+	}
+	p.token = token.accessToken
+	p.refreshToken = token.refreshToken
+	p.expiry = token.expiry
+	p.refreshAt = token.refreshAt
+	p.err = nil
+	log.Debugf("Fetched OAuth2 token from %s (expires %s)", p.config.TokenURL, p.expiry.Format(time.RFC3339))
+}
+
+// requestToken requests a new token, using the refresh token when the endpoint gave one
+// and falling back to the configured grant
+func (p *OAuth2Provider) requestToken(ctx context.Context, refreshToken string) (oauth2Token, error) {
+	if refreshToken != "" {
+		form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}}
+		token, err := p.request(ctx, form)
+		if err == nil {
+			// Endpoints that do not rotate refresh tokens keep the current one valid
+			if token.refreshToken == "" {
+				token.refreshToken = refreshToken
+			}
+			return token, nil
+		}
+		log.Debugf("OAuth2 refresh token rejected, requesting a new token: %v", err)
+	}
+
+	form := url.Values{"grant_type": {p.config.GrantType}}
+	if p.config.GrantType == OAuth2GrantPassword {
+		form.Set("username", p.config.Username)
+		form.Set("password", p.config.Password)
+	}
+	if len(p.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.config.Scopes, " "))
+	}
+	return p.request(ctx, form)
+}
+
+// request sends a token request and returns the token of a successful response
+func (p *OAuth2Provider) request(ctx context.Context, form url.Values) (oauth2Token, error) {
+	if p.config.ClientSecret == "" && p.config.ClientID != "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return oauth2Token{}, fmt.Errorf("failed to create OAuth2 token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return oauth2Token{}, fmt.Errorf("OAuth2 token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return oauth2Token{}, fmt.Errorf("failed to read OAuth2 token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr oauth2ErrorResponse
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			if oauthErr.ErrorDescription != "" {
+				return oauth2Token{}, fmt.Errorf("OAuth2 token endpoint returned %d: %s: %s", resp.StatusCode, oauthErr.Error, oauthErr.ErrorDescription)
+			}
+			return oauth2Token{}, fmt.Errorf("OAuth2 token endpoint returned %d: %s", resp.StatusCode, oauthErr.Error)
+		}
+		return oauth2Token{}, fmt.Errorf("OAuth2 token endpoint returned %d", resp.StatusCode)
+	}
+
+	var response oauth2TokenResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return oauth2Token{}, fmt.Errorf("failed to parse OAuth2 token response: %w", err)
+	}
+	if response.AccessToken == "" {
+		return oauth2Token{}, fmt.Errorf("OAuth2 token response has no access_token")
+	}
+	if tokenType := strings.ToLower(response.TokenType); tokenType != "" && tokenType != "bearer" {
+		return oauth2Token{}, fmt.Errorf("unsupported OAuth2 token type %q", response.TokenType)
+	}
+
+	var expiry, refreshAt time.Time
+	if response.ExpiresIn != "" {
+		seconds, err := strconv.ParseFloat(string(response.ExpiresIn), 64)
+		if err != nil {
+			return oauth2Token{}, fmt.Errorf("invalid OAuth2 expires_in %q", response.ExpiresIn)
+		}
+		// Short-lived tokens are refreshed halfway through their lifetime at the latest
+		lifetime := time.Duration(seconds * float64(time.Second))
+		margin := p.config.RefreshBefore
+		if margin > lifetime/2 {
+			margin = lifetime / 2
+		}
+		expiry = time.Now().Add(lifetime)
+		refreshAt = expiry.Add(-margin)
+	}
+
+	return oauth2Token{
+		accessToken:  response.AccessToken,
+		refreshToken: response.RefreshToken,
+		expiry:       expiry,
+		refreshAt:    refreshAt,
+	}, nil
+}
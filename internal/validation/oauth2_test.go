@@ -0,0 +1,314 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tokenEndpoint is a stub OAuth2 token endpoint that records the requests it receives
+type tokenEndpoint struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []tokenRequest
+}
+
+// tokenRequest is a request received by the stub token endpoint
+type tokenRequest struct {
+	form     url.Values
+	clientID string // From HTTP basic authentication
+	secret   string
+}
+
+// newTokenEndpoint starts a stub token endpoint. respond is called with the number of the
+// request, counting from 1, and returns the status code and JSON body to send.
+func newTokenEndpoint(t *testing.T, respond func(n int, form url.Values) (int, string)) *tokenEndpoint {
+	t.Helper()
+	e := &tokenEndpoint{}
+	e.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		clientID, secret, _ := r.BasicAuth()
+		e.mu.Lock()
+		e.requests = append(e.requests, tokenRequest{form: r.PostForm, clientID: clientID, secret: secret})
+		n := len(e.requests)
+		e.mu.Unlock()
+
+		status, body := respond(n, r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(e.server.Close)
+	return e
+}
+
+// received returns the requests received so far
+func (e *tokenEndpoint) received() []tokenRequest {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]tokenRequest(nil), e.requests...)
+}
+
+// grantTypes returns the grant types of the requests received so far
+func (e *tokenEndpoint) grantTypes() []string {
+	var grants []string
+	for _, request := range e.received() {
+		grants = append(grants, request.form.Get("grant_type"))
+	}
+	return grants
+}
+
+// newTestProvider creates a client credentials provider for the stub endpoint
+func newTestProvider(t *testing.T, e *tokenEndpoint) *OAuth2Provider {
+	t.Helper()
+	provider, err := NewOAuth2Provider(OAuth2Config{
+		TokenURL:     e.server.URL,
+		ClientID:     "driveby",
+		ClientSecret: "s3cret",
+		Scopes:       []string{"tasks:read", "tasks:write"},
+	})
+	if err != nil {
+		t.Fatalf("NewOAuth2Provider: %v", err)
+	}
+	return provider
+}
+
+// mustToken returns a token from the provider, failing the test on an error
+func mustToken(t *testing.T, p *OAuth2Provider) string {
+	t.Helper()
+	token, err := p.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	return token
+}
+
+// waitForToken waits until the provider returns the wanted token
+func waitForToken(t *testing.T, p *OAuth2Provider, want string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		token := mustToken(t, p)
+		if token == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("token is %q, want %q", token, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOAuth2ProviderCachesToken(t *testing.T) {
+	e := newTokenEndpoint(t, func(n int, form url.Values) (int, string) {
+		return http.StatusOK, `{"access_token":"t1","token_type":"Bearer","expires_in":3600}`
+	})
+	p := newTestProvider(t, e)
+
+	for i := 0; i < 3; i++ {
+		if token := mustToken(t, p); token != "t1" {
+			t.Fatalf("token is %q, want t1", token)
+		}
+	}
+
+	requests := e.received()
+	if len(requests) != 1 {
+		t.Fatalf("token endpoint received %d requests, want 1", len(requests))
+	}
+	request := requests[0]
+	if got := request.form.Get("grant_type"); got != OAuth2GrantClientCredentials {
+		t.Errorf("grant_type is %q, want %s", got, OAuth2GrantClientCredentials)
+	}
+	if got := request.form.Get("scope"); got != "tasks:read tasks:write" {
+		t.Errorf("scope is %q, want %q", got, "tasks:read tasks:write")
+	}
+	if request.clientID != "driveby" || request.secret != "s3cret" {
+		t.Errorf("basic auth is %q:%q, want driveby:s3cret", request.clientID, request.secret)
+	}
+	if request.form.Has("client_id") {
+		t.Errorf("confidential client sent client_id in the form")
+	}
+}
+
+func TestOAuth2ProviderRefreshesBeforeExpiry(t *testing.T) {
+	e := newTokenEndpoint(t, func(n int, form url.Values) (int, string) {
+		if n == 1 {
+			return http.StatusOK, `{"access_token":"t1","token_type":"Bearer","expires_in":1,"refresh_token":"r1"}`
+		}
+		return http.StatusOK, `{"access_token":"t2","token_type":"Bearer","expires_in":3600}`
+	})
+	p := newTestProvider(t, e)
+
+	start := time.Now()
+	if token := mustToken(t, p); token != "t1" {
+		t.Fatalf("token is %q, want t1", token)
+	}
+	time.Sleep(600 * time.Millisecond)
+	waitForToken(t, p, "t2")
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("token was refreshed after %s, after it expired", elapsed)
+	}
+
+	requests := e.received()
+	if len(requests) != 2 {
+		t.Fatalf("token endpoint received %d requests, want 2", len(requests))
+	}
+	if got := requests[1].form.Get("grant_type"); got != "refresh_token" {
+		t.Errorf("refresh used grant_type %q, want refresh_token", got)
+	}
+	if got := requests[1].form.Get("refresh_token"); got != "r1" {
+		t.Errorf("refresh_token is %q, want r1", got)
+	}
+	// The endpoint did not rotate the refresh token, so the new token is cached for good
+	if token := mustToken(t, p); token != "t2" {
+		t.Errorf("token is %q, want t2", token)
+	}
+}
+
+func TestOAuth2ProviderServesCachedTokenDuringRefresh(t *testing.T) {
+	release := make(chan struct{})
+	e := newTokenEndpoint(t, func(n int, form url.Values) (int, string) {
+		if n == 1 {
+			return http.StatusOK, `{"access_token":"t1","token_type":"Bearer","expires_in":1}`
+		}
+		<-release
+		return http.StatusOK, `{"access_token":"t2","token_type":"Bearer","expires_in":3600}`
+	})
+	p := newTestProvider(t, e)
+
+	if token := mustToken(t, p); token != "t1" {
+		t.Fatalf("token is %q, want t1", token)
+	}
+	time.Sleep(600 * time.Millisecond)
+
+	// Concurrent callers get the cached token at once while a single refresh is pending
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			if token, err := p.Token(context.Background()); err != nil || token != "t1" {
+				t.Errorf("Token returned %q, %v during the refresh, want t1", token, err)
+			}
+			if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+				t.Errorf("Token blocked for %s during the refresh", elapsed)
+			}
+		}()
+	}
+	wg.Wait()
+	close(release)
+	waitForToken(t, p, "t2")
+
+	if requests := e.received(); len(requests) != 2 {
+		t.Errorf("token endpoint received %d requests, want 2", len(requests))
+	}
+}
+
+func TestOAuth2ProviderFallsBackWhenRefreshTokenRejected(t *testing.T) {
+	e := newTokenEndpoint(t, func(n int, form url.Values) (int, string) {
+		switch {
+		case n == 1:
+			return http.StatusOK, `{"access_token":"t1","token_type":"Bearer","expires_in":1,"refresh_token":"r1"}`
+		case form.Get("grant_type") == "refresh_token":
+			return http.StatusBadRequest, `{"error":"invalid_grant","error_description":"refresh token revoked"}`
+		default:
+			return http.StatusOK, `{"access_token":"t2","token_type":"Bearer","expires_in":3600}`
+		}
+	})
+	p := newTestProvider(t, e)
+
+	if token := mustToken(t, p); token != "t1" {
+		t.Fatalf("token is %q, want t1", token)
+	}
+	time.Sleep(600 * time.Millisecond)
+	waitForToken(t, p, "t2")
+
+	want := []string{OAuth2GrantClientCredentials, "refresh_token", OAuth2GrantClientCredentials}
+	if got := e.grantTypes(); !slices.Equal(got, want) {
+		t.Errorf("grant types are %v, want %v", got, want)
+	}
+}
+
+func TestOAuth2ProviderPasswordGrant(t *testing.T) {
+	e := newTokenEndpoint(t, func(n int, form url.Values) (int, string) {
+		if form.Get("username") != "alice" || form.Get("password") != "pa55" {
+			return http.StatusUnauthorized, `{"error":"invalid_grant"}`
+		}
+		return http.StatusOK, `{"access_token":"t1","token_type":"bearer","expires_in":3600}`
+	})
+	p, err := NewOAuth2Provider(OAuth2Config{
+		TokenURL:  e.server.URL,
+		GrantType: OAuth2GrantPassword,
+		ClientID:  "cli",
+		Username:  "alice",
+		Password:  "pa55",
+	})
+	if err != nil {
+		t.Fatalf("NewOAuth2Provider: %v", err)
+	}
+
+	if token := mustToken(t, p); token != "t1" {
+		t.Fatalf("token is %q, want t1", token)
+	}
+	request := e.received()[0]
+	if got := request.form.Get("grant_type"); got != OAuth2GrantPassword {
+		t.Errorf("grant_type is %q, want %s", got, OAuth2GrantPassword)
+	}
+	// A public client identifies itself in the form instead of with basic auth
+	if got := request.form.Get("client_id"); got != "cli" {
+		t.Errorf("client_id is %q, want cli", got)
+	}
+	if request.clientID != "" {
+		t.Errorf("public client sent basic auth for %q", request.clientID)
+	}
+}
+
+func TestOAuth2ProviderKeepsValidTokenWhenRefreshFails(t *testing.T) {
+	e := newTokenEndpoint(t, func(n int, form url.Values) (int, string) {
+		if n == 1 {
+			return http.StatusOK, `{"access_token":"t1","token_type":"Bearer","expires_in":1}`
+		}
+		return http.StatusServiceUnavailable, `{"error":"temporarily_unavailable"}`
+	})
+	p := newTestProvider(t, e)
+
+	start := time.Now()
+	if token := mustToken(t, p); token != "t1" {
+		t.Fatalf("token is %q, want t1", token)
+	}
+	time.Sleep(600 * time.Millisecond)
+
+	// The refresh fails, but the current token has not expired yet
+	deadline := time.Now().Add(time.Second)
+	for len(e.received()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("token was not refreshed")
+		}
+		if token := mustToken(t, p); token != "t1" {
+			t.Fatalf("token is %q, want t1", token)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if token := mustToken(t, p); token != "t1" {
+		t.Fatalf("token is %q after a failed refresh, want t1", token)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Fatalf("refresh was attempted after %s, after the token expired", elapsed)
+	}
+
+	// Once it has expired, the failure is reported
+	time.Sleep(time.Until(start.Add(1100 * time.Millisecond)))
+	if token, err := p.Token(context.Background()); err == nil {
+		t.Errorf("Token returned %q after the token expired and the endpoint failed, want an error", token)
+	}
+}
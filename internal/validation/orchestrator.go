@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/meter-peter/driveby/internal/openapi"
@@ -99,14 +100,17 @@ const (
 
 // ValidationResult represents the result of a validation run
 type ValidationResult struct {
-	Type   ValidationType
-	Report *ValidationReport
-	Error  error
+	Type       ValidationType
+	Report     *ValidationReport
+	Error      error
+	Skipped    bool   // The phase did not run
+	SkipReason string // Why the phase did not run
 }
 
 // Orchestrator handles running different types of validation
 type Orchestrator struct {
-	config ValidatorConfig
+	config         ValidatorConfig
+	specPrinciples []PrincipleResult // Latest spec validation results; their test impact applies to the test phases
 }
 
 // NewOrchestrator creates a new orchestrator instance
@@ -125,6 +129,9 @@ func (o *Orchestrator) RunValidation(ctx context.Context, validationType Validat
 			return nil, fmt.Errorf("failed to create validator: %w", err)
 		}
 		report, err := validator.ValidateSpec(ctx)
+		if report != nil {
+			o.specPrinciples = report.Principles
+		}
 		return &ValidationResult{
 			Type:   ValidationTypeSpec,
 			Report: report,
@@ -133,6 +140,7 @@ func (o *Orchestrator) RunValidation(ctx context.Context, validationType Validat
 
 	case ValidationTypeFunctional:
 		tester := NewFunctionalTester(o.config)
+		tester.UseTestImpact(o.specPrinciples)
 		report, err := tester.TestEndpoints(ctx)
 		return &ValidationResult{
 			Type:   ValidationTypeFunctional,
@@ -145,6 +153,7 @@ func (o *Orchestrator) RunValidation(ctx context.Context, validationType Validat
 		if err != nil {
 			return nil, fmt.Errorf("failed to create performance tester: %w", err)
 		}
+		tester.UseTestImpact(o.specPrinciples)
 		report, err := tester.TestPerformance(ctx)
 		return &ValidationResult{
 			Type:   ValidationTypePerformance,
@@ -157,39 +166,232 @@ func (o *Orchestrator) RunValidation(ctx context.Context, validationType Validat
 	}
 }
 
-// RunAllValidations runs all types of validation in sequence
+// RunAllValidations runs spec validation, functional tests and performance tests in
+// sequence. Which phases run, and whether a failing phase stops the following ones,
+// depends on the validation mode:
+//   - strict: all phases; any failed check skips the remaining phases
+//   - minimal: spec validation only
+//   - test-only: functional and performance tests; failing functional tests skip performance tests
+//   - flexible: all phases; only critical spec failures skip the tests
 func (o *Orchestrator) RunAllValidations(ctx context.Context) ([]ValidationResult, error) {
+	mode := o.config.ValidationMode
+	if mode == "" {
+		mode = ValidationModeMinimal
+	}
 	results := make([]ValidationResult, 0, 3)
+	var blocked string
 
 	// Run spec validation
-	specResult, err := o.RunValidation(ctx, ValidationTypeSpec)
-	if err != nil {
-		return nil, fmt.Errorf("spec validation failed: %w", err)
+	if mode == ValidationModeTestOnly {
+		results = append(results, skippedResult(ValidationTypeSpec, "validation mode test-only skips spec validation"))
+	} else {
+		specResult, err := o.RunValidation(ctx, ValidationTypeSpec)
+		if err != nil {
+			return nil, fmt.Errorf("spec validation failed: %w", err)
+		}
+		if specResult.Error != nil {
+			return nil, fmt.Errorf("spec validation error: %w", specResult.Error)
+		}
+		results = append(results, *specResult)
+		blocked = o.blockingFailure(mode, specResult)
 	}
-	if specResult.Error != nil {
-		return nil, fmt.Errorf("spec validation error: %w", specResult.Error)
+
+	if mode == ValidationModeMinimal {
+		reason := "validation mode minimal runs spec validation only"
+		results = append(results, skippedResult(ValidationTypeFunctional, reason), skippedResult(ValidationTypePerformance, reason))
+		return results, nil
 	}
-	results = append(results, *specResult)
 
 	// Run functional tests
-	funcResult, err := o.RunValidation(ctx, ValidationTypeFunctional)
-	if err != nil {
-		return nil, fmt.Errorf("functional testing failed: %w", err)
-	}
-	if funcResult.Error != nil {
-		return nil, fmt.Errorf("functional testing error: %w", funcResult.Error)
+	if blocked != "" {
+		results = append(results, skippedResult(ValidationTypeFunctional, blocked))
+	} else {
+		funcResult, err := o.RunValidation(ctx, ValidationTypeFunctional)
+		if err != nil {
+			return nil, fmt.Errorf("functional testing failed: %w", err)
+		}
+		if funcResult.Error != nil {
+			return nil, fmt.Errorf("functional testing error: %w", funcResult.Error)
+		}
+		results = append(results, *funcResult)
+		blocked = o.blockingFailure(mode, funcResult)
 	}
-	results = append(results, *funcResult)
 
 	// Run performance tests
-	perfResult, err := o.RunValidation(ctx, ValidationTypePerformance)
-	if err != nil {
-		return nil, fmt.Errorf("performance testing failed: %w", err)
-	}
-	if perfResult.Error != nil {
-		return nil, fmt.Errorf("performance testing error: %w", perfResult.Error)
+	switch {
+	case o.config.PerformanceTarget == nil:
+		results = append(results, skippedResult(ValidationTypePerformance, "no performance targets configured"))
+	case blocked != "":
+		results = append(results, skippedResult(ValidationTypePerformance, blocked))
+	default:
+		perfResult, err := o.RunValidation(ctx, ValidationTypePerformance)
+		if err != nil {
+			return nil, fmt.Errorf("performance testing failed: %w", err)
+		}
+		if perfResult.Error != nil {
+			return nil, fmt.Errorf("performance testing error: %w", perfResult.Error)
+		}
+		results = append(results, *perfResult)
 	}
-	results = append(results, *perfResult)
 
 	return results, nil
 }
+
+// blockingFailure returns why the phases after result must be skipped, or an empty
+// string when they can run
+func (o *Orchestrator) blockingFailure(mode ValidationMode, result *ValidationResult) string {
+	var failed []string
+	for _, principle := range result.Report.Principles {
+		if principle.Passed {
+			continue
+		}
+		// Flexible mode tolerates everything but critical spec failures
+		if mode == ValidationModeFlexible && (result.Type != ValidationTypeSpec || principle.Principle.Severity != "critical") {
+			continue
+		}
+		failed = append(failed, fmt.Sprintf("%s %s", principle.Principle.ID, principle.Principle.Name))
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s phase failed (%s)", result.Type, strings.Join(failed, ", "))
+}
+
+// skippedResult records a phase that did not run
+func skippedResult(validationType ValidationType, reason string) ValidationResult {
+	log.Infof("Skipping %s phase: %s", validationType, reason)
+	return ValidationResult{
+		Type:       validationType,
+		Skipped:    true,
+		SkipReason: reason,
+	}
+}
+
+// MergeResults combines the results of all phases into a single report with test
+// results and a test summary
+func (o *Orchestrator) MergeResults(results []ValidationResult) *ValidationReport {
+	report := &ValidationReport{
+		Version:     o.config.Version,
+		Environment: o.config.Environment,
+		Timestamp:   time.Now(),
+	}
+	testResults := &TestResults{}
+	testSummary := &TestSummary{
+		FunctionalStatus:  TestStatusSkipped,
+		PerformanceStatus: TestStatusSkipped,
+		SkipReasons:       make(map[ValidationType]string),
+	}
+	principleIndex := make(map[string]int) // Position of each principle in report.Principles
+
+	for _, result := range results {
+		if result.Skipped {
+			testSummary.SkipReasons[result.Type] = result.SkipReason
+			continue
+		}
+		if result.Report == nil {
+			continue
+		}
+
+		for _, principle := range result.Report.Principles {
+			if i, ok := principleIndex[principle.Principle.ID]; ok {
+				report.Principles[i] = mergePrincipleResults(report.Principles[i], principle)
+				continue
+			}
+			principleIndex[principle.Principle.ID] = len(report.Principles)
+			report.Principles = append(report.Principles, principle)
+		}
+		report.AutoFixes = append(report.AutoFixes, result.Report.AutoFixes...)
+
+		phase := result.Report.TestResults
+		if phase == nil {
+			continue
+		}
+		if testResults.StartTime.IsZero() || phase.StartTime.Before(testResults.StartTime) {
+			testResults.StartTime = phase.StartTime
+		}
+		if phase.EndTime.After(testResults.EndTime) {
+			testResults.EndTime = phase.EndTime
+		}
+		if phase.Functional != nil {
+			testResults.Functional = phase.Functional
+			testSummary.FunctionalStatus = phase.Status
+			for _, endpoint := range phase.Functional.EndpointResults {
+				testSummary.TotalTests++
+				switch endpoint.Status {
+				case TestStatusPassed:
+					testSummary.PassedTests++
+				case TestStatusWarning:
+					testSummary.PassedTests++
+					testSummary.Warnings++
+				case TestStatusSkipped:
+					testSummary.SkippedTests++
+				default:
+					testSummary.FailedTests++
+				}
+			}
+		}
+		if phase.Performance != nil {
+			testResults.Performance = phase.Performance
+			testSummary.PerformanceStatus = phase.Performance.Status
+			testSummary.TotalTests++
+			if phase.Performance.Status == TestStatusFailed {
+				testSummary.FailedTests++
+			} else {
+				testSummary.PassedTests++
+			}
+		}
+	}
+
+	for _, principle := range report.Principles {
+		report.TotalChecks++
+		if principle.Passed {
+			report.PassedChecks++
+		} else {
+			report.FailedChecks++
+		}
+	}
+
+	switch {
+	case testSummary.FunctionalStatus == TestStatusFailed || testSummary.PerformanceStatus == TestStatusFailed:
+		testResults.Status = TestStatusFailed
+	case testResults.Functional == nil && testResults.Performance == nil:
+		testResults.Status = TestStatusSkipped
+	case report.FailedChecks > 0 && len(testSummary.SkipReasons) > 0:
+		// A failure stopped some of the phases
+		testResults.Status = TestStatusIncomplete
+	case testSummary.Warnings > 0:
+		testResults.Status = TestStatusWarning
+	default:
+		testResults.Status = TestStatusPassed
+	}
+
+	report.TestResults = testResults
+	report.Summary.TestSummary = testSummary
+	updateSummary(report)
+	return report
+}
+
+// mergePrincipleResults combines the results of a principle checked by more than one
+// phase, like P005, which spec validation checks in the spec and the authentication tests
+// against the running API. The principle fails when any phase fails it.
+func mergePrincipleResults(existing, added PrincipleResult) PrincipleResult {
+	if added.Passed {
+		return existing
+	}
+	if existing.Passed {
+		added.TestImpact = existing.TestImpact
+		return added
+	}
+	existing.Message = fmt.Sprintf("%s; %s", existing.Message, added.Message)
+	existing.Findings = append(existing.Findings, added.Findings...)
+	// Lists of failing operations are combined
+	if details, ok := existing.Details.([]string); ok {
+		if addedDetails, ok := added.Details.([]string); ok {
+			existing.Details = append(details, addedDetails...)
+		}
+	}
+	if existing.SuggestedFix == "" {
+		existing.SuggestedFix = added.SuggestedFix
+	}
+	return existing
+}
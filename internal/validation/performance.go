@@ -3,6 +3,9 @@ package validation
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +19,7 @@ type PerformanceTester struct {
 	config  ValidatorConfig
 	loader  *openapi.Loader
 	metrics *vegeta.Metrics
+	impact  testImpactIndex
 	mu      sync.Mutex // Protect metrics access
 }
 
@@ -31,6 +35,12 @@ func NewPerformanceTester(config ValidatorConfig) (*PerformanceTester, error) {
 	}, nil
 }
 
+// UseTestImpact makes the tester leave out the load targets that the given spec
+// validation results report as affected
+func (t *PerformanceTester) UseTestImpact(principles []PrincipleResult) {
+	t.impact = newTestImpactIndex(principles)
+}
+
 // cleanup releases any resources held by the tester
 func (t *PerformanceTester) cleanup() {
 	t.mu.Lock()
@@ -58,65 +68,137 @@ func (t *PerformanceTester) TestPerformance(ctx context.Context) (*ValidationRep
 		return nil, fmt.Errorf("failed to get OpenAPI document")
 	}
 
-	// Create targets for all endpoints
-	var targets []vegeta.Target
-	for path, pathItem := range doc.Paths.Map() {
-		for method := range pathItem.Operations() {
-			// Skip endpoints that are not suitable for load testing
-			if method == "DELETE" || method == "PATCH" {
-				continue
-			}
-			targets = append(targets, vegeta.Target{
-				Method: method,
-				URL:    fmt.Sprintf("%s%s", t.config.BaseURL, path),
-			})
-		}
-	}
-
-	if len(targets) == 0 {
+	// Prepare weighted targets for the selected endpoints
+	loadTargets := t.buildLoadTargets(doc)
+	if len(loadTargets.weighted) == 0 {
 		return nil, fmt.Errorf("no suitable endpoints found for load testing")
 	}
-
-	// Configure the attack
-	rate := vegeta.Rate{
-		Freq: t.config.PerformanceTarget.ConcurrentUsers,
-		Per:  time.Second,
+	skippedTargets := loadTargets.skipped
+	endpoints := newEndpointCollector()
+	totalWeight := 0.0
+	for _, target := range loadTargets.weighted {
+		totalWeight += target.weight
 	}
-	duration := t.config.PerformanceTarget.Duration
-	if duration == 0 {
-		duration = 5 * time.Minute // Default duration
+	for _, target := range loadTargets.operations {
+		endpoints.track(target.target, target.method, target.path, target.operationID, target.weight/totalWeight)
 	}
 
-	attacker := vegeta.NewAttacker()
-	targeter := vegeta.NewStaticTargeter(targets...)
+	// Every request, whichever model sent it, counts towards the overall and
+	// per-operation metrics
+	record := func(res *vegeta.Result) {
+		t.mu.Lock()
+		t.metrics.Add(res)
+		endpoints.add(res)
+		t.mu.Unlock()
+	}
 
-	// Run the attack with context cancellation
-	done := make(chan struct{})
-	go func() {
-		defer close(done)
-		for res := range attacker.Attack(targeter, rate, duration, "DriveBy Load Test") {
-			t.mu.Lock()
-			t.metrics.Add(res)
-			t.mu.Unlock()
+	duration := t.config.PerformanceTarget.loadDuration()
+	var startTime time.Time
+	var stageMetrics []StageMetrics
+	var journeyMetrics []JourneyMetrics
+	var capacity *CapacityResult
+	if t.config.PerformanceTarget.Profile.Type == LoadProfileCapacity {
+		startTime = time.Now()
+		result, err := t.searchCapacity(ctx, loadTargets.weighted, record)
+		if err != nil {
+			return nil, err
+		}
+		capacity = result
+	} else if t.config.PerformanceTarget.Model == LoadModelClosed {
+		journeys, skippedJourneys, err := resolveJourneys(t.config.PerformanceTarget.Journeys, loadTargets)
+		if err != nil {
+			return nil, err
+		}
+		skippedTargets = append(skippedTargets, skippedJourneys...)
+		if len(journeys) == 0 {
+			return nil, fmt.Errorf("no journeys left to run after skipping affected operations")
+		}
+
+		startTime = time.Now()
+		journeyMetrics = t.runVirtualUsers(ctx, journeys, duration, record)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	} else {
+		// Configure the attack from the load profile
+		loadStages, err := t.config.PerformanceTarget.loadStages()
+		if err != nil {
+			return nil, fmt.Errorf("invalid load profile: %w", err)
 		}
-	}()
+		pacer := stagedPacer{stages: loadStages}
+		stages := newStageCollector(loadStages)
 
-	// Wait for either context cancellation or attack completion
-	select {
-	case <-ctx.Done():
-		attacker.Stop()
-		return nil, ctx.Err()
-	case <-done:
-		// Attack completed normally
+		attacker := vegeta.NewAttacker()
+		targeter := weightedTargeter(loadTargets.weighted)
+		startTime = time.Now()
+		stages.begin(startTime)
+
+		// Run the attack with context cancellation
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for res := range attacker.Attack(targeter, pacer, duration, "DriveBy Load Test") {
+				record(res)
+				stages.add(res)
+			}
+		}()
+
+		// Wait for either context cancellation or attack completion
+		select {
+		case <-ctx.Done():
+			attacker.Stop()
+			return nil, ctx.Err()
+		case <-done:
+			// Attack completed normally
+		}
+
+		if len(loadStages) > 1 {
+			stageMetrics = stages.results(t.config.PerformanceTarget.MaxLatencyP95)
+		}
 	}
+	sort.Strings(skippedTargets)
 
 	t.mu.Lock()
 	t.metrics.Close()
 	metrics := t.metrics
 	t.metrics = nil // Prevent double close
 	t.mu.Unlock()
+	endTime := time.Now()
+
+	successCount, errorCount, errorRate := requestOutcomes(metrics)
+	endpointMetrics := endpoints.results(t.config.PerformanceTarget)
+	failedRequests := endpoints.failedRequests()
+	model := t.config.PerformanceTarget.Model
+	if model == "" {
+		model = LoadModelOpen
+	}
+	var virtualUsers int
+	if model == LoadModelClosed {
+		virtualUsers = t.config.PerformanceTarget.ConcurrentUsers
+	}
 
 	// Create performance report
+	details := &PerformanceMetrics{
+		StartTime:      startTime,
+		EndTime:        endTime,
+		TotalRequests:  metrics.Requests,
+		SuccessCount:   successCount,
+		ErrorCount:     errorCount,
+		ErrorRate:      errorRate,
+		LatencyP50:     metrics.Latencies.P50,
+		LatencyP95:     metrics.Latencies.P95,
+		LatencyP99:     metrics.Latencies.P99,
+		RequestsPerSec: metrics.Rate,
+		SkippedTargets: skippedTargets,
+		Endpoints:      endpointMetrics,
+		FailedRequests: failedRequests,
+		LoadProfile:    t.config.PerformanceTarget.Profile.Type,
+		Stages:         stageMetrics,
+		LoadModel:      model,
+		VirtualUsers:   virtualUsers,
+		Journeys:       journeyMetrics,
+		Capacity:       capacity,
+	}
 	report := &ValidationReport{
 		Version:     t.config.Version,
 		Environment: t.config.Environment,
@@ -125,77 +207,298 @@ func (t *PerformanceTester) TestPerformance(ctx context.Context) (*ValidationRep
 			{
 				Principle: CorePrinciples[6], // P007: API Performance Compliance
 				Passed:    true,
-				Details: &PerformanceMetrics{
-					StartTime:      time.Now().Add(-duration),
-					EndTime:        time.Now(),
-					TotalRequests:  metrics.Requests,
-					SuccessCount:   metrics.Requests - uint64(len(metrics.Errors)),
-					ErrorCount:     uint64(len(metrics.Errors)),
-					ErrorRate:      float64(len(metrics.Errors)) / float64(metrics.Requests),
-					LatencyP50:     metrics.Latencies.P50,
-					LatencyP95:     metrics.Latencies.P95,
-					LatencyP99:     metrics.Latencies.P99,
-					RequestsPerSec: metrics.Rate,
-				},
+				Details:   details,
 			},
 		},
 	}
 
-	// Check against performance targets
+	if capacity != nil {
+		// A capacity search fails probes on purpose, so only its outcome is judged
+		report.Principles[0].Passed = capacity.MaxSustainableRate > 0
+		report.Principles[0].Message = capacityMessage(capacity)
+	} else if failedChecks := t.targetFailures(metrics, errorRate, endpointMetrics); len(failedChecks) > 0 {
+		report.Principles[0].Passed = false
+		report.Principles[0].Message = strings.Join(failedChecks, "; ")
+	} else {
+		report.Principles[0].Message = "All performance targets met"
+	}
+
+	// A regression against the baseline fails the run even within the absolute targets
+	var baseline *BaselineComparison
+	if t.config.PerformanceTarget.Baseline != nil {
+		var err error
+		if baseline, err = t.checkBaseline(details, report.Principles[0].Passed); err != nil {
+			return nil, fmt.Errorf("failed to check performance baseline: %w", err)
+		}
+		details.Baseline = baseline
+		if len(baseline.Regressions) > 0 {
+			message := strings.Join(baseline.Regressions, "; ")
+			if !report.Principles[0].Passed {
+				message = report.Principles[0].Message + "; " + message
+			}
+			report.Principles[0].Passed = false
+			report.Principles[0].Message = message
+		}
+	}
+
+	report.TotalChecks = 1
+	if report.Principles[0].Passed {
+		report.PassedChecks = 1
+	} else {
+		report.FailedChecks = 1
+	}
+
+	performance := &PerformanceTestResults{
+		TotalRequests:     int64(metrics.Requests),
+		SuccessCount:      int64(successCount),
+		ErrorCount:        int64(errorCount),
+		ErrorRate:         errorRate,
+		LatencyP50:        metrics.Latencies.P50,
+		LatencyP95:        metrics.Latencies.P95,
+		LatencyP99:        metrics.Latencies.P99,
+		RequestsPerSecond: metrics.Rate,
+		Duration:          endTime.Sub(startTime),
+		Status:            TestStatusPassed,
+		FailedRequests:    failedRequests,
+		SkippedTargets:    skippedTargets,
+		Endpoints:         endpointMetrics,
+		Stages:            stageMetrics,
+		Journeys:          journeyMetrics,
+		Capacity:          capacity,
+		Baseline:          baseline,
+	}
+	if !report.Principles[0].Passed {
+		performance.Status = TestStatusFailed
+	}
+	report.TestResults = &TestResults{
+		Performance: performance,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Status:      performance.Status,
+	}
+
+	return report, nil
+}
+
+// targetFailures checks a run's overall metrics against the global targets and each
+// operation's metrics against its SLO
+func (t *PerformanceTester) targetFailures(metrics *vegeta.Metrics, errorRate float64, endpointMetrics []EndpointMetrics) []string {
 	var failedChecks []string
 	if t.config.PerformanceTarget.MaxLatencyP95 > 0 && metrics.Latencies.P95 > t.config.PerformanceTarget.MaxLatencyP95 {
 		failedChecks = append(failedChecks, fmt.Sprintf("P95 latency (%s) exceeded target (%s)",
 			metrics.Latencies.P95, t.config.PerformanceTarget.MaxLatencyP95))
 	}
 
-	successRate := 1.0 - (float64(len(metrics.Errors)) / float64(metrics.Requests))
+	successRate := 1.0 - errorRate
 	if t.config.PerformanceTarget.MinSuccessRate > 0 && successRate < t.config.PerformanceTarget.MinSuccessRate {
 		failedChecks = append(failedChecks, fmt.Sprintf("Success rate (%.2f%%) below target (%.2f%%)",
 			successRate*100, t.config.PerformanceTarget.MinSuccessRate*100))
 	}
 
-	if len(failedChecks) > 0 {
-		report.Principles[0].Passed = false
-		report.Principles[0].Message = strings.Join(failedChecks, "; ")
-	} else {
-		report.Principles[0].Message = "All performance targets met"
+	// Attribute per-operation SLO failures to the operation
+	for _, endpoint := range endpointMetrics {
+		for _, failure := range endpoint.Failures {
+			failedChecks = append(failedChecks, fmt.Sprintf("%s %s: %s", endpoint.Method, endpoint.Path, failure))
+		}
 	}
+	return failedChecks
+}
 
-	report.TotalChecks = 1
-	if report.Principles[0].Passed {
-		report.PassedChecks = 1
-	} else {
-		report.FailedChecks = 1
+// requestOutcomes splits a run's requests into successes and errors. Like vegeta, a
+// request succeeds when it returns a 2xx or 3xx status.
+func requestOutcomes(metrics *vegeta.Metrics) (successCount, errorCount uint64, errorRate float64) {
+	if metrics.Requests == 0 {
+		return 0, 0, 0
 	}
+	successCount = uint64(math.Round(metrics.Success * float64(metrics.Requests)))
+	errorCount = metrics.Requests - successCount
+	return successCount, errorCount, float64(errorCount) / float64(metrics.Requests)
+}
 
-	return report, nil
+// maxFailedSamples caps the failed requests kept per operation
+const maxFailedSamples = 10
+
+// endpointCollector keeps load test metrics and failed request samples per operation
+type endpointCollector struct {
+	operations   map[string]string // Tagged target URL to operation "METHOD path"
+	operationIDs map[string]string
+	shares       map[string]float64 // Planned share of the traffic
+	metrics      map[string]*vegeta.Metrics
+	failed       map[string][]FailedRequest
 }
 
-// runPerformanceTests executes a load test against the specified targets
-func (t *PerformanceTester) runPerformanceTests(targets []vegeta.Target) (*PerformanceTestResult, error) {
-	attacker := vegeta.NewAttacker()
-	targeter := vegeta.NewStaticTargeter(targets...)
-	pacer := vegeta.Rate{Freq: 50, Per: time.Second} // 50 requests per second
-	duration := 10 * time.Second
-	metrics := &vegeta.Metrics{}
+func newEndpointCollector() *endpointCollector {
+	return &endpointCollector{
+		operations:   make(map[string]string),
+		operationIDs: make(map[string]string),
+		shares:       make(map[string]float64),
+		metrics:      make(map[string]*vegeta.Metrics),
+		failed:       make(map[string][]FailedRequest),
+	}
+}
+
+// track registers the operation a target exercises and its planned share of the traffic
+func (c *endpointCollector) track(target vegeta.Target, method, path, operationID string, share float64) {
+	operation := method + " " + path
+	c.operations[target.URL] = operation
+	c.operationIDs[operation] = operationID
+	c.shares[operation] = share
+	c.metrics[operation] = &vegeta.Metrics{}
+}
 
-	for res := range attacker.Attack(targeter, pacer, duration, "Performance Test") {
-		metrics.Add(res)
+// add attributes a result to the operation whose target produced it
+func (c *endpointCollector) add(res *vegeta.Result) {
+	operation, ok := c.operations[res.URL]
+	if !ok {
+		operation = res.Method + " " + res.URL
+		if c.metrics[operation] == nil {
+			c.metrics[operation] = &vegeta.Metrics{}
+		}
 	}
-	metrics.Close()
+	c.metrics[operation].Add(res)
 
-	perfMetrics := &PerformanceMetrics{
-		StartTime:      time.Now().Add(-duration),
-		EndTime:        time.Now(),
-		TotalRequests:  metrics.Requests,
-		SuccessCount:   uint64(float64(metrics.Requests) * metrics.Success),
-		ErrorCount:     uint64(float64(metrics.Requests) * (1 - metrics.Success)),
-		ErrorRate:      1 - metrics.Success,
-		LatencyP50:     metrics.Latencies.P50,
-		LatencyP95:     metrics.Latencies.P95,
-		LatencyP99:     metrics.Latencies.P99,
-		RequestsPerSec: float64(metrics.Requests) / duration.Seconds(),
+	if res.Code >= 200 && res.Code < 400 {
+		return
+	}
+	if len(c.failed[operation]) < maxFailedSamples {
+		method, path, _ := strings.Cut(operation, " ")
+		c.failed[operation] = append(c.failed[operation], FailedRequest{
+			Method:     method,
+			Path:       path,
+			StatusCode: int(res.Code),
+			Error:      res.Error,
+			Timestamp:  res.Timestamp,
+			Latency:    res.Latency,
+		})
 	}
+}
 
-	return &PerformanceTestResult{Performance: perfMetrics}, nil
+// sortedOperations returns the tracked operations ordered by path, then method
+func (c *endpointCollector) sortedOperations() []string {
+	operations := make([]string, 0, len(c.metrics))
+	for operation := range c.metrics {
+		operations = append(operations, operation)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		mi, pi, _ := strings.Cut(operations[i], " ")
+		mj, pj, _ := strings.Cut(operations[j], " ")
+		if pi != pj {
+			return pi < pj
+		}
+		return mi < mj
+	})
+	return operations
+}
+
+// results closes the per-operation metrics and checks each operation against its SLO
+func (c *endpointCollector) results(targets *PerformanceTargetConfig) []EndpointMetrics {
+	var results []EndpointMetrics
+	for _, operation := range c.sortedOperations() {
+		metrics := c.metrics[operation]
+		metrics.Close()
+		if metrics.Requests == 0 {
+			continue
+		}
+		method, path, _ := strings.Cut(operation, " ")
+		successCount, errorCount, errorRate := requestOutcomes(metrics)
+		endpoint := EndpointMetrics{
+			Method:         method,
+			Path:           path,
+			OperationID:    c.operationIDs[operation],
+			Share:          c.shares[operation],
+			TotalRequests:  metrics.Requests,
+			SuccessCount:   successCount,
+			ErrorCount:     errorCount,
+			ErrorRate:      errorRate,
+			LatencyP50:     metrics.Latencies.P50,
+			LatencyP95:     metrics.Latencies.P95,
+			LatencyP99:     metrics.Latencies.P99,
+			RequestsPerSec: metrics.Rate,
+			Throughput:     metrics.Throughput,
+			StatusCodes:    metrics.StatusCodes,
+			Target:         targets.endpointTarget(operation, c.operationIDs[operation]),
+		}
+		endpoint.Failures = endpoint.Target.check(endpoint)
+		endpoint.Passed = len(endpoint.Failures) == 0
+		results = append(results, endpoint)
+	}
+	return results
+}
+
+// failedRequests returns the sampled failures of every operation
+func (c *endpointCollector) failedRequests() []FailedRequest {
+	var failed []FailedRequest
+	for _, operation := range c.sortedOperations() {
+		failed = append(failed, c.failed[operation]...)
+	}
+	return failed
+}
+
+// endpointTarget returns the SLO of an operation: its own entry, looked up by "METHOD path"
+// and then operationId, with unset fields taken from the global targets
+func (c *PerformanceTargetConfig) endpointTarget(operation, operationID string) EndpointTarget {
+	target, ok := c.Endpoints[operation]
+	if !ok && operationID != "" {
+		target = c.Endpoints[operationID]
+	}
+	if target.MaxLatencyP95 == 0 {
+		target.MaxLatencyP95 = c.MaxLatencyP95
+	}
+	if target.MinSuccessRate == 0 {
+		target.MinSuccessRate = c.MinSuccessRate
+	}
+	return target
+}
+
+// check returns the ways an operation's metrics miss the target
+func (target EndpointTarget) check(endpoint EndpointMetrics) []string {
+	var failures []string
+	if target.MaxLatencyP95 > 0 && endpoint.LatencyP95 > target.MaxLatencyP95 {
+		failures = append(failures, fmt.Sprintf("P95 latency (%s) exceeded target (%s)", endpoint.LatencyP95, target.MaxLatencyP95))
+	}
+	if target.MaxLatencyP99 > 0 && endpoint.LatencyP99 > target.MaxLatencyP99 {
+		failures = append(failures, fmt.Sprintf("P99 latency (%s) exceeded target (%s)", endpoint.LatencyP99, target.MaxLatencyP99))
+	}
+	if successRate := 1.0 - endpoint.ErrorRate; target.MinSuccessRate > 0 && successRate < target.MinSuccessRate {
+		failures = append(failures, fmt.Sprintf("Success rate (%.2f%%) below target (%.2f%%)", successRate*100, target.MinSuccessRate*100))
+	}
+	return failures
+}
+
+// ParseEndpointTarget parses a per-operation SLO of the form
+// "<METHOD path|operationId>@p95=800ms;p99=2s;success=0.95"
+func ParseEndpointTarget(value string) (string, EndpointTarget, error) {
+	var target EndpointTarget
+	idx := strings.LastIndex(value, "@")
+	if idx <= 0 {
+		return "", target, fmt.Errorf("invalid endpoint SLO %q: expected <operation>@<setting>=<value>;...", value)
+	}
+	operation := normalizeOperationKey(value[:idx])
+	for _, setting := range strings.Split(value[idx+1:], ";") {
+		if strings.TrimSpace(setting) == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(setting, "=")
+		if !ok {
+			return "", target, fmt.Errorf("invalid endpoint SLO setting %q for %s", setting, operation)
+		}
+		var err error
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "p95":
+			target.MaxLatencyP95, err = time.ParseDuration(strings.TrimSpace(raw))
+		case "p99":
+			target.MaxLatencyP99, err = time.ParseDuration(strings.TrimSpace(raw))
+		case "success":
+			target.MinSuccessRate, err = strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err == nil && (target.MinSuccessRate < 0 || target.MinSuccessRate > 1) {
+				err = fmt.Errorf("must be between 0 and 1")
+			}
+		default:
+			return "", target, fmt.Errorf("unknown endpoint SLO setting %q for %s", name, operation)
+		}
+		if err != nil {
+			return "", target, fmt.Errorf("invalid endpoint SLO %s for %s: %w", name, operation, err)
+		}
+	}
+	return operation, target, nil
 }
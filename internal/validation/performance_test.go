@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"net/http"
+	"testing"
+
+	vegeta "github.com/tsenart/vegeta/v12/lib"
+)
+
+func TestEndpointCollectorAdd(t *testing.T) {
+	c := newEndpointCollector()
+	tracked := vegeta.Target{Method: http.MethodGet, URL: tagTargetURL("http://api/tasks", "listTasks")}
+	c.track(tracked, http.MethodGet, "/tasks", "listTasks", 1)
+
+	results := []*vegeta.Result{
+		{Method: http.MethodGet, URL: tracked.URL, Code: 200},
+		{Method: http.MethodGet, URL: tracked.URL, Code: 500},
+		{Method: http.MethodGet, URL: "http://api/other", Code: 200},
+		{Method: http.MethodGet, URL: "http://api/other", Code: 200},
+		{Method: http.MethodGet, URL: "http://api/other", Code: 404},
+	}
+	for _, res := range results {
+		c.add(res)
+	}
+
+	tests := []struct {
+		operation string
+		requests  uint64
+		failed    int
+	}{
+		{operation: "GET /tasks", requests: 2, failed: 1},
+		{operation: "GET http://api/other", requests: 3, failed: 1},
+	}
+	for _, tt := range tests {
+		metrics := c.metrics[tt.operation]
+		if metrics == nil {
+			t.Errorf("no metrics for %s", tt.operation)
+			continue
+		}
+		if metrics.Requests != tt.requests {
+			t.Errorf("%s has %d requests, want %d", tt.operation, metrics.Requests, tt.requests)
+		}
+		if got := len(c.failed[tt.operation]); got != tt.failed {
+			t.Errorf("%s has %d failed samples, want %d", tt.operation, got, tt.failed)
+		}
+	}
+}
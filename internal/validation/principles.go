@@ -178,13 +178,16 @@ var CorePrinciples = []Principle{
 
 // Logger handles validation report logging
 type Logger struct {
-	logger *logrus.Logger
-	path   string
+	logger  *logrus.Logger
+	path    string
+	history *HistoryStore // Keeps logged reports when set
+	command string        // Command recorded with the reports kept in the history
 }
 
-// NewLogger creates a new validation logger
+// NewLogger creates a new validation logger writing to logPath, or to standard output
+// when logPath is empty or "stdout"
 func NewLogger(logPath string) (*Logger, error) {
-	if logPath == "" {
+	if logPath == "" || logPath == "stdout" {
 		logger := logrus.New()
 		logger.SetFormatter(&logrus.JSONFormatter{})
 		logger.SetLevel(logrus.DebugLevel)
@@ -217,17 +220,31 @@ func NewLogger(logPath string) (*Logger, error) {
 	}, nil
 }
 
-// LogReport logs a validation report
+// LogReport logs a validation report. The report is not modified, so the run keeps the
+// timestamp of its saved report files.
 func (l *Logger) LogReport(report *ValidationReport) error {
-	report.Timestamp = time.Now()
+	timestamp := report.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
 
 	entry := l.logger.WithFields(logrus.Fields{
 		"type":      "validation_report",
 		"version":   report.Version,
 		"env":       report.Environment,
-		"timestamp": report.Timestamp,
+		"timestamp": timestamp,
 	})
 
+	// The history holds the full report, so only its run ID is logged
+	if l.history != nil {
+		id, err := l.history.Save(l.command, report)
+		if err != nil {
+			return fmt.Errorf("failed to record report in history: %w", err)
+		}
+		entry.WithField("run_id", id).Info("validation report recorded")
+		return nil
+	}
+
 	// Log the full report as JSON
 	reportJSON, err := json.Marshal(report)
 	if err != nil {
@@ -238,9 +255,26 @@ func (l *Logger) LogReport(report *ValidationReport) error {
 	return nil
 }
 
-// GetRecentReports retrieves recent validation reports
+// UseHistory makes the logger keep every logged report, with the command that produced
+// it, in the history store
+func (l *Logger) UseHistory(store *HistoryStore, command string) {
+	l.history = store
+	l.command = command
+}
+
+// GetRecentReports retrieves up to limit validation reports from the history, most
+// recent first
 func (l *Logger) GetRecentReports(limit int) ([]ValidationReport, error) {
-	// Implementation to read and parse recent reports from the log file
-	// This would be implemented based on your specific needs
-	return nil, nil
+	if l.history == nil {
+		return nil, fmt.Errorf("no report history configured")
+	}
+	records, err := l.history.List(HistoryFilter{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]ValidationReport, 0, len(records))
+	for _, record := range records {
+		reports = append(reports, *record.Report)
+	}
+	return reports, nil
 }
@@ -0,0 +1,187 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// PrincipleDefinition is a spec principle together with the function that checks a
+// document against it
+type PrincipleDefinition struct {
+	Principle Principle
+	Minimal   bool   // Also run in minimal validation mode; every other mode runs all principles
+	Source    string // Where the principle was defined, e.g. a rule file; empty for Go code
+	// Validate checks a document. The validation mode of the run is available from the
+	// context with ValidationModeFrom. Findings put in the result are located in the
+	// spec file; otherwise they are itemized from its details like those of the core
+	// principles.
+	Validate func(ctx context.Context, doc *openapi3.T) PrincipleResult
+}
+
+// PrincipleRegistry holds the spec principles a validator can run, in registration order
+type PrincipleRegistry struct {
+	mu          sync.RWMutex
+	definitions []PrincipleDefinition
+}
+
+// DefaultRegistry holds the core spec principles and those added with RegisterPrinciple.
+// Validators use it unless their config names another registry.
+var DefaultRegistry = NewPrincipleRegistry()
+
+// RegisterPrinciple adds a principle to the default registry. Principles written in Go
+// are registered from an init function of a file built into the binary.
+func RegisterPrinciple(definition PrincipleDefinition) error {
+	return DefaultRegistry.Register(definition)
+}
+
+// NewPrincipleRegistry creates a registry holding the core spec principles
+func NewPrincipleRegistry() *PrincipleRegistry {
+	r := &PrincipleRegistry{}
+	for _, definition := range corePrincipleDefinitions() {
+		if err := r.Register(definition); err != nil {
+			panic(fmt.Sprintf("invalid core principle: %v", err))
+		}
+	}
+	return r
+}
+
+// corePrincipleDefinitions returns the built-in spec principles
+func corePrincipleDefinitions() []PrincipleDefinition {
+	return []PrincipleDefinition{
+		{Principle: CorePrinciples[0], Minimal: true, Validate: validateOpenAPICompliance},
+		{Principle: CorePrinciples[1], Validate: validateDocumentationQuality},
+		{Principle: CorePrinciples[2], Validate: validateErrorHandling},
+		{Principle: CorePrinciples[3], Minimal: true, Validate: validateRequestSchema},
+		{Principle: CorePrinciples[4], Validate: validateAuthentication},
+		{Principle: CorePrinciples[7], Validate: validateVersioning},
+	}
+}
+
+// Register adds a principle. IDs must be unique and must not be those of the principles
+// that test the running API.
+func (r *PrincipleRegistry) Register(definition PrincipleDefinition) error {
+	principle := definition.Principle
+	if principle.ID == "" {
+		return fmt.Errorf("principle ID is required")
+	}
+	if principle.Name == "" {
+		return fmt.Errorf("principle %s has no name", principle.ID)
+	}
+	switch principle.Severity {
+	case "critical", "warning", "info":
+	default:
+		return fmt.Errorf("principle %s has unknown severity %q (expected critical, warning or info)", principle.ID, principle.Severity)
+	}
+	if definition.Validate == nil {
+		return fmt.Errorf("principle %s has no validate function", principle.ID)
+	}
+	if !IsSpecPrinciple(principle.ID) {
+		return fmt.Errorf("principle ID %s is reserved for API tests", principle.ID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.definitions {
+		if existing.Principle.ID == principle.ID {
+			if existing.Source != "" {
+				return fmt.Errorf("principle %s is already defined in %s", principle.ID, existing.Source)
+			}
+			return fmt.Errorf("principle %s is already registered", principle.ID)
+		}
+	}
+	r.definitions = append(r.definitions, definition)
+	return nil
+}
+
+// Clone returns a copy of the registry, so that principles can be added to it without
+// changing the original
+func (r *PrincipleRegistry) Clone() *PrincipleRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &PrincipleRegistry{definitions: append([]PrincipleDefinition(nil), r.definitions...)}
+}
+
+// Lookup returns the principle with the given ID
+func (r *PrincipleRegistry) Lookup(id string) (PrincipleDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, definition := range r.definitions {
+		if definition.Principle.ID == id {
+			return definition, true
+		}
+	}
+	return PrincipleDefinition{}, false
+}
+
+// Definitions returns every registered principle, in registration order
+func (r *PrincipleRegistry) Definitions() []PrincipleDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]PrincipleDefinition(nil), r.definitions...)
+}
+
+// IDs returns the IDs of the registered principles, in registration order
+func (r *PrincipleRegistry) IDs() []string {
+	definitions := r.Definitions()
+	ids := make([]string, len(definitions))
+	for i, definition := range definitions {
+		ids[i] = definition.Principle.ID
+	}
+	return ids
+}
+
+// CheckIDs checks that principles selected to run are registered
+func (r *PrincipleRegistry) CheckIDs(ids []string) error {
+	for _, id := range ids {
+		if _, ok := r.Lookup(id); !ok {
+			return fmt.Errorf("unknown spec principle %q (expected one of %s)", id, strings.Join(r.IDs(), ", "))
+		}
+	}
+	return nil
+}
+
+// forMode returns the principles run by default in a validation mode
+func (r *PrincipleRegistry) forMode(mode ValidationMode) []PrincipleDefinition {
+	var definitions []PrincipleDefinition
+	for _, definition := range r.Definitions() {
+		if mode != ValidationModeMinimal || definition.Minimal {
+			definitions = append(definitions, definition)
+		}
+	}
+	return definitions
+}
+
+// selectPrinciples returns the principles with the given IDs, in registration order
+func (r *PrincipleRegistry) selectPrinciples(ids []string) []PrincipleDefinition {
+	var definitions []PrincipleDefinition
+	for _, definition := range r.Definitions() {
+		for _, id := range ids {
+			if definition.Principle.ID == id {
+				definitions = append(definitions, definition)
+				break
+			}
+		}
+	}
+	return definitions
+}
+
+// validationModeKey is the context key of the validation mode
+type validationModeKey struct{}
+
+// withValidationMode returns a context carrying the validation mode of a run
+func withValidationMode(ctx context.Context, mode ValidationMode) context.Context {
+	return context.WithValue(ctx, validationModeKey{}, mode)
+}
+
+// ValidationModeFrom returns the validation mode a principle is run in, or the minimal
+// mode when the context carries none
+func ValidationModeFrom(ctx context.Context) ValidationMode {
+	if mode, ok := ctx.Value(validationModeKey{}).(ValidationMode); ok && mode != "" {
+		return mode
+	}
+	return ValidationModeMinimal
+}
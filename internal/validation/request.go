@@ -0,0 +1,239 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/meter-peter/driveby/internal/openapi"
+)
+
+// RequestInput holds the parameter and body values used to build a request
+type RequestInput struct {
+	PathParams  map[string]interface{} `json:"path_params,omitempty"`
+	QueryParams map[string]interface{} `json:"query_params,omitempty"`
+	Headers     map[string]interface{} `json:"headers,omitempty"`
+	Cookies     map[string]interface{} `json:"cookies,omitempty"`
+	Body        interface{}            `json:"body,omitempty"`
+	ContentType string                 `json:"content_type,omitempty"`
+}
+
+// reservedHeaders are header parameters the OpenAPI spec says must be ignored
+var reservedHeaders = map[string]bool{
+	"accept":        true,
+	"content-type":  true,
+	"authorization": true,
+}
+
+// operationParameters merges path-level and operation-level parameters, with
+// operation-level definitions overriding path-level ones of the same name and location
+func operationParameters(pathItem *openapi3.PathItem, operation *openapi3.Operation) openapi3.Parameters {
+	var params openapi3.Parameters
+	seen := make(map[string]int)
+	add := func(ref *openapi3.ParameterRef) {
+		if ref == nil || ref.Value == nil {
+			return
+		}
+		key := ref.Value.In + ":" + ref.Value.Name
+		if idx, ok := seen[key]; ok {
+			params[idx] = ref
+			return
+		}
+		seen[key] = len(params)
+		params = append(params, ref)
+	}
+	if pathItem != nil {
+		for _, ref := range pathItem.Parameters {
+			add(ref)
+		}
+	}
+	for _, ref := range operation.Parameters {
+		add(ref)
+	}
+	return params
+}
+
+// synthesizeRequest fills path, query, header and cookie parameters and the JSON request
+// body of an operation. Spec examples and defaults are preferred; otherwise values are
+// generated from the schema. Optional parameters are only sent when they document a value.
+func synthesizeRequest(pathItem *openapi3.PathItem, operation *openapi3.Operation) *RequestInput {
+	input := &RequestInput{
+		PathParams:  make(map[string]interface{}),
+		QueryParams: make(map[string]interface{}),
+		Headers:     make(map[string]interface{}),
+		Cookies:     make(map[string]interface{}),
+	}
+
+	for _, ref := range operationParameters(pathItem, operation) {
+		param := ref.Value
+		if !param.Required && param.In != openapi3.ParameterInPath && !hasDocumentedValue(param) {
+			continue
+		}
+		value := openapi.ParameterExample(param)
+		if value == nil {
+			continue
+		}
+		switch param.In {
+		case openapi3.ParameterInPath:
+			input.PathParams[param.Name] = value
+		case openapi3.ParameterInQuery:
+			input.QueryParams[param.Name] = value
+		case openapi3.ParameterInHeader:
+			if reservedHeaders[strings.ToLower(param.Name)] {
+				continue
+			}
+			input.Headers[param.Name] = value
+		case openapi3.ParameterInCookie:
+			input.Cookies[param.Name] = value
+		}
+	}
+
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		contentType, mediaType := openapi.JSONMediaType(operation.RequestBody.Value.Content)
+		if mediaType != nil {
+			input.ContentType = contentType
+			input.Body = openapi.MediaTypeExample(mediaType)
+		}
+	}
+
+	return input
+}
+
+// unmatchedPatterns names the parameters whose value does not match their schema
+// pattern, because no matching value could be generated for it
+func unmatchedPatterns(pathItem *openapi3.PathItem, operation *openapi3.Operation, input *RequestInput) []string {
+	var names []string
+	for _, ref := range operationParameters(pathItem, operation) {
+		param := ref.Value
+		if param.Schema == nil || param.Schema.Value == nil || param.Schema.Value.Pattern == "" {
+			continue
+		}
+		var values map[string]interface{}
+		switch param.In {
+		case openapi3.ParameterInPath:
+			values = input.PathParams
+		case openapi3.ParameterInQuery:
+			values = input.QueryParams
+		case openapi3.ParameterInHeader:
+			values = input.Headers
+		case openapi3.ParameterInCookie:
+			values = input.Cookies
+		}
+		if value, ok := values[param.Name].(string); ok && !openapi.MatchesPattern(param.Schema.Value.Pattern, value) {
+			names = append(names, param.Name)
+		}
+	}
+	return names
+}
+
+// hasDocumentedValue reports whether a parameter carries an explicit example or default
+func hasDocumentedValue(param *openapi3.Parameter) bool {
+	if param.Example != nil || len(param.Examples) > 0 {
+		return true
+	}
+	if param.Schema != nil && param.Schema.Value != nil {
+		return param.Schema.Value.Example != nil || param.Schema.Value.Default != nil
+	}
+	return false
+}
+
+// buildHTTPRequest renders a RequestInput into an HTTP request for the given templated path
+func buildHTTPRequest(ctx context.Context, baseURL, method, path string, input *RequestInput) (*http.Request, error) {
+	if input == nil {
+		input = &RequestInput{}
+	}
+
+	resolvedPath := path
+	for name, value := range input.PathParams {
+		resolvedPath = strings.ReplaceAll(resolvedPath, "{"+name+"}", url.PathEscape(openapi.FormatParameterValue(value)))
+	}
+	if strings.Contains(resolvedPath, "{") {
+		return nil, fmt.Errorf("unresolved path parameters in %s", resolvedPath)
+	}
+
+	target := strings.TrimRight(baseURL, "/") + resolvedPath
+	if len(input.QueryParams) > 0 {
+		query := url.Values{}
+		for _, name := range sortedInputKeys(input.QueryParams) {
+			switch value := input.QueryParams[name].(type) {
+			case []interface{}:
+				for _, item := range value {
+					query.Add(name, openapi.FormatParameterValue(item))
+				}
+			default:
+				query.Set(name, openapi.FormatParameterValue(value))
+			}
+		}
+		target += "?" + query.Encode()
+	}
+
+	body, err := encodeRequestBody(input.ContentType, input.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if input.Body != nil {
+		contentType := input.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range input.Headers {
+		req.Header.Set(name, openapi.FormatParameterValue(value))
+	}
+	for name, value := range input.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: openapi.FormatParameterValue(value)})
+	}
+
+	return req, nil
+}
+
+// encodeRequestBody serialises a body value according to its content type
+func encodeRequestBody(contentType string, body interface{}) (io.Reader, error) {
+	if body == nil {
+		return nil, nil
+	}
+	switch {
+	case contentType == "" || strings.Contains(contentType, "json"):
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		return bytes.NewReader(data), nil
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		fields, ok := body.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("form request body must be an object, got %T", body)
+		}
+		form := url.Values{}
+		for _, name := range sortedInputKeys(fields) {
+			form.Set(name, openapi.FormatParameterValue(fields[name]))
+		}
+		return strings.NewReader(form.Encode()), nil
+	default:
+		return strings.NewReader(openapi.FormatParameterValue(body)), nil
+	}
+}
+
+func sortedInputKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
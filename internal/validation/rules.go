@@ -0,0 +1,1085 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// ruleFile is a declarative set of principles, similar to a Spectral ruleset
+type ruleFile struct {
+	Principles []rulePrinciple `yaml:"principles"`
+}
+
+// rulePrinciple is a principle whose checks are declarative rules
+type rulePrinciple struct {
+	ID          string     `yaml:"id"`
+	Name        string     `yaml:"name"`
+	Description string     `yaml:"description"`
+	Category    string     `yaml:"category"`
+	Severity    string     `yaml:"severity"`
+	Tags        []string   `yaml:"tags"`
+	Minimal     bool       `yaml:"minimal"`
+	Rules       []ruleSpec `yaml:"rules"`
+}
+
+// ruleSpec applies functions to the fields of the spec nodes its paths select. Given
+// and then take a single value or a list.
+type ruleSpec struct {
+	Check   string     `yaml:"check"`   // Principle check the rule implements
+	Message string     `yaml:"message"` // Finding message template; defaults to "{{path}}: {{error}}"
+	Given   ruleList   `yaml:"given"`
+	Then    []ruleThen `yaml:"-"`
+}
+
+// ruleThen is a function applied to a field of each selected node
+type ruleThen struct {
+	Field           string                 `yaml:"field"` // Relative path, "@key" for the keys of the node, or empty for the node itself
+	Function        string                 `yaml:"function"`
+	FunctionOptions map[string]interface{} `yaml:"functionOptions"`
+}
+
+// ruleList is a list of strings that may be written as a single string
+type ruleList []string
+
+// UnmarshalYAML accepts a string or a list of strings
+func (l *ruleList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = ruleList{value.Value}
+		return nil
+	}
+	var values []string
+	if err := value.Decode(&values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// UnmarshalYAML decodes a rule, whose then is a single function or a list of them
+func (r *ruleSpec) UnmarshalYAML(value *yaml.Node) error {
+	if err := checkRuleKeys(value, "check", "message", "given", "then"); err != nil {
+		return err
+	}
+	type plain ruleSpec
+	var rule struct {
+		plain `yaml:",inline"`
+		Then  yaml.Node `yaml:"then"`
+	}
+	if err := value.Decode(&rule); err != nil {
+		return err
+	}
+	*r = ruleSpec(rule.plain)
+	thenNodes := []*yaml.Node{&rule.Then}
+	switch rule.Then.Kind {
+	case 0:
+		return nil
+	case yaml.SequenceNode:
+		thenNodes = rule.Then.Content
+	}
+	for _, node := range thenNodes {
+		if err := checkRuleKeys(node, "field", "function", "functionOptions"); err != nil {
+			return err
+		}
+		var then ruleThen
+		if err := node.Decode(&then); err != nil {
+			return err
+		}
+		r.Then = append(r.Then, then)
+	}
+	return nil
+}
+
+// checkRuleKeys reports keys of a mapping that are not known, which decoding inside
+// UnmarshalYAML does not
+func checkRuleKeys(node *yaml.Node, keys ...string) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i]; !containsString(keys, key.Value) {
+			return fmt.Errorf("line %d: field %s not found (expected one of %s)", key.Line, key.Value, strings.Join(keys, ", "))
+		}
+	}
+	return nil
+}
+
+// LoadRules adds the principles of a declarative rule file to the registry
+func (r *PrincipleRegistry) LoadRules(path string) error {
+	definitions, err := LoadRuleFile(path)
+	if err != nil {
+		return err
+	}
+	for _, definition := range definitions {
+		if err := r.Register(definition); err != nil {
+			return fmt.Errorf("invalid rule file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// LoadRuleFile reads the principles of a declarative YAML or JSON rule file
+func LoadRuleFile(path string) ([]PrincipleDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule file: %w", err)
+	}
+	var file ruleFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse rule file %s: %w", path, err)
+	}
+	if len(file.Principles) == 0 {
+		return nil, fmt.Errorf("rule file %s defines no principles", path)
+	}
+
+	var definitions []PrincipleDefinition
+	for i, spec := range file.Principles {
+		definition, err := compileRulePrinciple(spec)
+		if err != nil {
+			name := spec.ID
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			return nil, fmt.Errorf("invalid principle %s in rule file %s: %w", name, path, err)
+		}
+		definition.Source = path
+		definitions = append(definitions, definition)
+	}
+	return definitions, nil
+}
+
+// compiledRule is a rule whose paths and functions are parsed
+type compiledRule struct {
+	check   string
+	message string
+	given   []rulePath
+	then    []compiledThen
+}
+
+// compiledThen is a parsed function applied to a field
+type compiledThen struct {
+	field    rulePath // Nil for the node itself
+	key      bool     // Applied to the keys of the node
+	property string   // Name of the field in messages
+	function ruleFunction
+}
+
+// compileRulePrinciple parses the rules of a principle into its definition
+func compileRulePrinciple(spec rulePrinciple) (PrincipleDefinition, error) {
+	if len(spec.Rules) == 0 {
+		return PrincipleDefinition{}, fmt.Errorf("no rules")
+	}
+	principle := Principle{
+		ID:          spec.ID,
+		Name:        spec.Name,
+		Description: spec.Description,
+		Category:    spec.Category,
+		Severity:    spec.Severity,
+		Tags:        spec.Tags,
+	}
+	if principle.Category == "" {
+		principle.Category = "Custom"
+	}
+
+	var rules []compiledRule
+	for i, ruleSpec := range spec.Rules {
+		rule, err := compileRule(ruleSpec)
+		if err != nil {
+			name := ruleSpec.Check
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			return PrincipleDefinition{}, fmt.Errorf("rule %s: %w", name, err)
+		}
+		rules = append(rules, rule)
+		if !containsString(principle.Checks, rule.check) {
+			principle.Checks = append(principle.Checks, rule.check)
+		}
+	}
+
+	return PrincipleDefinition{
+		Principle: principle,
+		Minimal:   spec.Minimal,
+		Validate: func(ctx context.Context, doc *openapi3.T) PrincipleResult {
+			return evaluateRules(principle, rules, doc)
+		},
+	}, nil
+}
+
+// compileRule parses the paths and functions of a rule
+func compileRule(spec ruleSpec) (compiledRule, error) {
+	if spec.Check == "" {
+		return compiledRule{}, fmt.Errorf("check is required")
+	}
+	if len(spec.Given) == 0 {
+		return compiledRule{}, fmt.Errorf("given is required")
+	}
+	if len(spec.Then) == 0 {
+		return compiledRule{}, fmt.Errorf("then is required")
+	}
+	rule := compiledRule{check: spec.Check, message: spec.Message}
+	if rule.message == "" {
+		rule.message = "{{path}}: {{error}}"
+	}
+	for _, given := range spec.Given {
+		if !strings.HasPrefix(given, "$") {
+			return compiledRule{}, fmt.Errorf("given path %q must start with $", given)
+		}
+		path, err := parseRulePath(given)
+		if err != nil {
+			return compiledRule{}, fmt.Errorf("invalid given path %q: %w", given, err)
+		}
+		rule.given = append(rule.given, path)
+	}
+	for _, thenSpec := range spec.Then {
+		then := compiledThen{property: thenSpec.Field}
+		switch {
+		case thenSpec.Field == "@key":
+			then.key = true
+		case thenSpec.Field != "":
+			field := thenSpec.Field
+			switch {
+			case strings.HasPrefix(field, "$"):
+			case strings.HasPrefix(field, "["):
+				field = "$" + field
+			default:
+				field = "$." + field
+			}
+			path, err := parseRulePath(field)
+			if err != nil {
+				return compiledRule{}, fmt.Errorf("invalid field %q: %w", thenSpec.Field, err)
+			}
+			then.field = path
+			if last := path[len(path)-1]; last.kind == segmentChild && len(last.names) == 1 {
+				then.property = last.names[0]
+			}
+		}
+		function, err := compileRuleFunction(thenSpec.Function, thenSpec.FunctionOptions)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		then.function = function
+		rule.then = append(rule.then, then)
+	}
+	return rule, nil
+}
+
+// evaluateRules checks a document against the rules of a principle
+func evaluateRules(principle Principle, rules []compiledRule, doc *openapi3.T) PrincipleResult {
+	result := PrincipleResult{
+		Principle: principle,
+		Passed:    true,
+	}
+	root, err := ruleDocument(doc)
+	if err != nil {
+		result.Passed = false
+		result.Message = fmt.Sprintf("Failed to read the spec: %v", err)
+		return result
+	}
+
+	checks := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if _, ok := checks[rule.check]; !ok {
+			checks[rule.check] = true
+		}
+		for _, given := range rule.given {
+			for _, node := range given.evaluate(root, ruleNode{value: root, present: true}) {
+				node = resolveRuleNode(root, node)
+				for _, then := range rule.then {
+					for _, target := range then.targets(root, node) {
+						problem := then.function(target.node, target.property)
+						if problem == "" {
+							continue
+						}
+						finding := Finding{
+							Check:   rule.check,
+							Message: formatRuleMessage(rule.message, problem, target),
+							Pointer: rulePointer(target.node.tokens),
+						}
+						key := finding.Check + "\x00" + finding.Pointer + "\x00" + finding.Message
+						if seen[key] {
+							continue
+						}
+						seen[key] = true
+						checks[rule.check] = false
+						result.Findings = append(result.Findings, finding)
+					}
+				}
+			}
+		}
+	}
+
+	result.Details = map[string]interface{}{"checks": checks}
+	if len(result.Findings) == 0 {
+		result.Message = fmt.Sprintf("All %d checks passed", len(checks))
+		return result
+	}
+	result.Passed = false
+	var failed []string
+	for _, check := range principle.Checks {
+		if !checks[check] {
+			failed = append(failed, check)
+		}
+	}
+	result.Message = fmt.Sprintf("%d of %d checks failed: %s", len(failed), len(checks), strings.Join(failed, "; "))
+	return result
+}
+
+// ruleDocument converts a document to the generic JSON form rules are evaluated on
+func ruleDocument(doc *openapi3.T) (interface{}, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// ruleNode is a value in the document and its location
+type ruleNode struct {
+	value   interface{}
+	tokens  []string // JSON pointer tokens of the node
+	present bool     // False for fields the document does not have
+}
+
+// ruleTarget is a value a rule function is applied to
+type ruleTarget struct {
+	node     ruleNode
+	property string
+	location []string // Where findings say the value is, when not at the node itself
+}
+
+// targets returns the values the function is applied to for a selected node. A field the
+// node does not have is a single target that is not present.
+func (t compiledThen) targets(root interface{}, node ruleNode) []ruleTarget {
+	switch {
+	case t.key:
+		object, ok := node.value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		var targets []ruleTarget
+		for _, key := range sortedKeys(object) {
+			tokens := appendToken(node.tokens, key)
+			targets = append(targets, ruleTarget{node: ruleNode{value: key, tokens: tokens, present: true}, property: key, location: node.tokens})
+		}
+		return targets
+	case t.field == nil:
+		return []ruleTarget{{node: node, property: lastToken(node.tokens)}}
+	}
+
+	var targets []ruleTarget
+	for _, field := range t.field.evaluate(root, node) {
+		field = resolveRuleNode(root, field)
+		targets = append(targets, ruleTarget{node: field, property: lastToken(field.tokens)})
+	}
+	if len(targets) == 0 {
+		targets = append(targets, ruleTarget{node: ruleNode{tokens: node.tokens}, property: t.property})
+	}
+	return targets
+}
+
+// formatRuleMessage fills in the placeholders of a finding message: {{error}},
+// {{path}}, {{property}} and {{value}}
+func formatRuleMessage(message, problem string, target ruleTarget) string {
+	value := ""
+	if target.node.present {
+		value = fmt.Sprintf("%v", target.node.value)
+	}
+	location := target.node.tokens
+	if target.location != nil {
+		location = target.location
+	}
+	path := ruleLocation(location)
+	if path == "" && strings.HasPrefix(message, "{{path}}: ") {
+		message = strings.TrimPrefix(message, "{{path}}: ")
+	}
+	return strings.NewReplacer(
+		"{{error}}", problem,
+		"{{path}}", path,
+		"{{property}}", target.property,
+		"{{value}}", value,
+	).Replace(message)
+}
+
+// ruleLocation describes a location in the spec, e.g. "GET /tasks responses.200" or
+// "components.schemas.Task"
+func ruleLocation(tokens []string) string {
+	if len(tokens) >= 2 && tokens[0] == "paths" {
+		location := tokens[1]
+		rest := tokens[2:]
+		if len(rest) > 0 && isHTTPMethod(strings.ToUpper(rest[0])) {
+			location = strings.ToUpper(rest[0]) + " " + location
+			rest = rest[1:]
+		}
+		if len(rest) > 0 {
+			location += " " + strings.Join(rest, ".")
+		}
+		return location
+	}
+	return strings.Join(tokens, ".")
+}
+
+// rulePointer returns the JSON pointer of a location, or an empty string for the root
+func rulePointer(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return specPointer(tokens...)
+}
+
+// maxRefHops limits how many references are followed to resolve a node
+const maxRefHops = 16
+
+// resolveRuleNode follows local references, so that rules see referenced components as
+// if they were inlined
+func resolveRuleNode(root interface{}, node ruleNode) ruleNode {
+	for hops := 0; hops < maxRefHops; hops++ {
+		object, ok := node.value.(map[string]interface{})
+		if !ok {
+			return node
+		}
+		ref, ok := object["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return node
+		}
+		var tokens []string
+		for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			tokens = append(tokens, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+		}
+		value, ok := lookupTokens(root, tokens)
+		if !ok {
+			return node
+		}
+		node = ruleNode{value: value, tokens: tokens, present: true}
+	}
+	return node
+}
+
+// lookupTokens returns the value at a JSON pointer
+func lookupTokens(root interface{}, tokens []string) (interface{}, bool) {
+	current := root
+	for _, token := range tokens {
+		switch value := current.(type) {
+		case map[string]interface{}:
+			child, ok := value[token]
+			if !ok {
+				return nil, false
+			}
+			current = child
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
+			}
+			current = value[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// segmentKind is the kind of a rule path segment
+type segmentKind int
+
+const (
+	segmentChild    segmentKind = iota // Named children: .name, ['name'] or [a,b]
+	segmentWildcard                    // Every child: .* or [*]
+	segmentFilter                      // Children matching a filter: [?(...)]
+)
+
+// pathSegment is a step of a rule path
+type pathSegment struct {
+	kind      segmentKind
+	recursive bool // Applied to the node and all its descendants: ..name
+	names     []string
+	filter    func(root interface{}, node ruleNode) bool
+}
+
+// rulePath is a JSONPath subset: $, .name, ['name'], [a,b], [index], .* and [*],
+// ..name for recursive descent, and filters such as [?(@property.match(/^x-/))],
+// [?(!@property.match(/\}$/))], [?(@.type == 'array')] and [?(@.deprecated)]
+type rulePath []pathSegment
+
+// parseRulePath parses a rule path
+func parseRulePath(path string) (rulePath, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with $")
+	}
+	var segments rulePath
+	for i := 1; i < len(path); {
+		recursive := false
+		switch {
+		case strings.HasPrefix(path[i:], ".."):
+			recursive = true
+			i += 2
+		case path[i] == '.':
+			i++
+		case path[i] == '[':
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", path[i], i)
+		}
+		if i >= len(path) {
+			return nil, fmt.Errorf("path ends after a dot")
+		}
+
+		var segment pathSegment
+		if path[i] == '[' {
+			end, err := closingBracket(path, i)
+			if err != nil {
+				return nil, err
+			}
+			segment, err = parseBracket(path[i+1 : end])
+			if err != nil {
+				return nil, err
+			}
+			i = end + 1
+		} else {
+			j := i
+			for j < len(path) && path[j] != '.' && path[j] != '[' {
+				j++
+			}
+			name := path[i:j]
+			if name == "" {
+				return nil, fmt.Errorf("empty segment at %d", i)
+			}
+			segment = pathSegment{kind: segmentChild, names: []string{name}}
+			if name == "*" {
+				segment = pathSegment{kind: segmentWildcard}
+			}
+			i = j
+		}
+		segment.recursive = recursive
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// closingBracket returns the index of the bracket closing the one at start, skipping
+// quoted strings and regular expressions
+func closingBracket(path string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '\'', '"':
+			end := strings.IndexByte(path[i+1:], c)
+			if end < 0 {
+				return 0, fmt.Errorf("unterminated string at %d", i)
+			}
+			i += end + 1
+		case '/':
+			if strings.HasSuffix(strings.TrimSpace(path[start:i]), "match(") {
+				end := i + 1
+				for end < len(path) && (path[end] != '/' || path[end-1] == '\\') {
+					end++
+				}
+				if end >= len(path) {
+					return 0, fmt.Errorf("unterminated regular expression at %d", i)
+				}
+				i = end
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated bracket at %d", start)
+}
+
+// parseBracket parses the content of a bracket segment
+func parseBracket(content string) (pathSegment, error) {
+	content = strings.TrimSpace(content)
+	switch {
+	case content == "*":
+		return pathSegment{kind: segmentWildcard}, nil
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		filter, err := parseRuleFilter(strings.TrimSpace(content[2 : len(content)-1]))
+		if err != nil {
+			return pathSegment{}, err
+		}
+		return pathSegment{kind: segmentFilter, filter: filter}, nil
+	}
+
+	var names []string
+	for _, name := range splitOutsideQuotes(content, ',') {
+		name = strings.TrimSpace(name)
+		if len(name) >= 2 && (name[0] == '\'' || name[0] == '"') && name[len(name)-1] == name[0] {
+			name = name[1 : len(name)-1]
+		}
+		if name == "" {
+			return pathSegment{}, fmt.Errorf("empty name in [%s]", content)
+		}
+		names = append(names, name)
+	}
+	return pathSegment{kind: segmentChild, names: names}, nil
+}
+
+// splitOutsideQuotes splits a string on a separator that is not quoted
+func splitOutsideQuotes(value string, separator byte) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == separator:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// Filter expressions: matching the key or a field against a regular expression,
+// comparing them with a literal, or checking that a field is set
+var (
+	filterMatchPattern   = regexp.MustCompile(`^(!)?@(property|(?:\.[^\s.=!()]+)+)\.match\(\s*/(.*)/([a-z]*)\s*\)$`)
+	filterComparePattern = regexp.MustCompile(`^@(property|(?:\.[^\s.=!()]+)+)\s*(===|!==|==|!=)\s*(.+)$`)
+	filterExistsPattern  = regexp.MustCompile(`^(!)?@((?:\.[^\s.=!()]+)+)$`)
+)
+
+// parseRuleFilter parses a filter expression
+func parseRuleFilter(expression string) (func(root interface{}, node ruleNode) bool, error) {
+	if m := filterMatchPattern.FindStringSubmatch(expression); m != nil {
+		negate := m[1] == "!"
+		pattern, err := compileRulePattern("/" + m[3] + "/" + m[4])
+		if err != nil {
+			return nil, err
+		}
+		operand := filterOperand(m[2])
+		return func(root interface{}, node ruleNode) bool {
+			value, ok := operand(root, node)
+			text, isString := value.(string)
+			return (ok && isString && pattern.MatchString(text)) != negate
+		}, nil
+	}
+	if m := filterComparePattern.FindStringSubmatch(expression); m != nil {
+		literal, err := parseFilterLiteral(strings.TrimSpace(m[3]))
+		if err != nil {
+			return nil, err
+		}
+		negate := strings.HasPrefix(m[2], "!")
+		operand := filterOperand(m[1])
+		return func(root interface{}, node ruleNode) bool {
+			value, ok := operand(root, node)
+			return (ok && reflect.DeepEqual(value, literal)) != negate
+		}, nil
+	}
+	if m := filterExistsPattern.FindStringSubmatch(expression); m != nil {
+		negate := m[1] == "!"
+		operand := filterOperand(m[2])
+		return func(root interface{}, node ruleNode) bool {
+			value, ok := operand(root, node)
+			return (ok && isTruthy(value)) != negate
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported filter %q", expression)
+}
+
+// filterOperand returns how a filter reads the key of a node (@property) or one of its
+// fields (@.a.b)
+func filterOperand(operand string) func(root interface{}, node ruleNode) (interface{}, bool) {
+	if operand == "property" {
+		return func(root interface{}, node ruleNode) (interface{}, bool) {
+			if len(node.tokens) == 0 {
+				return nil, false
+			}
+			return lastToken(node.tokens), true
+		}
+	}
+	fields := strings.Split(strings.TrimPrefix(operand, "."), ".")
+	return func(root interface{}, node ruleNode) (interface{}, bool) {
+		for _, field := range fields {
+			node = resolveRuleNode(root, node)
+			object, ok := node.value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			value, ok := object[field]
+			if !ok {
+				return nil, false
+			}
+			node = ruleNode{value: value, tokens: appendToken(node.tokens, field), present: true}
+		}
+		return resolveRuleNode(root, node).value, true
+	}
+}
+
+// parseFilterLiteral parses a quoted string, number, boolean or null
+func parseFilterLiteral(literal string) (interface{}, error) {
+	if len(literal) >= 2 && (literal[0] == '\'' || literal[0] == '"') && literal[len(literal)-1] == literal[0] {
+		return literal[1 : len(literal)-1], nil
+	}
+	switch literal {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	number, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter literal %q", literal)
+	}
+	return number, nil
+}
+
+// evaluate returns the nodes a path selects, starting from a node
+func (p rulePath) evaluate(root interface{}, start ruleNode) []ruleNode {
+	current := []ruleNode{start}
+	for _, segment := range p {
+		var next []ruleNode
+		for _, node := range current {
+			candidates := []ruleNode{node}
+			if segment.recursive {
+				candidates = descendants(node)
+			}
+			for _, candidate := range candidates {
+				next = append(next, segment.apply(root, candidate)...)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// apply returns the children of a node a segment selects
+func (s pathSegment) apply(root interface{}, node ruleNode) []ruleNode {
+	if !s.recursive {
+		node = resolveRuleNode(root, node)
+	}
+	var children []ruleNode
+	switch value := node.value.(type) {
+	case map[string]interface{}:
+		if s.kind == segmentChild {
+			for _, name := range s.names {
+				if child, ok := value[name]; ok {
+					children = append(children, ruleNode{value: child, tokens: appendToken(node.tokens, name), present: true})
+				}
+			}
+			return children
+		}
+		for _, key := range sortedKeys(value) {
+			children = append(children, ruleNode{value: value[key], tokens: appendToken(node.tokens, key), present: true})
+		}
+	case []interface{}:
+		if s.kind == segmentChild {
+			for _, name := range s.names {
+				index, err := strconv.Atoi(name)
+				if err == nil && index < 0 {
+					index += len(value)
+				}
+				if err == nil && index >= 0 && index < len(value) {
+					children = append(children, ruleNode{value: value[index], tokens: appendToken(node.tokens, strconv.Itoa(index)), present: true})
+				}
+			}
+			return children
+		}
+		for i, item := range value {
+			children = append(children, ruleNode{value: item, tokens: appendToken(node.tokens, strconv.Itoa(i)), present: true})
+		}
+	}
+	if s.kind == segmentFilter {
+		var matched []ruleNode
+		for _, child := range children {
+			if s.filter(root, child) {
+				matched = append(matched, child)
+			}
+		}
+		return matched
+	}
+	return children
+}
+
+// descendants returns a node and everything below it. References are not followed, so
+// recursive cycles cannot occur.
+func descendants(node ruleNode) []ruleNode {
+	nodes := []ruleNode{node}
+	switch value := node.value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			nodes = append(nodes, descendants(ruleNode{value: value[key], tokens: appendToken(node.tokens, key), present: true})...)
+		}
+	case []interface{}:
+		for i, item := range value {
+			nodes = append(nodes, descendants(ruleNode{value: item, tokens: appendToken(node.tokens, strconv.Itoa(i)), present: true})...)
+		}
+	}
+	return nodes
+}
+
+// ruleFunction checks a value and describes the problem, or returns an empty string
+type ruleFunction func(node ruleNode, property string) string
+
+// Letter cases the casing function checks
+var ruleCasings = map[string]*regexp.Regexp{
+	"flat":   regexp.MustCompile(`^[a-z][a-z0-9]*$`),
+	"camel":  regexp.MustCompile(`^[a-z][a-z0-9]*(?:[A-Z][a-z0-9]*)*$`),
+	"pascal": regexp.MustCompile(`^[A-Z][a-z0-9]*(?:[A-Z][a-z0-9]*)*$`),
+	"kebab":  regexp.MustCompile(`^[a-z][a-z0-9]*(?:-[a-z0-9]+)*$`),
+	"cobol":  regexp.MustCompile(`^[A-Z][A-Z0-9]*(?:-[A-Z0-9]+)*$`),
+	"snake":  regexp.MustCompile(`^[a-z][a-z0-9]*(?:_[a-z0-9]+)*$`),
+	"macro":  regexp.MustCompile(`^[A-Z][A-Z0-9]*(?:_[A-Z0-9]+)*$`),
+}
+
+// compileRuleFunction returns a rule function with its options applied: truthy, falsy,
+// defined, undefined, pattern (match, notMatch), casing (type), enumeration (values)
+// and length (min, max). Except for those checking presence, functions pass fields
+// the document does not have.
+func compileRuleFunction(name string, options map[string]interface{}) (ruleFunction, error) {
+	allowed := map[string][]string{
+		"truthy": nil, "falsy": nil, "defined": nil, "undefined": nil,
+		"pattern":     {"match", "notMatch"},
+		"casing":      {"type"},
+		"enumeration": {"values"},
+		"length":      {"min", "max"},
+	}
+	keys, known := allowed[name]
+	if !known {
+		return nil, fmt.Errorf("unknown function %q (expected truthy, falsy, defined, undefined, pattern, casing, enumeration or length)", name)
+	}
+	for option := range options {
+		if !containsString(keys, option) {
+			return nil, fmt.Errorf("unknown option %q of function %s", option, name)
+		}
+	}
+
+	switch name {
+	case "truthy":
+		return func(node ruleNode, property string) string {
+			if node.present && isTruthy(node.value) {
+				return ""
+			}
+			return fmt.Sprintf("%q is missing or empty", property)
+		}, nil
+	case "falsy":
+		return func(node ruleNode, property string) string {
+			if !node.present || !isTruthy(node.value) {
+				return ""
+			}
+			return fmt.Sprintf("%q must not be set", property)
+		}, nil
+	case "defined":
+		return func(node ruleNode, property string) string {
+			if node.present {
+				return ""
+			}
+			return fmt.Sprintf("%q is missing", property)
+		}, nil
+	case "undefined":
+		return func(node ruleNode, property string) string {
+			if !node.present {
+				return ""
+			}
+			return fmt.Sprintf("%q must not be present", property)
+		}, nil
+	case "pattern":
+		var match, notMatch *regexp.Regexp
+		for option, target := range map[string]**regexp.Regexp{"match": &match, "notMatch": &notMatch} {
+			value, ok := options[option]
+			if !ok {
+				continue
+			}
+			text, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("option %s of function pattern must be a string", option)
+			}
+			pattern, err := compileRulePattern(text)
+			if err != nil {
+				return nil, err
+			}
+			*target = pattern
+		}
+		if match == nil && notMatch == nil {
+			return nil, fmt.Errorf("function pattern needs match or notMatch")
+		}
+		return func(node ruleNode, property string) string {
+			text, ok := node.value.(string)
+			if !node.present || !ok {
+				return ""
+			}
+			if match != nil && !match.MatchString(text) {
+				return fmt.Sprintf("%q does not match %s", text, match)
+			}
+			if notMatch != nil && notMatch.MatchString(text) {
+				return fmt.Sprintf("%q must not match %s", text, notMatch)
+			}
+			return ""
+		}, nil
+	case "casing":
+		casing, _ := options["type"].(string)
+		pattern, ok := ruleCasings[casing]
+		if !ok {
+			return nil, fmt.Errorf("function casing needs a type: flat, camel, pascal, kebab, cobol, snake or macro")
+		}
+		return func(node ruleNode, property string) string {
+			text, ok := node.value.(string)
+			if !node.present || !ok || pattern.MatchString(text) {
+				return ""
+			}
+			return fmt.Sprintf("%q is not %s case", text, casing)
+		}, nil
+	case "enumeration":
+		values, ok := options["values"].([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, fmt.Errorf("function enumeration needs a list of values")
+		}
+		allowedValues := make([]string, len(values))
+		for i, value := range values {
+			allowedValues[i] = fmt.Sprintf("%v", value)
+		}
+		return func(node ruleNode, property string) string {
+			if !node.present {
+				return ""
+			}
+			if containsString(allowedValues, fmt.Sprintf("%v", node.value)) {
+				return ""
+			}
+			return fmt.Sprintf("%q is not one of %s", fmt.Sprintf("%v", node.value), strings.Join(allowedValues, ", "))
+		}, nil
+	case "length":
+		minLength, hasMin, err := ruleNumberOption(options, "min")
+		if err != nil {
+			return nil, err
+		}
+		maxLength, hasMax, err := ruleNumberOption(options, "max")
+		if err != nil {
+			return nil, err
+		}
+		if !hasMin && !hasMax {
+			return nil, fmt.Errorf("function length needs min or max")
+		}
+		return func(node ruleNode, property string) string {
+			length, ok := ruleLength(node.value)
+			if !node.present || !ok {
+				return ""
+			}
+			if hasMin && length < minLength {
+				return fmt.Sprintf("%q is shorter than %v", property, minLength)
+			}
+			if hasMax && length > maxLength {
+				return fmt.Sprintf("%q is longer than %v", property, maxLength)
+			}
+			return ""
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown function %q", name)
+}
+
+// compileRulePattern compiles a regular expression, given as is or as "/pattern/flags"
+// with the i flag for case-insensitive matching
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	if end := strings.LastIndexByte(pattern, '/'); strings.HasPrefix(pattern, "/") && end > 0 {
+		flags := pattern[end+1:]
+		pattern = pattern[1:end]
+		if strings.Trim(flags, "i") != "" {
+			return nil, fmt.Errorf("unsupported regular expression flags %q", flags)
+		}
+		if flags != "" {
+			pattern = "(?i)" + pattern
+		}
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+	}
+	return compiled, nil
+}
+
+// ruleNumberOption reads a numeric function option
+func ruleNumberOption(options map[string]interface{}, name string) (float64, bool, error) {
+	value, ok := options[name]
+	if !ok {
+		return 0, false, nil
+	}
+	switch number := value.(type) {
+	case int:
+		return float64(number), true, nil
+	case float64:
+		return number, true, nil
+	}
+	return 0, false, fmt.Errorf("option %s of function length must be a number", name)
+}
+
+// ruleLength returns the length of a string, array or object, or a number itself
+func ruleLength(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), true
+	case []interface{}:
+		return float64(len(v)), true
+	case map[string]interface{}:
+		return float64(len(v)), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// isTruthy reports whether a value is set the way JavaScript truthiness sees it
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	}
+	return true
+}
+
+// appendToken returns the tokens of a child location without sharing the parent's
+func appendToken(tokens []string, token string) []string {
+	child := make([]string, len(tokens), len(tokens)+1)
+	copy(child, tokens)
+	return append(child, token)
+}
+
+// lastToken returns the last token of a location, or an empty string for the root
+func lastToken(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return tokens[len(tokens)-1]
+}
+
+// containsString reports whether a list contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
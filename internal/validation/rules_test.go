@@ -0,0 +1,272 @@
+package validation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// writeRuleFile writes a rule file into a temporary directory and returns its path
+func writeRuleFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestRulePathEvaluate(t *testing.T) {
+	root := map[string]interface{}{
+		"paths": map[string]interface{}{
+			"/tasks": map[string]interface{}{
+				"get":  map[string]interface{}{"operationId": "listTasks", "deprecated": true},
+				"post": map[string]interface{}{"operationId": "createTask"},
+			},
+			"/tasks/{id}": map[string]interface{}{
+				"get":         map[string]interface{}{"operationId": "getTask"},
+				"x-internal":  true,
+				"parameters":  []interface{}{map[string]interface{}{"name": "id", "in": "path"}},
+				"description": "One task",
+			},
+		},
+	}
+	tests := []struct {
+		path string
+		want []string // Pointers of the selected nodes
+	}{
+		{path: "$", want: []string{""}},
+		{path: "$.paths['/tasks'].get", want: []string{"#/paths/~1tasks/get"}},
+		{path: "$.paths['/tasks'][get,post]", want: []string{"#/paths/~1tasks/get", "#/paths/~1tasks/post"}},
+		{path: "$.paths['/tasks/{id}'].parameters[0].name", want: []string{"#/paths/~1tasks~1{id}/parameters/0/name"}},
+		{path: "$.paths[*].get", want: []string{"#/paths/~1tasks/get", "#/paths/~1tasks~1{id}/get"}},
+		{path: "$.paths[?(!@property.match(/\\}$/))].*", want: []string{"#/paths/~1tasks/get", "#/paths/~1tasks/post"}},
+		{path: "$.paths.*[?(@property.match(/^x-/))]", want: []string{"#/paths/~1tasks~1{id}/x-internal"}},
+		{path: "$.paths.*[?(@.deprecated)]", want: []string{"#/paths/~1tasks/get"}},
+		{path: "$.paths.*[?(@.operationId == 'createTask')]", want: []string{"#/paths/~1tasks/post"}},
+		{path: "$..operationId", want: []string{"#/paths/~1tasks/get/operationId", "#/paths/~1tasks/post/operationId", "#/paths/~1tasks~1{id}/get/operationId"}},
+		{path: "$.components.schemas"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := parseRulePath(tt.path)
+			if err != nil {
+				t.Fatalf("parseRulePath: %v", err)
+			}
+			var got []string
+			for _, node := range path.evaluate(root, ruleNode{value: root, present: true}) {
+				got = append(got, rulePointer(node.tokens))
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRulePathErrors(t *testing.T) {
+	for _, path := range []string{"paths", "$.paths[", "$.paths[?(@.a.b.c ~ 1)]", "$.paths['/tasks'"} {
+		if _, err := parseRulePath(path); err == nil {
+			t.Errorf("parseRulePath(%q) succeeded, want an error", path)
+		}
+	}
+}
+
+func TestRuleFunctions(t *testing.T) {
+	present := func(value interface{}) ruleNode { return ruleNode{value: value, present: true} }
+	missing := ruleNode{}
+	tests := []struct {
+		name     string
+		function string
+		options  map[string]interface{}
+		node     ruleNode
+		fails    bool
+	}{
+		{name: "truthy set", function: "truthy", node: present("team-a")},
+		{name: "truthy empty", function: "truthy", node: present(""), fails: true},
+		{name: "truthy missing", function: "truthy", node: missing, fails: true},
+		{name: "falsy false", function: "falsy", node: present(false)},
+		{name: "falsy set", function: "falsy", node: present(true), fails: true},
+		{name: "defined empty", function: "defined", node: present("")},
+		{name: "defined missing", function: "defined", node: missing, fails: true},
+		{name: "undefined missing", function: "undefined", node: missing},
+		{name: "undefined set", function: "undefined", node: present(nil), fails: true},
+		{name: "pattern match", function: "pattern", options: map[string]interface{}{"match": "^/[a-z-]+$"}, node: present("/task-lists")},
+		{name: "pattern no match", function: "pattern", options: map[string]interface{}{"match": "^/[a-z-]+$"}, node: present("/taskLists"), fails: true},
+		{name: "pattern case-insensitive", function: "pattern", options: map[string]interface{}{"match": "/^task/i"}, node: present("TASKS")},
+		{name: "pattern not match", function: "pattern", options: map[string]interface{}{"notMatch": "_"}, node: present("list_tasks"), fails: true},
+		{name: "pattern missing field", function: "pattern", options: map[string]interface{}{"match": "x"}, node: missing},
+		{name: "casing camel", function: "casing", options: map[string]interface{}{"type": "camel"}, node: present("listTasks")},
+		{name: "casing not camel", function: "casing", options: map[string]interface{}{"type": "camel"}, node: present("ListTasks"), fails: true},
+		{name: "casing kebab", function: "casing", options: map[string]interface{}{"type": "kebab"}, node: present("task-lists")},
+		{name: "casing not macro", function: "casing", options: map[string]interface{}{"type": "macro"}, node: present("TASK-LIST"), fails: true},
+		{name: "enumeration allowed", function: "enumeration", options: map[string]interface{}{"values": []interface{}{"array", "object"}}, node: present("array")},
+		{name: "enumeration not allowed", function: "enumeration", options: map[string]interface{}{"values": []interface{}{"array"}}, node: present("string"), fails: true},
+		{name: "length within", function: "length", options: map[string]interface{}{"min": 2, "max": 4}, node: present("abc")},
+		{name: "length too short", function: "length", options: map[string]interface{}{"min": 2}, node: present([]interface{}{"a"}), fails: true},
+		{name: "length too long", function: "length", options: map[string]interface{}{"max": 1}, node: present(map[string]interface{}{"a": 1, "b": 2}), fails: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			function, err := compileRuleFunction(tt.function, tt.options)
+			if err != nil {
+				t.Fatalf("compileRuleFunction: %v", err)
+			}
+			if problem := function(tt.node, "field"); (problem != "") != tt.fails {
+				t.Errorf("problem is %q, want failure %v", problem, tt.fails)
+			}
+		})
+	}
+}
+
+func TestLoadRuleFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string // Part of the expected error
+	}{
+		{name: "no principles", content: "principles: []", want: "defines no principles"},
+		{name: "unknown key", content: "principles:\n  - id: X1\n    owner: me", want: "owner"},
+		{
+			name:    "no rules",
+			content: "principles:\n  - id: X1\n    name: Empty",
+			want:    "invalid principle X1",
+		},
+		{
+			name:    "unknown function",
+			content: "principles:\n  - id: X1\n    rules:\n      - {check: c, given: $.paths, then: {function: exists}}",
+			want:    `unknown function "exists"`,
+		},
+		{
+			name:    "unknown option",
+			content: "principles:\n  - id: X1\n    rules:\n      - {check: c, given: $.paths, then: {function: casing, functionOptions: {style: camel}}}",
+			want:    `unknown option "style"`,
+		},
+		{
+			name:    "relative given",
+			content: "principles:\n  - id: X1\n    rules:\n      - {check: c, given: paths, then: {function: truthy}}",
+			want:    "must start with $",
+		},
+		{
+			name:    "missing check",
+			content: "principles:\n  - id: X1\n    rules:\n      - {given: $.paths, then: {function: truthy}}",
+			want:    "check is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadRuleFile(writeRuleFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error is %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	definitions, err := LoadRuleFile(writeRuleFile(t, `
+principles:
+  - id: ACME001
+    name: Naming Conventions
+    severity: warning
+    minimal: true
+    rules:
+      - check: Paths are kebab-case
+        given: $.paths
+        then:
+          field: "@key"
+          function: pattern
+          functionOptions:
+            match: "^(/([a-z0-9-]+|\\{[a-zA-Z]+\\}))+$"
+      - check: Operation IDs are camelCase
+        given: "$.paths[*][get,post]"
+        then:
+          field: operationId
+          function: casing
+          functionOptions: {type: camel}
+      - check: Operations name their owning team
+        given: "$.paths[*][get,post]"
+        message: "{{path}} has no x-owner"
+        then:
+          field: x-owner
+          function: truthy
+      - check: Schemas have a type
+        given: "$.paths[*].get.responses['200'].content['application/json'].schema"
+        then:
+          field: type
+          function: defined
+`))
+	if err != nil {
+		t.Fatalf("LoadRuleFile: %v", err)
+	}
+	if len(definitions) != 1 || !definitions[0].Minimal || definitions[0].Principle.Category != "Custom" {
+		t.Fatalf("definitions are %+v, want one minimal principle in the Custom category", definitions)
+	}
+	definition := definitions[0]
+	if want := 4; len(definition.Principle.Checks) != want {
+		t.Errorf("principle has checks %v, want %d", definition.Principle.Checks, want)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(`
+openapi: 3.0.3
+info: {title: Tasks, version: 1.0.0}
+paths:
+  /tasks:
+    get:
+      operationId: listTasks
+      x-owner: team-a
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/TaskList'}
+  /taskLists/{id}:
+    get:
+      operationId: GetTaskList
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema: {type: object}
+components:
+  schemas:
+    TaskList:
+      properties:
+        items: {type: array, items: {type: string}}
+`))
+	if err != nil {
+		t.Fatalf("LoadFromData: %v", err)
+	}
+
+	result := definition.Validate(context.Background(), doc)
+	if result.Passed {
+		t.Fatalf("principle passed, want findings")
+	}
+	want := []Finding{
+		{Check: "Paths are kebab-case", Pointer: "#/paths/~1taskLists~1{id}", Message: `paths: "/taskLists/{id}" does not match`},
+		{Check: "Operation IDs are camelCase", Pointer: "#/paths/~1taskLists~1{id}/get/operationId", Message: `GET /taskLists/{id} operationId: "GetTaskList" is not camel case`},
+		{Check: "Operations name their owning team", Pointer: "#/paths/~1taskLists~1{id}/get", Message: "GET /taskLists/{id} has no x-owner"},
+		// The referenced schema is reported where it is defined
+		{Check: "Schemas have a type", Pointer: "#/components/schemas/TaskList", Message: `components.schemas.TaskList: "type" is missing`},
+	}
+	if len(result.Findings) != len(want) {
+		t.Fatalf("findings are %+v, want %d", result.Findings, len(want))
+	}
+	for i, finding := range result.Findings {
+		if finding.Check != want[i].Check || finding.Pointer != want[i].Pointer || !strings.HasPrefix(finding.Message, want[i].Message) {
+			t.Errorf("finding %d is %+v, want %+v", i, finding, want[i])
+		}
+	}
+	if !strings.HasPrefix(result.Message, "4 of 4 checks failed") {
+		t.Errorf("message is %q, want every check to fail", result.Message)
+	}
+}
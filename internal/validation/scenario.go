@@ -0,0 +1,501 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// scenarioStep is a single operation executed as part of a CRUD scenario
+type scenarioStep struct {
+	Name      string
+	Method    string
+	Path      string
+	PathItem  *openapi3.PathItem
+	Operation *openapi3.Operation
+	// Params maps path parameter names to OpenAPI runtime expressions taken from links.
+	// Parameters without an expression are inferred from the producer's response.
+	Params map[string]interface{}
+}
+
+// crudScenario chains an operation that creates a resource with the operations that
+// read, update and delete it
+type crudScenario struct {
+	Resource string
+	Create   scenarioStep
+	Steps    []scenarioStep
+}
+
+// scenarioState holds the exchange of the producer step, used to resolve runtime expressions
+type scenarioState struct {
+	request  *RequestInput
+	response *EndpointValidation
+	body     interface{}
+}
+
+// consumerOrder defines the order in which consumer operations run within a scenario
+var consumerOrder = map[string]int{
+	http.MethodGet:    0,
+	http.MethodPut:    1,
+	http.MethodPatch:  2,
+	http.MethodDelete: 3,
+}
+
+// discoverScenarios builds CRUD scenarios for every POST operation that has consumers.
+// Consumers are taken from OpenAPI links on the POST's 2xx responses when present, and
+// otherwise inferred from item paths of the form <collection>/{param}.
+func discoverScenarios(doc *openapi3.T) []crudScenario {
+	var scenarios []crudScenario
+
+	paths := doc.Paths.InMatchingOrder()
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := doc.Paths.Value(path)
+		if pathItem == nil || pathItem.Post == nil || pathItem.Post.Deprecated {
+			continue
+		}
+
+		scenario := crudScenario{
+			Resource: path,
+			Create: scenarioStep{
+				Name:      "create",
+				Method:    http.MethodPost,
+				Path:      path,
+				PathItem:  pathItem,
+				Operation: pathItem.Post,
+			},
+		}
+
+		linked := make(map[string]bool)
+		for _, step := range linkedSteps(doc, pathItem.Post) {
+			linked[step.Method+" "+step.Path] = true
+			scenario.Steps = append(scenario.Steps, step)
+		}
+		for _, step := range inferredSteps(doc, path) {
+			if !linked[step.Method+" "+step.Path] {
+				scenario.Steps = append(scenario.Steps, step)
+			}
+		}
+		if len(scenario.Steps) == 0 {
+			continue
+		}
+
+		sort.SliceStable(scenario.Steps, func(i, j int) bool {
+			return consumerOrder[scenario.Steps[i].Method] < consumerOrder[scenario.Steps[j].Method]
+		})
+		scenarios = append(scenarios, scenario)
+	}
+
+	return scenarios
+}
+
+// linkedSteps resolves the OpenAPI links declared on an operation's 2xx responses
+func linkedSteps(doc *openapi3.T, producer *openapi3.Operation) []scenarioStep {
+	var steps []scenarioStep
+	if producer.Responses == nil {
+		return steps
+	}
+	for code, responseRef := range producer.Responses.Map() {
+		if !strings.HasPrefix(code, "2") || responseRef.Value == nil {
+			continue
+		}
+		names := make([]string, 0, len(responseRef.Value.Links))
+		for name := range responseRef.Value.Links {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			linkRef := responseRef.Value.Links[name]
+			if linkRef == nil || linkRef.Value == nil {
+				continue
+			}
+			method, path, pathItem, operation := resolveLinkTarget(doc, linkRef.Value)
+			if operation == nil || operation.Deprecated {
+				continue
+			}
+			if _, ok := consumerOrder[method]; !ok {
+				continue
+			}
+			steps = append(steps, scenarioStep{
+				Name:      stepName(method),
+				Method:    method,
+				Path:      path,
+				PathItem:  pathItem,
+				Operation: operation,
+				Params:    linkRef.Value.Parameters,
+			})
+		}
+	}
+	return steps
+}
+
+// resolveLinkTarget finds the operation a link points at, by operationId or operationRef
+func resolveLinkTarget(doc *openapi3.T, link *openapi3.Link) (string, string, *openapi3.PathItem, *openapi3.Operation) {
+	if link.OperationID != "" {
+		for path, pathItem := range doc.Paths.Map() {
+			for method, operation := range pathItem.Operations() {
+				if operation.OperationID == link.OperationID {
+					return method, path, pathItem, operation
+				}
+			}
+		}
+		return "", "", nil, nil
+	}
+
+	// Local operationRefs look like #/paths/~1tasks~1{id}/get
+	ref := strings.TrimPrefix(link.OperationRef, "#/paths/")
+	if ref == link.OperationRef {
+		return "", "", nil, nil
+	}
+	idx := strings.LastIndex(ref, "/")
+	if idx < 0 {
+		return "", "", nil, nil
+	}
+	path := strings.NewReplacer("~1", "/", "~0", "~").Replace(ref[:idx])
+	method := strings.ToUpper(ref[idx+1:])
+	pathItem := doc.Paths.Value(path)
+	if pathItem == nil {
+		return "", "", nil, nil
+	}
+	return method, path, pathItem, pathItem.GetOperation(method)
+}
+
+// inferredSteps returns the operations on item paths directly below a collection path
+func inferredSteps(doc *openapi3.T, collection string) []scenarioStep {
+	var steps []scenarioStep
+	prefix := strings.TrimRight(collection, "/") + "/{"
+	for path, pathItem := range doc.Paths.Map() {
+		if !strings.HasPrefix(path, prefix) || !strings.HasSuffix(path, "}") {
+			continue
+		}
+		// Only a single trailing parameter segment, e.g. /tasks/{id}
+		if strings.Contains(path[len(prefix):], "/") {
+			continue
+		}
+		for method, operation := range pathItem.Operations() {
+			if _, ok := consumerOrder[method]; !ok || operation.Deprecated {
+				continue
+			}
+			steps = append(steps, scenarioStep{
+				Name:      stepName(method),
+				Method:    method,
+				Path:      path,
+				PathItem:  pathItem,
+				Operation: operation,
+			})
+		}
+	}
+	return steps
+}
+
+func stepName(method string) string {
+	switch method {
+	case http.MethodGet:
+		return "read"
+	case http.MethodPut:
+		return "replace"
+	case http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return strings.ToLower(method)
+}
+
+// runScenarios executes all CRUD scenarios and returns their test cases keyed by "METHOD path"
+func (t *FunctionalTester) runScenarios(ctx context.Context, doc *openapi3.T) map[string][]TestCaseResult {
+	results := make(map[string][]TestCaseResult)
+	for _, scenario := range discoverScenarios(doc) {
+		t.runScenario(ctx, scenario, results)
+	}
+	return results
+}
+
+// runScenario creates a resource, runs the consumer steps against it and cleans it up
+func (t *FunctionalTester) runScenario(ctx context.Context, scenario crudScenario, results map[string][]TestCaseResult) {
+	record := func(step scenarioStep, tc TestCaseResult) {
+		tc.Name = fmt.Sprintf("crud %s: %s", scenario.Resource, tc.Name)
+		key := step.Method + " " + step.Path
+		// Scenario requests are generated like endpoint requests and share their impact
+		if reason, ok := t.impact.downgradeReason(key, TestKindEndpoint); ok && tc.Status == TestStatusFailed {
+			tc.Status = TestStatusWarning
+			tc.Error = fmt.Sprintf("%s (reported as a warning: %s)", tc.Error, reason)
+		}
+		results[key] = append(results[key], tc)
+	}
+
+	createInput := synthesizeRequest(scenario.Create.PathItem, scenario.Create.Operation)
+	created := t.executeOperation(ctx, scenario.Create.Method, scenario.Create.Path, scenario.Create.Operation, createInput)
+	createCase := scenarioTestCase(scenario.Create, createInput, created)
+	record(scenario.Create, createCase)
+
+	if createCase.Status == TestStatusFailed {
+		for _, step := range scenario.Steps {
+			record(step, TestCaseResult{
+				Name:        step.Name,
+				Status:      TestStatusSkipped,
+				Description: fmt.Sprintf("Skipped because creating the resource via POST %s failed", scenario.Create.Path),
+			})
+		}
+		return
+	}
+
+	state := &scenarioState{request: createInput, response: &created}
+	if len(created.ResponseBody) > 0 {
+		_ = json.Unmarshal(created.ResponseBody, &state.body)
+	}
+
+	deleted := false
+	var readStep, deleteStep *scenarioStep
+	for i := range scenario.Steps {
+		step := scenario.Steps[i]
+		if step.Method == http.MethodGet && readStep == nil {
+			readStep = &scenario.Steps[i]
+		}
+		if step.Method == http.MethodDelete {
+			deleteStep = &scenario.Steps[i]
+		}
+
+		input, err := t.scenarioInput(step, state)
+		if err != nil {
+			record(step, TestCaseResult{
+				Name:        step.Name,
+				Status:      TestStatusSkipped,
+				Description: err.Error(),
+			})
+			continue
+		}
+
+		validation := t.executeOperation(ctx, step.Method, step.Path, step.Operation, input)
+		tc := scenarioTestCase(step, input, validation)
+		record(step, tc)
+		if step.Method == http.MethodDelete && tc.Status != TestStatusFailed {
+			deleted = true
+		}
+	}
+
+	// After a successful delete the resource should no longer be readable
+	if deleted && readStep != nil {
+		if input, err := t.scenarioInput(*readStep, state); err == nil {
+			validation := t.executeOperation(ctx, readStep.Method, readStep.Path, readStep.Operation, input)
+			tc := TestCaseResult{
+				Name:        "read after delete",
+				Description: fmt.Sprintf("GET %s after DELETE should return 404", readStep.Path),
+				Input:       input,
+				Expected:    http.StatusNotFound,
+				Actual:      validation.StatusCode,
+				Status:      TestStatusPassed,
+			}
+			if validation.StatusCode != http.StatusNotFound {
+				tc.Status = TestStatusWarning
+				tc.Error = fmt.Sprintf("resource still readable after delete (status %d)", validation.StatusCode)
+			}
+			record(*readStep, tc)
+		}
+	}
+
+	// Clean up the resource if the scenario did not delete it
+	if !deleted && deleteStep != nil {
+		if input, err := t.scenarioInput(*deleteStep, state); err == nil {
+			validation := t.executeOperation(ctx, deleteStep.Method, deleteStep.Path, deleteStep.Operation, input)
+			tc := scenarioTestCase(*deleteStep, input, validation)
+			tc.Name = "cleanup"
+			if tc.Status == TestStatusFailed {
+				tc.Status = TestStatusWarning
+			}
+			record(*deleteStep, tc)
+		}
+	}
+}
+
+// scenarioInput synthesizes the request for a consumer step and fills its path
+// parameters from the producer's exchange. Parameters the producer request already had,
+// such as the parent of a nested collection, keep their value; only the others are
+// inferred from the created resource.
+func (t *FunctionalTester) scenarioInput(step scenarioStep, state *scenarioState) (*RequestInput, error) {
+	input := synthesizeRequest(step.PathItem, step.Operation)
+	for _, ref := range operationParameters(step.PathItem, step.Operation) {
+		param := ref.Value
+		if param.In != openapi3.ParameterInPath {
+			continue
+		}
+		var value interface{}
+		if expr, ok := step.Params[param.Name]; ok {
+			value = evaluateRuntimeExpression(expr, state)
+		} else if producerValue, ok := state.request.PathParams[param.Name]; ok {
+			value = producerValue
+		} else {
+			value = inferResourceID(param.Name, state)
+		}
+		if value == nil {
+			return nil, fmt.Errorf("could not determine path parameter %q from the created resource", param.Name)
+		}
+		input.PathParams[param.Name] = value
+	}
+	return input, nil
+}
+
+// scenarioTestCase converts the outcome of a scenario step into a test case
+func scenarioTestCase(step scenarioStep, input *RequestInput, validation EndpointValidation) TestCaseResult {
+	tc := TestCaseResult{
+		Name:        step.Name,
+		Description: fmt.Sprintf("%s %s", step.Method, step.Path),
+		Input:       input,
+		Expected:    documentedSuccessCodes(step.Operation),
+		Actual: map[string]interface{}{
+			"status_code": validation.StatusCode,
+			"status":      validation.Status,
+		},
+	}
+
+	switch {
+	case validation.StatusCode < 200 || validation.StatusCode >= 300:
+		tc.Status = TestStatusFailed
+		tc.Error = fmt.Sprintf("expected a 2xx response, got %d", validation.StatusCode)
+		if len(validation.Errors) > 0 {
+			tc.Error += ": " + strings.Join(validation.Errors, "; ")
+		}
+	case validation.Status == "error":
+		tc.Status = TestStatusFailed
+		tc.Error = strings.Join(validation.Errors, "; ")
+	case validation.Status == "warning":
+		tc.Status = TestStatusWarning
+		tc.Error = strings.Join(validation.Errors, "; ")
+	default:
+		tc.Status = TestStatusPassed
+	}
+	return tc
+}
+
+// documentedSuccessCodes lists the 2xx status codes documented for an operation
+func documentedSuccessCodes(operation *openapi3.Operation) []string {
+	var codes []string
+	if operation.Responses == nil {
+		return codes
+	}
+	for code := range operation.Responses.Map() {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// evaluateRuntimeExpression resolves an OpenAPI link runtime expression against the producer's exchange.
+// Non-expression values are returned unchanged.
+func evaluateRuntimeExpression(expr interface{}, state *scenarioState) interface{} {
+	s, ok := expr.(string)
+	if !ok || !strings.HasPrefix(s, "$") {
+		return expr
+	}
+
+	switch {
+	case s == "$statusCode":
+		return state.response.StatusCode
+	case s == "$response.body":
+		return state.body
+	case strings.HasPrefix(s, "$response.body#"):
+		return resolvePointer(state.body, strings.TrimPrefix(s, "$response.body#"))
+	case strings.HasPrefix(s, "$response.header."):
+		return nilIfEmpty(state.response.ResponseHeaders.Get(strings.TrimPrefix(s, "$response.header.")))
+	case strings.HasPrefix(s, "$request.path."):
+		return state.request.PathParams[strings.TrimPrefix(s, "$request.path.")]
+	case strings.HasPrefix(s, "$request.query."):
+		return state.request.QueryParams[strings.TrimPrefix(s, "$request.query.")]
+	case strings.HasPrefix(s, "$request.header."):
+		return state.request.Headers[strings.TrimPrefix(s, "$request.header.")]
+	case s == "$request.body":
+		return state.request.Body
+	case strings.HasPrefix(s, "$request.body#"):
+		return resolvePointer(state.request.Body, strings.TrimPrefix(s, "$request.body#"))
+	}
+	return nil
+}
+
+// inferResourceID guesses the identifier of a created resource for a path parameter,
+// looking at the response body first and falling back to the Location header
+func inferResourceID(paramName string, state *scenarioState) interface{} {
+	candidates := []string{paramName, "id", "ID", "Id", "_id", "uuid"}
+
+	objects := []map[string]interface{}{}
+	if obj, ok := state.body.(map[string]interface{}); ok {
+		objects = append(objects, obj)
+		for _, wrapper := range []string{"data", "result", "item"} {
+			if nested, ok := obj[wrapper].(map[string]interface{}); ok {
+				objects = append(objects, nested)
+			}
+		}
+	}
+
+	for _, obj := range objects {
+		for _, key := range candidates {
+			if value, ok := obj[key]; ok && value != nil {
+				return value
+			}
+		}
+	}
+	for _, obj := range objects {
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			lower := strings.ToLower(key)
+			if strings.HasSuffix(lower, "id") && obj[key] != nil {
+				switch obj[key].(type) {
+				case string, float64:
+					return obj[key]
+				}
+			}
+		}
+	}
+
+	if state.response.ResponseHeaders != nil {
+		if location := state.response.ResponseHeaders.Get("Location"); location != "" {
+			location = strings.TrimRight(location, "/")
+			if idx := strings.LastIndex(location, "/"); idx >= 0 && idx < len(location)-1 {
+				return location[idx+1:]
+			}
+		}
+	}
+	return nil
+}
+
+// resolvePointer looks up an RFC 6901 JSON pointer in a decoded JSON value
+func resolvePointer(value interface{}, pointer string) interface{} {
+	if pointer == "" || pointer == "/" {
+		return value
+	}
+	unescaper := strings.NewReplacer("~1", "/", "~0", "~")
+	current := value
+	for _, segment := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		segment = unescaper.Replace(segment)
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current = node[segment]
+		case []interface{}:
+			var idx int
+			if _, err := fmt.Sscanf(segment, "%d", &idx); err != nil || idx < 0 || idx >= len(node) {
+				return nil
+			}
+			current = node[idx]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+func nilIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const nestedResourceSpec = `
+openapi: 3.0.3
+info: {title: Projects, version: 1.0.0}
+paths:
+  /projects/{pid}/tasks:
+    parameters:
+      - {name: pid, in: path, required: true, schema: {type: string, example: p1}}
+    post:
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema: {type: object, properties: {id: {type: string}}}
+  /projects/{pid}/tasks/{id}:
+    parameters:
+      - {name: pid, in: path, required: true, schema: {type: string, example: p1}}
+      - {name: id, in: path, required: true, schema: {type: string}}
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema: {type: object, properties: {id: {type: string}}}
+        '404': {description: Not found}
+    delete:
+      responses:
+        '204': {description: Deleted}
+`
+
+func TestRunScenariosNestedResource(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(nestedResourceSpec))
+	if err != nil {
+		t.Fatalf("LoadFromData: %v", err)
+	}
+
+	var mu sync.Mutex
+	var requests []string
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/p1/tasks":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"t9"}`))
+		case r.URL.Path != "/projects/p1/tasks/t9" || deleted:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"id":"t9"}`))
+		case r.Method == http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	tester := NewFunctionalTester(ValidatorConfig{BaseURL: server.URL})
+	results := tester.runScenarios(context.Background(), doc)
+
+	want := []string{
+		"POST /projects/p1/tasks",
+		"GET /projects/p1/tasks/t9",
+		"DELETE /projects/p1/tasks/t9",
+		"GET /projects/p1/tasks/t9",
+	}
+	if !slices.Equal(requests, want) {
+		t.Errorf("requests are %v, want %v", requests, want)
+	}
+	for key, cases := range results {
+		for _, tc := range cases {
+			if tc.Status != TestStatusPassed {
+				t.Errorf("%s %q is %s: %s", key, tc.Name, tc.Status, tc.Error)
+			}
+		}
+	}
+}
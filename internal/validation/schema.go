@@ -0,0 +1,252 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/meter-peter/driveby/internal/openapi"
+)
+
+// SchemaMismatch describes a single location where a response body diverges from its documented schema
+type SchemaMismatch struct {
+	Pointer  string      `json:"pointer"`
+	Expected string      `json:"expected"`
+	Actual   interface{} `json:"actual"`
+	Reason   string      `json:"reason,omitempty"`
+}
+
+// String formats the mismatch for inclusion in endpoint error lists
+func (m SchemaMismatch) String() string {
+	pointer := m.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: expected %s, got %v", pointer, m.Expected, formatActual(m.Actual))
+}
+
+// lookupResponseSchema finds the schema documented for a status code and content type.
+// The bool result reports whether a response body is documented at all. Without a content
+// type, the schema of the documented JSON media type is used.
+func lookupResponseSchema(operation *openapi3.Operation, statusCode int, contentType string) (*openapi3.Schema, bool, error) {
+	if operation.Responses == nil {
+		return nil, false, nil
+	}
+	responseRef := operation.Responses.Status(statusCode)
+	if responseRef == nil {
+		responseRef = operation.Responses.Default()
+	}
+	if responseRef == nil || responseRef.Value == nil || len(responseRef.Value.Content) == 0 {
+		return nil, false, nil
+	}
+
+	mediaType := responseRef.Value.Content.Get(contentType)
+	if mediaType == nil && contentType == "" {
+		_, mediaType = openapi.JSONMediaType(responseRef.Value.Content)
+	}
+	if mediaType == nil {
+		return nil, true, fmt.Errorf("content type %q is not documented for status %d", contentType, statusCode)
+	}
+	if mediaType.Schema == nil || mediaType.Schema.Value == nil {
+		return nil, true, nil
+	}
+	return mediaType.Schema.Value, true, nil
+}
+
+// validateResponseBody decodes a response body and validates it against the schema documented
+// for its status code and content type. Non-JSON bodies are not inspected; bodies sent without
+// a content type are validated against the documented JSON schema.
+func validateResponseBody(operation *openapi3.Operation, statusCode int, contentType string, body []byte) ([]SchemaMismatch, error) {
+	schema, documented, err := lookupResponseSchema(operation, statusCode, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if !documented || schema == nil {
+		return nil, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "" && !strings.Contains(mediaType, "json") {
+		return nil, nil
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return []SchemaMismatch{{
+			Pointer:  "",
+			Expected: describeSchemaType(schema),
+			Actual:   "<empty body>",
+			Reason:   "response body is empty but a schema is documented",
+		}}, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode response body as JSON: %w", err)
+	}
+
+	err = schema.VisitJSON(value,
+		openapi3.MultiErrors(),
+		openapi3.VisitAsResponse(),
+		openapi3.EnableFormatValidation(),
+	)
+	if err == nil {
+		return nil, nil
+	}
+
+	var mismatches []SchemaMismatch
+	collectSchemaMismatches(err, &mismatches)
+	return mismatches, nil
+}
+
+// collectSchemaMismatches flattens kin-openapi validation errors into SchemaMismatch entries.
+// allOf errors wrap the errors of their subschemas; those are reported individually with the
+// pointer of the enclosing value as prefix. oneOf/anyOf failures are reported as a whole.
+func collectSchemaMismatches(err error, mismatches *[]SchemaMismatch) {
+	collectSchemaMismatchesAt("", err, mismatches)
+}
+
+func collectSchemaMismatchesAt(prefix string, err error, mismatches *[]SchemaMismatch) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			collectSchemaMismatchesAt(prefix, inner, mismatches)
+		}
+		return
+	case *openapi3.SchemaError:
+		pointer := prefix + toJSONPointer(e.JSONPointer())
+		if e.Origin != nil && e.SchemaField != "oneOf" && e.SchemaField != "anyOf" {
+			before := len(*mismatches)
+			collectSchemaMismatchesAt(pointer, e.Origin, mismatches)
+			if len(*mismatches) > before {
+				return
+			}
+		}
+		mismatch := SchemaMismatch{
+			Pointer:  pointer,
+			Expected: describeSchemaConstraint(e),
+			Actual:   e.Value,
+			Reason:   e.Reason,
+		}
+		if e.SchemaField == "required" {
+			mismatch.Actual = "<missing>"
+		}
+		*mismatches = append(*mismatches, mismatch)
+		return
+	}
+
+	var multiErr openapi3.MultiError
+	if errors.As(err, &multiErr) {
+		collectSchemaMismatchesAt(prefix, multiErr, mismatches)
+		return
+	}
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		collectSchemaMismatchesAt(prefix, schemaErr, mismatches)
+		return
+	}
+	if prefix != "" {
+		// Non-schema origins (e.g. format callbacks) are described by the enclosing error
+		return
+	}
+	*mismatches = append(*mismatches, SchemaMismatch{Reason: err.Error()})
+}
+
+// toJSONPointer renders path segments as an RFC 6901 JSON pointer
+func toJSONPointer(segments []string) string {
+	if len(segments) == 0 {
+		return ""
+	}
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	var b strings.Builder
+	for _, segment := range segments {
+		b.WriteString("/")
+		b.WriteString(escaper.Replace(segment))
+	}
+	return b.String()
+}
+
+// describeSchemaConstraint renders the constraint that a value failed to satisfy
+func describeSchemaConstraint(err *openapi3.SchemaError) string {
+	schema := err.Schema
+	if schema == nil {
+		return err.SchemaField
+	}
+
+	switch err.SchemaField {
+	case "type":
+		return describeSchemaType(schema)
+	case "nullable":
+		return fmt.Sprintf("non-null %s", describeSchemaType(schema))
+	case "required":
+		return "required property"
+	case "enum":
+		return fmt.Sprintf("one of %v", schema.Enum)
+	case "format":
+		return fmt.Sprintf("string with format %q", schema.Format)
+	case "pattern":
+		return fmt.Sprintf("string matching %q", schema.Pattern)
+	case "minLength":
+		return fmt.Sprintf("string of at least %d characters", schema.MinLength)
+	case "maxLength":
+		if schema.MaxLength != nil {
+			return fmt.Sprintf("string of at most %d characters", *schema.MaxLength)
+		}
+	case "minimum":
+		if schema.Min != nil {
+			return fmt.Sprintf("number >= %v", *schema.Min)
+		}
+	case "maximum":
+		if schema.Max != nil {
+			return fmt.Sprintf("number <= %v", *schema.Max)
+		}
+	case "minItems":
+		return fmt.Sprintf("array of at least %d items", schema.MinItems)
+	case "maxItems":
+		if schema.MaxItems != nil {
+			return fmt.Sprintf("array of at most %d items", *schema.MaxItems)
+		}
+	case "oneOf":
+		return fmt.Sprintf("value matching exactly one of %d schemas", len(schema.OneOf))
+	case "anyOf":
+		return fmt.Sprintf("value matching any of %d schemas", len(schema.AnyOf))
+	case "allOf":
+		return fmt.Sprintf("value matching all of %d schemas", len(schema.AllOf))
+	case "additionalProperties":
+		return "no additional properties"
+	}
+	return fmt.Sprintf("%s constraint satisfied", err.SchemaField)
+}
+
+// describeSchemaType renders the documented type of a schema, including format and nullability
+func describeSchemaType(schema *openapi3.Schema) string {
+	typ := schema.Type
+	if typ == "" {
+		typ = "any"
+	}
+	if schema.Format != "" {
+		typ = fmt.Sprintf("%s (%s)", typ, schema.Format)
+	}
+	if schema.Nullable {
+		typ += " or null"
+	}
+	return typ
+}
+
+// formatActual summarises an actual value without dumping whole objects into reports
+func formatActual(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return fmt.Sprintf("array of %d items", len(v))
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestValidateResponseBody(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(`
+openapi: 3.0.3
+info: {title: Tasks, version: 1.0.0}
+paths:
+  /tasks/{id}:
+    get:
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: string}
+        '204':
+          description: No content
+`))
+	if err != nil {
+		t.Fatalf("LoadFromData: %v", err)
+	}
+	operation := doc.Paths.Find("/tasks/{id}").Get
+	tests := []struct {
+		name        string
+		statusCode  int
+		contentType string
+		body        string
+		mismatches  []string // Pointers of the expected mismatches
+		wantErr     bool
+	}{
+		{name: "valid", statusCode: 200, contentType: "application/json; charset=utf-8", body: `{"id": "t1"}`},
+		{name: "mismatch", statusCode: 200, contentType: "application/json", body: `{"id": 1}`, mismatches: []string{"/id"}},
+		{name: "empty body", statusCode: 200, contentType: "application/json", mismatches: []string{""}},
+		{name: "no content type is validated as JSON", statusCode: 200, body: `{}`, mismatches: []string{"/id"}},
+		{name: "no content type and not JSON", statusCode: 200, body: "ok", wantErr: true},
+		{name: "undocumented content type", statusCode: 200, contentType: "application/xml", body: "<task/>", wantErr: true},
+		{name: "no body documented", statusCode: 204, contentType: "text/plain", body: "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatches, err := validateResponseBody(operation, tt.statusCode, tt.contentType, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateResponseBody() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(mismatches) != len(tt.mismatches) {
+				t.Fatalf("mismatches are %v, want %d", mismatches, len(tt.mismatches))
+			}
+			for i, mismatch := range mismatches {
+				if mismatch.Pointer != tt.mismatches[i] {
+					t.Errorf("mismatch %d is at %q, want %q", i, mismatch.Pointer, tt.mismatches[i])
+				}
+			}
+		})
+	}
+}
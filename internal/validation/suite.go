@@ -0,0 +1,628 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// TestSuite is a user-authored set of functional test cases, loaded from YAML or JSON
+type TestSuite struct {
+	Name      string                 `yaml:"name" json:"name"`
+	Variables map[string]interface{} `yaml:"variables" json:"variables"`
+	Fixtures  []SuiteFixture         `yaml:"fixtures" json:"fixtures"`
+	Cases     []SuiteCase            `yaml:"cases" json:"cases"`
+}
+
+// SuiteRequest describes a request against an operation referenced by operationId.
+// Values given here override those synthesized from the spec; string values may
+// reference variables as ${name} or environment variables as ${env:NAME}.
+type SuiteRequest struct {
+	OperationID string                 `yaml:"operationId" json:"operationId"`
+	PathParams  map[string]interface{} `yaml:"pathParams" json:"pathParams"`
+	Query       map[string]interface{} `yaml:"query" json:"query"`
+	Headers     map[string]interface{} `yaml:"headers" json:"headers"`
+	Body        interface{}            `yaml:"body" json:"body"`
+	ContentType string                 `yaml:"contentType" json:"contentType"`
+	// Capture stores response values in variables, keyed by variable name. Values are
+	// JSONPath expressions into the body ($.id) or header references (header.Location).
+	Capture map[string]string `yaml:"capture" json:"capture"`
+}
+
+// SuiteFixture is a request run before the suite's cases, typically to create data
+// they depend on. Its optional teardown runs after all cases.
+type SuiteFixture struct {
+	Name         string `yaml:"name" json:"name"`
+	SuiteRequest `yaml:",inline"`
+	Expect       *SuiteExpectation `yaml:"expect" json:"expect"`
+	Teardown     *SuiteRequest     `yaml:"teardown" json:"teardown"`
+}
+
+// SuiteCase is a single user-authored test case
+type SuiteCase struct {
+	Name         string `yaml:"name" json:"name"`
+	Description  string `yaml:"description" json:"description"`
+	SuiteRequest `yaml:",inline"`
+	Expect       SuiteExpectation `yaml:"expect" json:"expect"`
+}
+
+// SuiteExpectation lists what a response must satisfy
+type SuiteExpectation struct {
+	Status   int              `yaml:"status,omitempty" json:"status,omitempty"`
+	Statuses []int            `yaml:"statuses,omitempty" json:"statuses,omitempty"`
+	Body     []SuiteAssertion `yaml:"body,omitempty" json:"body,omitempty"`
+	Headers  []SuiteAssertion `yaml:"headers,omitempty" json:"headers,omitempty"`
+	// SkipSchema disables validation of the response body against the documented schema
+	SkipSchema bool `yaml:"skipSchema,omitempty" json:"skipSchema,omitempty"`
+}
+
+// SuiteAssertion checks a single value in a response. Body assertions select the value
+// with a JSONPath expression in Path; header assertions name the header in Name.
+type SuiteAssertion struct {
+	Path      string      `yaml:"path,omitempty" json:"path,omitempty"`
+	Name      string      `yaml:"name,omitempty" json:"name,omitempty"`
+	Equals    interface{} `yaml:"equals,omitempty" json:"equals,omitempty"`
+	NotEquals interface{} `yaml:"notEquals,omitempty" json:"notEquals,omitempty"`
+	Contains  interface{} `yaml:"contains,omitempty" json:"contains,omitempty"`
+	Matches   string      `yaml:"matches,omitempty" json:"matches,omitempty"`
+	Exists    *bool       `yaml:"exists,omitempty" json:"exists,omitempty"`
+	Type      string      `yaml:"type,omitempty" json:"type,omitempty"`
+}
+
+// LoadTestSuite reads a test suite from a YAML or JSON file
+func LoadTestSuite(path string) (*TestSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test suite: %w", err)
+	}
+	var suite TestSuite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse test suite %s: %w", path, err)
+	}
+	if suite.Name == "" {
+		suite.Name = path
+	}
+	for i, fixture := range suite.Fixtures {
+		if fixture.OperationID == "" {
+			return nil, fmt.Errorf("fixture %d (%s) in %s has no operationId", i, fixture.Name, path)
+		}
+	}
+	for i, tc := range suite.Cases {
+		if tc.OperationID == "" {
+			return nil, fmt.Errorf("case %d (%s) in %s has no operationId", i, tc.Name, path)
+		}
+	}
+	return &suite, nil
+}
+
+// suiteOperation is an operation located by operationId
+type suiteOperation struct {
+	method    string
+	path      string
+	pathItem  *openapi3.PathItem
+	operation *openapi3.Operation
+}
+
+// indexOperations maps operationIds to their operations
+func indexOperations(doc *openapi3.T) map[string]suiteOperation {
+	index := make(map[string]suiteOperation)
+	for path, pathItem := range doc.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			if operation.OperationID != "" {
+				index[operation.OperationID] = suiteOperation{method: method, path: path, pathItem: pathItem, operation: operation}
+			}
+		}
+	}
+	return index
+}
+
+// suiteExchange is the result of a single suite request
+type suiteExchange struct {
+	op         suiteOperation
+	input      *RequestInput
+	validation EndpointValidation
+	body       interface{}
+}
+
+// runSuites executes the configured test suites and returns their test cases keyed by "METHOD path"
+func (t *FunctionalTester) runSuites(ctx context.Context, doc *openapi3.T) (map[string][]TestCaseResult, error) {
+	results := make(map[string][]TestCaseResult)
+	if len(t.config.SuitePaths) == 0 {
+		return results, nil
+	}
+
+	operations := indexOperations(doc)
+	for _, path := range t.config.SuitePaths {
+		suite, err := LoadTestSuite(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSuiteOperations(suite, operations); err != nil {
+			return nil, fmt.Errorf("invalid test suite %s: %w", path, err)
+		}
+		t.runSuite(ctx, suite, operations, results)
+	}
+	return results, nil
+}
+
+// checkSuiteOperations verifies that every operationId referenced by a suite exists in the spec
+func checkSuiteOperations(suite *TestSuite, operations map[string]suiteOperation) error {
+	var requests []SuiteRequest
+	for _, fixture := range suite.Fixtures {
+		requests = append(requests, fixture.SuiteRequest)
+		if fixture.Teardown != nil {
+			requests = append(requests, *fixture.Teardown)
+		}
+	}
+	for _, suiteCase := range suite.Cases {
+		requests = append(requests, suiteCase.SuiteRequest)
+	}
+	for _, request := range requests {
+		if _, ok := operations[request.OperationID]; !ok {
+			return fmt.Errorf("unknown operationId %q", request.OperationID)
+		}
+	}
+	return nil
+}
+
+// runSuite runs a suite's fixtures, cases and teardowns in order
+func (t *FunctionalTester) runSuite(ctx context.Context, suite *TestSuite, operations map[string]suiteOperation, results map[string][]TestCaseResult) {
+	vars := make(map[string]interface{}, len(suite.Variables))
+	for k, v := range suite.Variables {
+		vars[k] = v
+	}
+	record := func(op suiteOperation, tc TestCaseResult) {
+		tc.Name = fmt.Sprintf("suite %s: %s", suite.Name, tc.Name)
+		key := op.method + " " + op.path
+		results[key] = append(results[key], tc)
+	}
+
+	var teardowns []SuiteRequest
+	fixturesOK := true
+	for _, fixture := range suite.Fixtures {
+		op := operations[fixture.OperationID]
+		exchange, err := t.runSuiteRequest(ctx, op, fixture.SuiteRequest, vars)
+		expect := SuiteExpectation{}
+		if fixture.Expect != nil {
+			expect = *fixture.Expect
+		}
+		tc := evaluateSuiteCase("fixture "+fixture.Name, "", exchange, err, expect, vars, true)
+		record(op, tc)
+		if tc.Status == TestStatusFailed {
+			fixturesOK = false
+			continue
+		}
+		captureVariables(fixture.Capture, exchange, vars)
+		if fixture.Teardown != nil {
+			teardowns = append(teardowns, *fixture.Teardown)
+		}
+	}
+
+	for _, suiteCase := range suite.Cases {
+		op := operations[suiteCase.OperationID]
+		if !fixturesOK {
+			record(op, TestCaseResult{
+				Name:        suiteCase.Name,
+				Status:      TestStatusSkipped,
+				Description: "Skipped because a fixture failed",
+			})
+			continue
+		}
+		exchange, err := t.runSuiteRequest(ctx, op, suiteCase.SuiteRequest, vars)
+		tc := evaluateSuiteCase(suiteCase.Name, suiteCase.Description, exchange, err, suiteCase.Expect, vars, false)
+		record(op, tc)
+		if tc.Status != TestStatusFailed {
+			captureVariables(suiteCase.Capture, exchange, vars)
+		}
+	}
+
+	// Tear fixtures down in reverse order of creation
+	for i := len(teardowns) - 1; i >= 0; i-- {
+		teardown := teardowns[i]
+		op := operations[teardown.OperationID]
+		exchange, err := t.runSuiteRequest(ctx, op, teardown, vars)
+		tc := evaluateSuiteCase("teardown "+teardown.OperationID, "", exchange, err, SuiteExpectation{}, vars, true)
+		if tc.Status == TestStatusFailed {
+			tc.Status = TestStatusWarning
+		}
+		record(op, tc)
+	}
+}
+
+// runSuiteRequest builds and sends a suite request, starting from the values synthesized
+// from the spec and applying the suite's overrides
+func (t *FunctionalTester) runSuiteRequest(ctx context.Context, op suiteOperation, request SuiteRequest, vars map[string]interface{}) (*suiteExchange, error) {
+	input := synthesizeRequest(op.pathItem, op.operation)
+	for k, v := range request.PathParams {
+		input.PathParams[k] = substituteVariables(v, vars)
+	}
+	for k, v := range request.Query {
+		input.QueryParams[k] = substituteVariables(v, vars)
+	}
+	for k, v := range request.Headers {
+		input.Headers[k] = substituteVariables(v, vars)
+	}
+	if request.Body != nil {
+		input.Body = substituteVariables(request.Body, vars)
+	}
+	if request.ContentType != "" {
+		input.ContentType = request.ContentType
+	}
+
+	exchange := &suiteExchange{op: op, input: input}
+	exchange.validation = t.executeOperation(ctx, op.method, op.path, op.operation, input)
+	if exchange.validation.StatusCode == 0 {
+		return exchange, fmt.Errorf("%s", strings.Join(exchange.validation.Errors, "; "))
+	}
+	if len(exchange.validation.ResponseBody) > 0 {
+		_ = json.Unmarshal(exchange.validation.ResponseBody, &exchange.body)
+	}
+	return exchange, nil
+}
+
+// evaluateSuiteCase checks a suite exchange against its expectation. Without an explicit
+// status expectation, any 2xx response is accepted when requireSuccess is set and any
+// documented status otherwise.
+func evaluateSuiteCase(name, description string, exchange *suiteExchange, execErr error, expect SuiteExpectation, vars map[string]interface{}, requireSuccess bool) TestCaseResult {
+	tc := TestCaseResult{
+		Name:        name,
+		Description: description,
+		Expected:    expect,
+		Status:      TestStatusPassed,
+	}
+	if exchange != nil {
+		tc.Input = exchange.input
+		if tc.Description == "" {
+			tc.Description = fmt.Sprintf("%s %s", exchange.op.method, exchange.op.path)
+		}
+	}
+	if execErr != nil {
+		tc.Status = TestStatusFailed
+		tc.Error = execErr.Error()
+		return tc
+	}
+
+	validation := exchange.validation
+	actual := map[string]interface{}{
+		"status_code": validation.StatusCode,
+		"body":        exchange.body,
+	}
+	var failures []string
+
+	expectedStatuses := expect.Statuses
+	if expect.Status != 0 {
+		expectedStatuses = append(expectedStatuses, expect.Status)
+	}
+	switch {
+	case len(expectedStatuses) > 0:
+		if !containsInt(expectedStatuses, validation.StatusCode) {
+			failures = append(failures, fmt.Sprintf("expected status %v, got %d", expectedStatuses, validation.StatusCode))
+		}
+	case requireSuccess:
+		if validation.StatusCode < 200 || validation.StatusCode >= 300 {
+			failures = append(failures, fmt.Sprintf("expected a 2xx response, got %d", validation.StatusCode))
+		}
+	default:
+		if validation.Status == "warning" {
+			failures = append(failures, validation.Errors...)
+		}
+	}
+
+	if !expect.SkipSchema && len(validation.SchemaErrors) > 0 {
+		for _, mismatch := range validation.SchemaErrors {
+			failures = append(failures, fmt.Sprintf("response schema mismatch at %s", mismatch))
+		}
+	}
+
+	for _, assertion := range expect.Body {
+		value, found := evaluateJSONPath(exchange.body, assertion.Path)
+		if err := checkAssertion(assertion, value, found, vars); err != nil {
+			failures = append(failures, fmt.Sprintf("body %s: %v", assertion.Path, err))
+		}
+	}
+
+	if len(expect.Headers) > 0 {
+		headers := make(map[string]interface{}, len(expect.Headers))
+		for _, assertion := range expect.Headers {
+			values := validation.ResponseHeaders.Values(assertion.Name)
+			var value interface{}
+			if len(values) > 0 {
+				value = strings.Join(values, ", ")
+			}
+			headers[assertion.Name] = value
+			if err := checkAssertion(assertion, value, len(values) > 0, vars); err != nil {
+				failures = append(failures, fmt.Sprintf("header %s: %v", assertion.Name, err))
+			}
+		}
+		actual["headers"] = headers
+	}
+
+	tc.Actual = actual
+	if len(failures) > 0 {
+		tc.Status = TestStatusFailed
+		tc.Error = strings.Join(failures, "; ")
+	}
+	return tc
+}
+
+// checkAssertion evaluates a single assertion against a selected value
+func checkAssertion(assertion SuiteAssertion, value interface{}, found bool, vars map[string]interface{}) error {
+	if assertion.Exists != nil {
+		if *assertion.Exists && !found {
+			return fmt.Errorf("expected value to exist")
+		}
+		if !*assertion.Exists && found {
+			return fmt.Errorf("expected value to be absent, got %v", value)
+		}
+	}
+	if !found {
+		if assertion.Equals != nil || assertion.Contains != nil || assertion.Matches != "" || assertion.Type != "" {
+			return fmt.Errorf("value not found")
+		}
+		return nil
+	}
+
+	if assertion.Equals != nil {
+		expected := substituteVariables(assertion.Equals, vars)
+		if !jsonEqual(expected, value) {
+			return fmt.Errorf("expected %v, got %v", expected, value)
+		}
+	}
+	if assertion.NotEquals != nil {
+		unexpected := substituteVariables(assertion.NotEquals, vars)
+		if jsonEqual(unexpected, value) {
+			return fmt.Errorf("expected value other than %v", unexpected)
+		}
+	}
+	if assertion.Contains != nil {
+		expected := substituteVariables(assertion.Contains, vars)
+		if !containsValue(value, expected) {
+			return fmt.Errorf("expected %v to contain %v", value, expected)
+		}
+	}
+	if assertion.Matches != "" {
+		re, err := regexp.Compile(assertion.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", assertion.Matches, err)
+		}
+		if !re.MatchString(fmt.Sprintf("%v", value)) {
+			return fmt.Errorf("expected %v to match %q", value, assertion.Matches)
+		}
+	}
+	if assertion.Type != "" {
+		actualType := jsonTypeOf(value)
+		if actualType != assertion.Type && !(assertion.Type == "number" && actualType == "integer") {
+			return fmt.Errorf("expected type %s, got %s", assertion.Type, actualType)
+		}
+	}
+	return nil
+}
+
+// captureVariables stores response values selected by JSONPath or header reference in vars
+func captureVariables(capture map[string]string, exchange *suiteExchange, vars map[string]interface{}) {
+	if exchange == nil {
+		return
+	}
+	for name, expr := range capture {
+		if strings.HasPrefix(expr, "header.") {
+			if value := exchange.validation.ResponseHeaders.Get(strings.TrimPrefix(expr, "header.")); value != "" {
+				vars[name] = value
+			}
+			continue
+		}
+		if value, found := evaluateJSONPath(exchange.body, expr); found {
+			vars[name] = value
+		}
+	}
+}
+
+var variablePattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// substituteVariables replaces ${name} and ${env:NAME} references in strings, recursing into
+// maps and slices. A string consisting of a single reference keeps the variable's type.
+func substituteVariables(value interface{}, vars map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if m := variablePattern.FindStringSubmatch(v); m != nil && m[0] == v {
+			if resolved, ok := lookupVariable(m[1], vars); ok {
+				return resolved
+			}
+			return v
+		}
+		return variablePattern.ReplaceAllStringFunc(v, func(ref string) string {
+			if resolved, ok := lookupVariable(ref[2:len(ref)-1], vars); ok {
+				return fmt.Sprintf("%v", resolved)
+			}
+			return ref
+		})
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = substituteVariables(item, vars)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = substituteVariables(item, vars)
+		}
+		return out
+	}
+	return value
+}
+
+func lookupVariable(name string, vars map[string]interface{}) (interface{}, bool) {
+	if strings.HasPrefix(name, "env:") {
+		return os.LookupEnv(strings.TrimPrefix(name, "env:"))
+	}
+	value, ok := vars[name]
+	return value, ok
+}
+
+// evaluateJSONPath selects a value using a JSONPath subset: $, .name, ['name'], [index] and
+// [*]. Wildcards return the list of matched values.
+func evaluateJSONPath(root interface{}, path string) (interface{}, bool) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, false
+	}
+	segments, err := parseJSONPath(path[1:])
+	if err != nil {
+		return nil, false
+	}
+
+	current := []interface{}{root}
+	wildcard := false
+	for _, segment := range segments {
+		var next []interface{}
+		for _, node := range current {
+			switch {
+			case segment == "*":
+				wildcard = true
+				switch n := node.(type) {
+				case []interface{}:
+					next = append(next, n...)
+				case map[string]interface{}:
+					keys := make([]string, 0, len(n))
+					for k := range n {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, n[k])
+					}
+				}
+			default:
+				switch n := node.(type) {
+				case map[string]interface{}:
+					if child, ok := n[segment]; ok {
+						next = append(next, child)
+					}
+				case []interface{}:
+					idx, err := strconv.Atoi(segment)
+					if err == nil && idx < 0 {
+						idx += len(n)
+					}
+					if err == nil && idx >= 0 && idx < len(n) {
+						next = append(next, n[idx])
+					}
+				}
+			}
+		}
+		current = next
+	}
+
+	if wildcard {
+		return current, true
+	}
+	if len(current) == 0 {
+		return nil, false
+	}
+	return current[0], true
+}
+
+// parseJSONPath splits a JSONPath expression (without the leading $) into segments
+func parseJSONPath(path string) ([]string, error) {
+	var segments []string
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			j := i + 1
+			for j < len(path) && path[j] != '.' && path[j] != '[' {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("empty segment at %d", i)
+			}
+			segments = append(segments, path[i+1:j])
+			i = j
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket at %d", i)
+			}
+			segment := strings.Trim(path[i+1:i+end], `'"`)
+			segments = append(segments, segment)
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", path[i], i)
+		}
+	}
+	return segments, nil
+}
+
+// jsonEqual compares two values after normalising them through JSON
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeJSON(a), normalizeJSON(b))
+}
+
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return value
+	}
+	return out
+}
+
+// containsValue reports whether a string contains a substring, an array contains an
+// element, or an object contains a key
+func containsValue(container, expected interface{}) bool {
+	switch c := normalizeJSON(container).(type) {
+	case string:
+		return strings.Contains(c, fmt.Sprintf("%v", expected))
+	case []interface{}:
+		for _, item := range c {
+			if jsonEqual(item, expected) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		_, ok := c[fmt.Sprintf("%v", expected)]
+		return ok
+	}
+	return false
+}
+
+// jsonTypeOf returns the JSON type name of a decoded value
+func jsonTypeOf(value interface{}) string {
+	switch v := normalizeJSON(value).(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+func containsInt(values []int, target int) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEvaluateJSONPath(t *testing.T) {
+	var body interface{}
+	if err := json.Unmarshal([]byte(`{
+		"id": "t1",
+		"owner": {"name": "ada", "team.name": "core"},
+		"tags": ["urgent", "home"],
+		"items": [{"id": 1}, {"id": 2}],
+		"done": [],
+		"total": 0,
+		"next": null
+	}`), &body); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	tests := []struct {
+		path  string
+		want  string // JSON encoding of the selected value
+		found bool
+	}{
+		{path: "$", want: `{"done":[],"id":"t1","items":[{"id":1},{"id":2}],"next":null,"owner":{"name":"ada","team.name":"core"},"tags":["urgent","home"],"total":0}`, found: true},
+		{path: "$.id", want: `"t1"`, found: true},
+		{path: " $.owner.name ", want: `"ada"`, found: true},
+		{path: "$['owner']['team.name']", want: `"core"`, found: true},
+		{path: `$.owner["name"]`, want: `"ada"`, found: true},
+		{path: "$.tags[1]", want: `"home"`, found: true},
+		{path: "$.tags[-1]", want: `"home"`, found: true},
+		{path: "$.items[0].id", want: `1`, found: true},
+		{path: "$.items[*].id", want: `[1,2]`, found: true},
+		{path: "$.owner.*", want: `["ada","core"]`, found: true},
+		{path: "$.total", want: `0`, found: true},
+		{path: "$.next", want: `null`, found: true},
+		{path: "$.done[*]", want: `null`, found: true},
+		{path: "$.missing", want: `null`},
+		{path: "$.missing[*]", want: `null`},
+		{path: "$.tags[2]", want: `null`},
+		{path: "$.tags.name", want: `null`},
+		{path: "id", want: `null`},
+		{path: "$..id", want: `null`},
+		{path: "$.tags[0", want: `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, found := evaluateJSONPath(body, tt.path)
+			got, err := json.Marshal(value)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if found != tt.found || string(got) != tt.want {
+				t.Errorf("evaluateJSONPath(%q) = %s, %v, want %s, %v", tt.path, got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestCheckAssertion(t *testing.T) {
+	yes, no := true, false
+	vars := map[string]interface{}{"id": "t1", "count": float64(2)}
+	tests := []struct {
+		name      string
+		assertion SuiteAssertion
+		value     interface{}
+		found     bool
+		fails     bool
+	}{
+		{name: "equals variable", assertion: SuiteAssertion{Equals: "${id}"}, value: "t1", found: true},
+		{name: "equals keeps the variable's type", assertion: SuiteAssertion{Equals: "${count}"}, value: float64(2), found: true},
+		{name: "equals integer and float", assertion: SuiteAssertion{Equals: 2}, value: float64(2), found: true},
+		{name: "not equal", assertion: SuiteAssertion{Equals: "t2"}, value: "t1", found: true, fails: true},
+		{name: "notEquals", assertion: SuiteAssertion{NotEquals: "${id}"}, value: "t1", found: true, fails: true},
+		{name: "contains element", assertion: SuiteAssertion{Contains: "home"}, value: []interface{}{"urgent", "home"}, found: true},
+		{name: "contains key", assertion: SuiteAssertion{Contains: "id"}, value: map[string]interface{}{"id": "t1"}, found: true},
+		{name: "contains substring", assertion: SuiteAssertion{Contains: "gen"}, value: "urgent", found: true},
+		{name: "does not contain", assertion: SuiteAssertion{Contains: "work"}, value: []interface{}{"home"}, found: true, fails: true},
+		{name: "matches", assertion: SuiteAssertion{Matches: `^t\d+$`}, value: "t1", found: true},
+		{name: "invalid pattern", assertion: SuiteAssertion{Matches: "("}, value: "t1", found: true, fails: true},
+		{name: "integer is a number", assertion: SuiteAssertion{Type: "number"}, value: float64(2), found: true},
+		{name: "number is not an integer", assertion: SuiteAssertion{Type: "integer"}, value: 2.5, found: true, fails: true},
+		{name: "null type", assertion: SuiteAssertion{Type: "null"}, value: nil, found: true},
+		{name: "exists", assertion: SuiteAssertion{Exists: &yes}, found: false, fails: true},
+		{name: "absent", assertion: SuiteAssertion{Exists: &no}, found: false},
+		{name: "present but expected absent", assertion: SuiteAssertion{Exists: &no}, value: "t1", found: true, fails: true},
+		{name: "missing value with a comparison", assertion: SuiteAssertion{Equals: "t1"}, found: false, fails: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAssertion(tt.assertion, tt.value, tt.found, vars)
+			if (err != nil) != tt.fails {
+				t.Errorf("checkAssertion() error = %v, want failure %v", err, tt.fails)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ type ValidationReport struct {
 type TestResults struct {
 	Functional  *FunctionalTestResults
 	Performance *PerformanceTestResults
+	Fuzz        *FuzzTestResults
 	StartTime   time.Time
 	EndTime     time.Time
 	Status      TestStatus
@@ -60,6 +61,7 @@ type EndpointTestResult struct {
 	ResponseTime time.Duration
 	Errors       []string
 	Warnings     []string
+	Notes        []string // How spec validation findings changed this endpoint's tests
 	TestCases    []TestCaseResult
 }
 
@@ -86,7 +88,13 @@ type PerformanceTestResults struct {
 	RequestsPerSecond float64
 	Duration          time.Duration
 	Status            TestStatus
-	FailedRequests    []FailedRequest
+	FailedRequests    []FailedRequest     // Sampled failures, capped per operation
+	SkippedTargets    []string            // Operations left out of the load test and why
+	Endpoints         []EndpointMetrics   // Per-operation breakdown
+	Stages            []StageMetrics      // Per-stage breakdown of non-constant load profiles
+	Journeys          []JourneyMetrics    // Closed model journey results
+	Capacity          *CapacityResult     // Outcome of the capacity profile's search
+	Baseline          *BaselineComparison // Comparison with the recorded baseline
 }
 
 // FailedRequest represents a failed request during performance testing
@@ -118,6 +126,8 @@ type TestSummary struct {
 	FailedTests       int
 	Warnings          int
 	SkippedTests      int
+	SkipReasons       map[ValidationType]string // Why a phase did not run
+
 }
 
 // PrincipleResult represents the result of validating a single principle
@@ -129,14 +139,16 @@ type PrincipleResult struct {
 	Explanation  string
 	SuggestedFix string
 	TestImpact   *TestImpact // Added to show impact on testing
+	Findings     []Finding   // Problems of a failed spec principle, located in the spec file
 }
 
 // TestImpact represents how a validation result impacts testing
 type TestImpact struct {
-	CanRunTests     bool
+	CanRunTests     bool // False when no operation has a test left to run
 	AffectedTests   []string
 	ImpactLevel     ImpactLevel
 	Recommendations []string
+	Operations      []OperationImpact // Affected tests per operation
 }
 
 // ImpactLevel represents the severity of test impact
@@ -159,7 +171,8 @@ type AutoFixResult struct {
 	Message     string
 	Error       string
 	Original    PrincipleResult
-	Fixed       string
+	Before      string      // JSON at Location before the fix; empty if it did not exist
+	Fixed       string      // JSON at Location after the fix
 	TestImpact  *TestImpact // Added to show impact on testing
 }
 
@@ -193,6 +206,12 @@ type ValidatorConfig struct {
 	ValidationMode    ValidationMode
 	Auth              *AuthConfig // Add back Auth field for token support
 	PerformanceTarget *PerformanceTargetConfig
+	SuitePaths        []string // User-authored functional test suites
+	BaselineSpecPath  string   // Spec that SpecPath is compared against by the diff command
+	Fuzz              *FuzzConfig
+	FixOutputPath     string             // Where the fix command writes the repaired spec; defaults to SpecPath
+	Principles        []string           // Spec principles to run instead of those of the validation mode
+	Registry          *PrincipleRegistry // Spec principles to validate with; defaults to DefaultRegistry
 }
 
 // PerformanceTargetConfig holds configuration for performance test targets
@@ -201,6 +220,32 @@ type PerformanceTargetConfig struct {
 	MinSuccessRate  float64
 	ConcurrentUsers int
 	Duration        time.Duration
+	Endpoints       map[string]EndpointTarget // Per-operation SLOs keyed by "METHOD path" or operationId
+	Rate            int                       // Peak requests per second of the open model; defaults to ConcurrentUsers
+	Profile         LoadProfile               // Rate over time of the open model
+	Model           LoadModel                 // Defaults to the open model
+	ThinkTime       time.Duration             // Closed model: pause between the steps of a journey
+	Pacing          time.Duration             // Closed model: minimum time between the starts of a user's journeys
+	Journeys        []Journey                 // Closed model: defaults to one journey through every load target
+	Weights         map[string]float64        // Traffic weights keyed by "METHOD path", operationId, "tag:<name>", "reads" or "writes"
+	Include         []string                  // Only load test operations matching "METHOD path", operationId or "tag:<name>"
+	Exclude         []string                  // Never load test operations matching "METHOD path", operationId or "tag:<name>"
+	Baseline        *BaselineConfig           // Compare with earlier runs; off when nil
+}
+
+// EndpointTarget holds the SLO for a single operation. Zero fields fall back to the
+// global performance targets.
+type EndpointTarget struct {
+	MaxLatencyP95  time.Duration
+	MaxLatencyP99  time.Duration
+	MinSuccessRate float64
+}
+
+// FuzzConfig holds configuration for property-based fuzzing
+type FuzzConfig struct {
+	Seed       int64         // Zero picks a random seed, which is recorded in the results
+	Iterations int           // Maximum number of requests; zero means no limit when Duration is set, and 1000 otherwise
+	Duration   time.Duration // Maximum run time; zero means no limit
 }
 
 // AuthConfig holds authentication configuration
@@ -212,4 +257,9 @@ type AuthConfig struct {
 	Password     string
 	APIKey       string
 	APIKeyHeader string
+	Schemes      map[string]string // Credentials keyed by security scheme name, overriding those matched by type
+	OAuth2       *OAuth2Provider   // Fetches the token from a token endpoint instead of Token
+
+	// Tokens granted known scopes, which the functional tests use to check scope enforcement
+	ScopedCredentials []ScopedCredential
 }
@@ -31,20 +31,12 @@ func validateConfig(config ValidatorConfig) error {
 	if config.Timeout <= 0 {
 		return fmt.Errorf("timeout must be greater than 0")
 	}
-	if config.Auth != nil {
-		authMethods := 0
-		if config.Auth.Token != "" {
-			authMethods++
-		}
-		if config.Auth.APIKey != "" {
-			authMethods++
-		}
-		if config.Auth.Username != "" {
-			authMethods++
-		}
-		if authMethods > 1 {
-			return fmt.Errorf("only one authentication method can be specified")
-		}
+	registry := config.Registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	if err := registry.CheckIDs(config.Principles); err != nil {
+		return fmt.Errorf("invalid principles: %w", err)
 	}
 	if config.PerformanceTarget != nil {
 		if config.PerformanceTarget.Duration <= 0 {
@@ -56,6 +48,26 @@ func validateConfig(config ValidatorConfig) error {
 		if config.PerformanceTarget.MinSuccessRate < 0 || config.PerformanceTarget.MinSuccessRate > 1 {
 			return fmt.Errorf("minimum success rate must be between 0 and 1")
 		}
+		if _, err := config.PerformanceTarget.loadStages(); err != nil {
+			return fmt.Errorf("invalid load profile: %w", err)
+		}
+		switch config.PerformanceTarget.Model {
+		case "", LoadModelOpen:
+		case LoadModelClosed:
+			if profile := config.PerformanceTarget.Profile.Type; profile != "" && profile != LoadProfileConstant {
+				return fmt.Errorf("load profile %s applies to the open load model only", profile)
+			}
+		default:
+			return fmt.Errorf("unknown load model %q (expected open or closed)", config.PerformanceTarget.Model)
+		}
+		if baseline := config.PerformanceTarget.Baseline; baseline != nil {
+			if baseline.Path == "" {
+				return fmt.Errorf("performance baseline path is required")
+			}
+			if baseline.P95Tolerance < 0 || baseline.ThroughputTolerance < 0 || baseline.MinP95Increase < 0 {
+				return fmt.Errorf("performance baseline tolerances must not be negative")
+			}
+		}
 	}
 	return nil
 }
@@ -96,29 +108,28 @@ func (v *OpenAPIValidator) ValidateSpec(ctx context.Context) (*ValidationReport,
 		Timestamp:   time.Now(),
 	}
 
-	// In minimal mode, only run basic validation principles (P001, P004)
-	// Skip functional and performance testing
-	var validationPrinciples []Principle
+	// Minimal mode runs the principles marked for it, such as P001 and P004, and every
+	// other mode runs all registered principles
+	registry := v.registry()
+	validationPrinciples := registry.forMode(v.config.ValidationMode)
 	if v.config.ValidationMode == ValidationModeMinimal {
-		validationPrinciples = []Principle{
-			CorePrinciples[0], // P001: OpenAPI Specification Compliance
-			CorePrinciples[3], // P004: Request Validation (basic schema checks only)
-		}
 		log.Debug("Running in minimal mode - skipping functional and performance testing")
-	} else {
-		// Strict mode - run all validation principles
-		validationPrinciples = []Principle{
-			CorePrinciples[0], // P001: OpenAPI Specification Compliance
-			CorePrinciples[1], // P002: API Documentation Completeness
-			CorePrinciples[2], // P003: Error Response Documentation
-			CorePrinciples[3], // P004: Request Validation
-			CorePrinciples[4], // P005: Authentication Requirements
-			CorePrinciples[7], // P008: API Versioning
-		}
+	}
+	if len(v.config.Principles) > 0 {
+		validationPrinciples = registry.selectPrinciples(v.config.Principles)
+		log.Debugf("Running selected principles: %s", strings.Join(v.config.Principles, ", "))
 	}
 
-	for _, principle := range validationPrinciples {
-		result := v.validatePrinciple(ctx, principle, doc)
+	ctx = withValidationMode(ctx, v.config.ValidationMode)
+	for _, definition := range validationPrinciples {
+		result := v.validatePrinciple(ctx, definition, doc)
+		result.TestImpact = assessTestImpact(definition.Principle.ID, doc)
+		if !result.Passed {
+			if len(result.Findings) == 0 {
+				result.Findings = specFindings(result)
+			}
+			result.Findings = locateFindings(v.loader, result.Findings)
+		}
 		report.Principles = append(report.Principles, result)
 
 		if result.Passed {
@@ -129,41 +140,58 @@ func (v *OpenAPIValidator) ValidateSpec(ctx context.Context) (*ValidationReport,
 	}
 
 	report.TotalChecks = len(validationPrinciples)
-	v.updateSummary(report)
+	updateSummary(report)
 
 	return report, nil
 }
 
-// validatePrinciple checks a single validation principle
-func (v *OpenAPIValidator) validatePrinciple(ctx context.Context, principle Principle, doc *openapi3.T) PrincipleResult {
-	result := PrincipleResult{
-		Principle: principle,
-		Passed:    true,
+// registry returns the registry of the principles the validator runs
+func (v *OpenAPIValidator) registry() *PrincipleRegistry {
+	if v.config.Registry != nil {
+		return v.config.Registry
 	}
+	return DefaultRegistry
+}
 
-	switch principle.ID {
-	case "P001": // OpenAPI Specification Compliance
-		result = v.validateOpenAPICompliance(doc)
-	case "P002": // API Documentation Completeness
-		result = v.validateDocumentationQuality(doc)
-	case "P003": // Error Response Documentation
-		result = v.validateErrorHandling(doc)
-	case "P004": // Request Validation
-		result = v.validateRequestSchema(doc)
-	case "P005": // Authentication Requirements
-		result = v.validateAuthentication(doc)
-	case "P008": // API Versioning
-		result = v.validateVersioning(doc)
-	default:
-		result.Passed = false
-		result.Message = fmt.Sprintf("Unknown principle ID: %s", principle.ID)
+// locateFindings adds the position of each finding's pointer in the loaded spec file
+func locateFindings(loader *openapi.Loader, findings []Finding) []Finding {
+	for i, finding := range findings {
+		if position, ok := loader.Locate(finding.Pointer); ok {
+			findings[i].Line = position.Line
+			findings[i].Column = position.Column
+		}
 	}
+	return findings
+}
 
+// validatePrinciple checks a single validation principle. Results are reported under
+// the registered principle, and a principle that panics fails instead of the run.
+func (v *OpenAPIValidator) validatePrinciple(ctx context.Context, definition PrincipleDefinition, doc *openapi3.T) (result PrincipleResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = PrincipleResult{
+				Principle: definition.Principle,
+				Passed:    false,
+				Message:   fmt.Sprintf("Principle %s failed to run: %v", definition.Principle.ID, r),
+			}
+		}
+	}()
+
+	result = definition.Validate(ctx, doc)
+	result.Principle = definition.Principle
+	for i := range result.Findings {
+		if result.Findings[i].PrincipleID == "" {
+			result.Findings[i].PrincipleID = definition.Principle.ID
+		}
+		if result.Findings[i].Severity == "" {
+			result.Findings[i].Severity = definition.Principle.Severity
+		}
+	}
 	return result
 }
 
 // validateOpenAPICompliance validates that the OpenAPI spec is compliant with the OpenAPI 3.0/3.1 schema
-func (v *OpenAPIValidator) validateOpenAPICompliance(doc *openapi3.T) PrincipleResult {
+func validateOpenAPICompliance(ctx context.Context, doc *openapi3.T) PrincipleResult {
 	result := PrincipleResult{
 		Principle: CorePrinciples[0], // P001
 		Passed:    true,
@@ -330,7 +358,7 @@ func (v *OpenAPIValidator) validateOpenAPICompliance(doc *openapi3.T) PrincipleR
 }
 
 // validateDocumentationQuality validates the quality and completeness of API documentation
-func (v *OpenAPIValidator) validateDocumentationQuality(doc *openapi3.T) PrincipleResult {
+func validateDocumentationQuality(ctx context.Context, doc *openapi3.T) PrincipleResult {
 	result := PrincipleResult{
 		Principle: CorePrinciples[1], // P002
 		Passed:    true,
@@ -506,7 +534,7 @@ func (v *OpenAPIValidator) validateDocumentationQuality(doc *openapi3.T) Princip
 }
 
 // validateErrorHandling validates error response documentation and patterns
-func (v *OpenAPIValidator) validateErrorHandling(doc *openapi3.T) PrincipleResult {
+func validateErrorHandling(ctx context.Context, doc *openapi3.T) PrincipleResult {
 	result := PrincipleResult{
 		Principle: CorePrinciples[2], // P003
 		Passed:    true,
@@ -514,7 +542,7 @@ func (v *OpenAPIValidator) validateErrorHandling(doc *openapi3.T) PrincipleResul
 	}
 
 	// In minimal mode, only check documentation for present error codes
-	if v.config.ValidationMode == ValidationModeMinimal {
+	if ValidationModeFrom(ctx) == ValidationModeMinimal {
 		for path, pathItem := range doc.Paths.Map() {
 			for method, operation := range pathItem.Operations() {
 				opKey := fmt.Sprintf("%s %s", method, path)
@@ -705,7 +733,7 @@ func (v *OpenAPIValidator) validateErrorHandling(doc *openapi3.T) PrincipleResul
 }
 
 // validateRequestSchema validates request parameter and body schemas
-func (v *OpenAPIValidator) validateRequestSchema(doc *openapi3.T) PrincipleResult {
+func validateRequestSchema(ctx context.Context, doc *openapi3.T) PrincipleResult {
 	result := PrincipleResult{
 		Principle: CorePrinciples[3], // P004
 		Passed:    true,
@@ -713,7 +741,7 @@ func (v *OpenAPIValidator) validateRequestSchema(doc *openapi3.T) PrincipleResul
 	}
 
 	// In minimal mode, only check for basic schema existence
-	if v.config.ValidationMode == ValidationModeMinimal {
+	if ValidationModeFrom(ctx) == ValidationModeMinimal {
 		for path, pathItem := range doc.Paths.Map() {
 			for method, operation := range pathItem.Operations() {
 				opKey := fmt.Sprintf("%s %s", method, path)
@@ -926,7 +954,7 @@ func (v *OpenAPIValidator) validateRequestSchema(doc *openapi3.T) PrincipleResul
 						}
 
 						// Validate schema recursively
-						v.validateSchemaConstraints(content.Schema.Value, opKey, contentType, checks, missingValidation)
+						validateSchemaConstraints(content.Schema.Value, opKey, contentType, checks, missingValidation)
 					}
 				}
 			}
@@ -965,7 +993,7 @@ func (v *OpenAPIValidator) validateRequestSchema(doc *openapi3.T) PrincipleResul
 }
 
 // validateSchemaConstraints recursively validates schema constraints
-func (v *OpenAPIValidator) validateSchemaConstraints(schema *openapi3.Schema, context, contentType string, checks map[string]bool, missingValidation map[string][]string) {
+func validateSchemaConstraints(schema *openapi3.Schema, context, contentType string, checks map[string]bool, missingValidation map[string][]string) {
 	if schema == nil {
 		return
 	}
@@ -1046,19 +1074,19 @@ func (v *OpenAPIValidator) validateSchemaConstraints(schema *openapi3.Schema, co
 	if schema.Properties != nil {
 		for name, prop := range schema.Properties {
 			if prop.Value != nil {
-				v.validateSchemaConstraints(prop.Value, fmt.Sprintf("%s.%s", context, name), contentType, checks, missingValidation)
+				validateSchemaConstraints(prop.Value, fmt.Sprintf("%s.%s", context, name), contentType, checks, missingValidation)
 			}
 		}
 	}
 
 	// Check array items
 	if schema.Type == "array" && schema.Items != nil && schema.Items.Value != nil {
-		v.validateSchemaConstraints(schema.Items.Value, fmt.Sprintf("%s[]", context), contentType, checks, missingValidation)
+		validateSchemaConstraints(schema.Items.Value, fmt.Sprintf("%s[]", context), contentType, checks, missingValidation)
 	}
 }
 
 // validateAuthentication validates that all operations have proper authentication requirements
-func (v *OpenAPIValidator) validateAuthentication(doc *openapi3.T) PrincipleResult {
+func validateAuthentication(ctx context.Context, doc *openapi3.T) PrincipleResult {
 	result := PrincipleResult{
 		Principle: CorePrinciples[4], // P005
 		Passed:    true,
@@ -1091,7 +1119,7 @@ func (v *OpenAPIValidator) validateAuthentication(doc *openapi3.T) PrincipleResu
 }
 
 // validateVersioning validates that the API has proper versioning
-func (v *OpenAPIValidator) validateVersioning(doc *openapi3.T) PrincipleResult {
+func validateVersioning(ctx context.Context, doc *openapi3.T) PrincipleResult {
 	result := PrincipleResult{
 		Principle: CorePrinciples[7], // P008
 		Passed:    true,
@@ -1118,7 +1146,7 @@ func (v *OpenAPIValidator) validateVersioning(doc *openapi3.T) PrincipleResult {
 }
 
 // updateSummary updates the validation summary
-func (v *OpenAPIValidator) updateSummary(report *ValidationReport) {
+func updateSummary(report *ValidationReport) {
 	summary := ValidationSummary{}
 	categories := make(map[string]bool)
 	failedTags := make(map[string]bool)
@@ -1148,6 +1176,7 @@ func (v *OpenAPIValidator) updateSummary(report *ValidationReport) {
 		summary.FailedTags = append(summary.FailedTags, tag)
 	}
 
+	summary.TestSummary = report.Summary.TestSummary
 	report.Summary = summary
 }
 
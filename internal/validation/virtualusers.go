@@ -0,0 +1,291 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	vegeta "github.com/tsenart/vegeta/v12/lib"
+)
+
+// LoadModel selects how load is generated
+type LoadModel string
+
+const (
+	LoadModelOpen   LoadModel = "open"   // Requests are sent at a planned rate, regardless of response times
+	LoadModelClosed LoadModel = "closed" // A fixed number of virtual users each wait for a response before continuing
+)
+
+// Journey is a sequence of operations that a virtual user performs in order
+type Journey struct {
+	Name  string
+	Steps []string // Operations as "METHOD path" or operationId
+}
+
+// ParseJourney parses a journey of the form "<name>=<operation>><operation>...", where each
+// operation is "METHOD path" or an operationId
+func ParseJourney(value string) (Journey, error) {
+	name, rawSteps, ok := strings.Cut(value, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return Journey{}, fmt.Errorf("invalid journey %q: expected <name>=<operation>><operation>...", value)
+	}
+	journey := Journey{Name: name}
+	for _, step := range strings.Split(rawSteps, ">") {
+		step = normalizeOperationKey(step)
+		if step == "" {
+			continue
+		}
+		journey.Steps = append(journey.Steps, step)
+	}
+	if len(journey.Steps) == 0 {
+		return Journey{}, fmt.Errorf("journey %s has no steps", name)
+	}
+	return journey, nil
+}
+
+// journeyStep is a resolved journey step
+type journeyStep struct {
+	operation string // "METHOD path"
+	target    *loadTarget
+}
+
+// resolvedJourney is a journey whose steps have been matched to the spec's operations
+type resolvedJourney struct {
+	name  string
+	steps []journeyStep
+}
+
+// resolveJourneys matches the configured journeys' steps to load targets. Without
+// configured journeys, a single journey visits every weighted target in order. Journeys
+// that use an operation left out of the load test are skipped and reported.
+func resolveJourneys(journeys []Journey, targets *loadTargetSet) ([]resolvedJourney, []string, error) {
+	if len(journeys) == 0 {
+		journey := resolvedJourney{name: "all operations"}
+		for _, target := range targets.weighted {
+			journey.steps = append(journey.steps, journeyStep{operation: target.operation, target: target})
+		}
+		return []resolvedJourney{journey}, nil, nil
+	}
+
+	var resolved []resolvedJourney
+	var skippedJourneys []string
+	for _, journey := range journeys {
+		current := resolvedJourney{name: journey.Name}
+		var skipReason string
+		for _, step := range journey.Steps {
+			operation := step
+			if key, ok := targets.operationIDs[step]; ok {
+				operation = key
+			}
+			if reason, ok := targets.excluded[operation]; ok {
+				skipReason = fmt.Sprintf("%s (%s)", operation, reason)
+				break
+			}
+			target, ok := targets.operations[operation]
+			if !ok {
+				return nil, nil, fmt.Errorf("journey %s: unknown operation %q", journey.Name, step)
+			}
+			current.steps = append(current.steps, journeyStep{operation: operation, target: target})
+		}
+		if skipReason != "" {
+			skippedJourneys = append(skippedJourneys, fmt.Sprintf("journey %s: %s", journey.Name, skipReason))
+			continue
+		}
+		resolved = append(resolved, current)
+	}
+	return resolved, skippedJourneys, nil
+}
+
+// journeyCollector keeps per-journey and per-step metrics of a closed-model run
+type journeyCollector struct {
+	mu        sync.Mutex
+	journeys  []resolvedJourney
+	started   []uint64
+	completed []uint64
+	failed    []uint64
+	durations []*vegeta.LatencyMetrics // Completed journey durations, including think time
+	steps     [][]*vegeta.Metrics
+}
+
+func newJourneyCollector(journeys []resolvedJourney) *journeyCollector {
+	c := &journeyCollector{
+		journeys:  journeys,
+		started:   make([]uint64, len(journeys)),
+		completed: make([]uint64, len(journeys)),
+		failed:    make([]uint64, len(journeys)),
+		durations: make([]*vegeta.LatencyMetrics, len(journeys)),
+		steps:     make([][]*vegeta.Metrics, len(journeys)),
+	}
+	for i, journey := range journeys {
+		c.durations[i] = &vegeta.LatencyMetrics{}
+		for range journey.steps {
+			c.steps[i] = append(c.steps[i], &vegeta.Metrics{})
+		}
+	}
+	return c
+}
+
+// results closes the per-step metrics and summarizes each journey
+func (c *journeyCollector) results(elapsed time.Duration) []JourneyMetrics {
+	var results []JourneyMetrics
+	for i, journey := range c.journeys {
+		result := JourneyMetrics{
+			Name:           journey.name,
+			Started:        c.started[i],
+			Completed:      c.completed[i],
+			Failed:         c.failed[i],
+			JourneysPerSec: float64(c.completed[i]) / elapsed.Seconds(),
+		}
+		if c.completed[i] > 0 {
+			result.DurationP50 = c.durations[i].Quantile(0.50)
+			result.DurationP95 = c.durations[i].Quantile(0.95)
+		}
+		for j, step := range journey.steps {
+			metrics := c.steps[i][j]
+			metrics.Close()
+			_, _, errorRate := requestOutcomes(metrics)
+			result.Steps = append(result.Steps, JourneyStepMetrics{
+				Operation:     step.operation,
+				TotalRequests: metrics.Requests,
+				ErrorRate:     errorRate,
+				LatencyP50:    metrics.Latencies.P50,
+				LatencyP95:    metrics.Latencies.P95,
+				LatencyP99:    metrics.Latencies.P99,
+			})
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// runVirtualUsers runs the closed load model: each of the configured number of virtual
+// users repeats a journey, waiting for every response and the think time before its next
+// step, until the duration ends. Every request is passed to record.
+func (t *PerformanceTester) runVirtualUsers(ctx context.Context, journeys []resolvedJourney, duration time.Duration, record func(*vegeta.Result)) []JourneyMetrics {
+	targets := t.config.PerformanceTarget
+	client := &http.Client{Timeout: t.config.Timeout}
+	collector := newJourneyCollector(journeys)
+
+	runCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for user := 0; user < targets.ConcurrentUsers; user++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			for runCtx.Err() == nil {
+				iterationStart := time.Now()
+				t.runJourney(runCtx, client, collector, index, record)
+				if wait := targets.Pacing - time.Since(iterationStart); wait > 0 {
+					sleepContext(runCtx, wait)
+				}
+			}
+		}(user % len(journeys))
+	}
+	wg.Wait()
+
+	return collector.results(time.Since(start))
+}
+
+// runJourney performs one iteration of a journey. A journey fails at its first
+// unsuccessful step; one cut short by the end of the run is not counted.
+func (t *PerformanceTester) runJourney(ctx context.Context, client *http.Client, collector *journeyCollector, index int, record func(*vegeta.Result)) {
+	journey := collector.journeys[index]
+	collector.mu.Lock()
+	collector.started[index]++
+	collector.mu.Unlock()
+
+	journeyStart := time.Now()
+	for i, step := range journey.steps {
+		if i > 0 && t.config.PerformanceTarget.ThinkTime > 0 {
+			sleepContext(ctx, t.config.PerformanceTarget.ThinkTime)
+		}
+		if ctx.Err() != nil {
+			collector.mu.Lock()
+			collector.started[index]--
+			collector.mu.Unlock()
+			return
+		}
+
+		res := sendLoadRequest(ctx, client, step.target.current())
+		if ctx.Err() != nil {
+			// The run ended while the request was in flight
+			collector.mu.Lock()
+			collector.started[index]--
+			collector.mu.Unlock()
+			return
+		}
+		record(res)
+
+		collector.mu.Lock()
+		collector.steps[index][i].Add(res)
+		if res.Code < 200 || res.Code >= 400 {
+			collector.failed[index]++
+			collector.mu.Unlock()
+			return
+		}
+		collector.mu.Unlock()
+	}
+
+	collector.mu.Lock()
+	collector.completed[index]++
+	collector.durations[index].Add(time.Since(journeyStart))
+	collector.mu.Unlock()
+}
+
+// sendLoadRequest sends a load target and describes the outcome the way vegeta does
+func sendLoadRequest(ctx context.Context, client *http.Client, target vegeta.Target) *vegeta.Result {
+	res := &vegeta.Result{
+		Method:    target.Method,
+		URL:       target.URL,
+		Timestamp: time.Now(),
+	}
+	defer func() { res.Latency = time.Since(res.Timestamp) }()
+
+	var body io.Reader
+	if len(target.Body) > 0 {
+		body = bytes.NewReader(target.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, target.Method, target.URL, body)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	for name, values := range target.Header {
+		req.Header[name] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+
+	bytesIn, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		res.Error = err.Error()
+	}
+	res.BytesIn = uint64(bytesIn)
+	if res.Code = uint16(resp.StatusCode); res.Code < 200 || res.Code >= 400 {
+		res.Error = resp.Status
+	}
+	return res
+}
+
+// sleepContext waits for the duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}